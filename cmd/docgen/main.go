@@ -0,0 +1,89 @@
+// docgen prints the argument and attribute reference of a resource or data source as markdown,
+// using the descriptions of the provider schema.
+//
+//	go run ./cmd/docgen netapp-gcp_volume
+//	go run ./cmd/docgen -data-source netapp-gcp_volume
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp"
+)
+
+func main() {
+	dataSource := flag.Bool("data-source", false, "document a data source instead of a resource")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: docgen [-data-source] <type name>")
+		os.Exit(2)
+	}
+
+	provider := gcp.Provider().(*schema.Provider)
+	resources := provider.ResourcesMap
+	if *dataSource {
+		resources = provider.DataSourcesMap
+	}
+	resource, ok := resources[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown type %s\n", flag.Arg(0))
+		os.Exit(1)
+	}
+
+	fmt.Println("## Argument Reference")
+	fmt.Println()
+	fmt.Println("The following arguments are supported:")
+	fmt.Println()
+	printAttributes(resource.Schema, func(s *schema.Schema) bool { return s.Required || s.Optional })
+	fmt.Println()
+	fmt.Println("## Attributes Reference")
+	fmt.Println()
+	fmt.Println("The following attributes are exported in addition to the arguments listed above:")
+	fmt.Println()
+	fmt.Println("* `id` - The unique identifier of the resource.")
+	printAttributes(resource.Schema, func(s *schema.Schema) bool { return s.Computed && !s.Optional })
+	printBlocks(resource.Schema)
+}
+
+func printAttributes(attributes map[string]*schema.Schema, include func(*schema.Schema) bool) {
+	for _, key := range sortedKeys(attributes) {
+		attribute := attributes[key]
+		if !include(attribute) {
+			continue
+		}
+		qualifier := ""
+		if attribute.Required {
+			qualifier = "(Required) "
+		} else if attribute.Optional {
+			qualifier = "(Optional) "
+		}
+		fmt.Printf("* `%s` - %s%s\n", key, qualifier, attribute.Description)
+	}
+}
+
+// printBlocks documents the attributes of nested blocks, e.g. "The `snapshot_policy` block supports:"
+func printBlocks(attributes map[string]*schema.Schema) {
+	for _, key := range sortedKeys(attributes) {
+		elem, ok := attributes[key].Elem.(*schema.Resource)
+		if !ok {
+			continue
+		}
+		fmt.Println()
+		fmt.Printf("The `%s` block supports:\n", key)
+		printAttributes(elem.Schema, func(*schema.Schema) bool { return true })
+		printBlocks(elem.Schema)
+	}
+}
+
+func sortedKeys(attributes map[string]*schema.Schema) []string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+)
+
+// writeServiceAccountKey writes a service account key with a freshly generated private key. The provider
+// signs its tokens with it; the fake server doesn't verify them.
+func writeServiceAccountKey(path string) error {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+	key := map[string]string{
+		"type":           "service_account",
+		"project_id":     "fakecvs",
+		"private_key_id": "fakecvs",
+		"private_key":    string(keyPEM),
+		"client_email":   "fakecvs@fakecvs.iam.gserviceaccount.com",
+		"client_id":      "0",
+		"token_uri":      "https://oauth2.googleapis.com/token",
+	}
+	content, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0600)
+}
@@ -0,0 +1,58 @@
+// fakecvs is an in-memory stand-in for the NetApp Cloud Volumes Service API for Google Cloud.
+// It implements the endpoints used by the provider so plans and applies can be run offline,
+// with configurable latency and error injection to reproduce retry behavior.
+//
+// Point the provider at it with:
+//
+//	NETAPP_GCP_API_HOST=http://127.0.0.1:8080/v2/projects/123456789/locations/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/fakecvs"
+)
+
+type faultFlags []string
+
+func (f *faultFlags) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *faultFlags) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	var faults faultFlags
+	listen := flag.String("listen", "127.0.0.1:8080", "address to listen on")
+	latency := flag.Duration("latency", 0, "delay added to every response")
+	provisioning := flag.Duration("provisioning-time", 30*time.Second, "time a volume stays in creating state")
+	keyFile := flag.String("write-key", "", "write a throwaway service account key to this path and exit")
+	flag.Var(&faults, "fault", "inject errors as method:resource:status:count[:message], e.g. POST:Volumes:500:3 (repeatable)")
+	flag.Parse()
+
+	if *keyFile != "" {
+		if err := writeServiceAccountKey(*keyFile); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Service account key written to %s\n", *keyFile)
+		return
+	}
+
+	server := fakecvs.NewServer(*latency, *provisioning)
+	for _, spec := range faults {
+		if err := server.AddFault(spec); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	log.Printf("fake CVS API listening on http://%s/v2/projects/{project}/locations/", *listen)
+	log.Fatal(http.ListenAndServe(*listen, server))
+}
@@ -1,24 +1,21 @@
 package gcp
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
-
-	"github.com/fatih/structs"
 )
 
 // operateActiveDirectoryRequest requests the user's input for creating/updating an active directory
 type operateActiveDirectoryRequest struct {
-	Username           string `structs:"username"`
-	Password           string `structs:"password"`
-	Region             string `structs:"region"`
-	Domain             string `structs:"domain"`
-	DNS                string `structs:"DNS"`
-	NetBIOS            string `structs:"netBIOS"`
-	OrganizationalUnit string `structs:"organizationalUnit"`
-	Site               string `structs:"site"`
-	UUID               string `structs:"UUID"`
+	Username           string `json:"username"`
+	Password           string `json:"password"`
+	Region             string `json:"region"`
+	Domain             string `json:"domain"`
+	DNS                string `json:"DNS"`
+	NetBIOS            string `json:"netBIOS"`
+	OrganizationalUnit string `json:"organizationalUnit"`
+	Site               string `json:"site"`
+	UUID               string `json:"UUID"`
 }
 
 // operateActiveDirectoryResult returns the api response for creating/updating an active directory
@@ -29,8 +26,8 @@ type operateActiveDirectoryResult struct {
 
 // listActiveDirectoryRequest requests the region and uuid of the active directory being fetched
 type listActiveDirectoryRequest struct {
-	Region string `structs:"region"`
-	UUID   string `structs:"UUID"`
+	Region string `json:"region"`
+	UUID   string `json:"UUID"`
 }
 
 // listActiveDirectoryResult lists the active directory for given ID
@@ -41,8 +38,8 @@ type listActiveDirectoryResult struct {
 	Domain             string `json:"domain"`
 	DNS                string `json:"DNS"`
 	NetBIOS            string `json:"netBIOS"`
-	OrganizationalUnit string `structs:"organizationalUnit"`
-	Site               string `structs:"site"`
+	OrganizationalUnit string `json:"organizationalUnit"`
+	Site               string `json:"site"`
 	UUID               string `json:"UUID"`
 }
 
@@ -52,12 +49,12 @@ type listActiveDirectoryAPIResult struct {
 
 // deleteActiveDirectoryRequest requests the region and uuid of the active directory being deleted
 type deleteActiveDirectoryRequest struct {
-	Region string `structs:"region"`
-	UUID   string `structs:"UUID"`
+	Region string `json:"region"`
+	UUID   string `json:"UUID"`
 }
 
 func (c *Client) createActiveDirectory(request *operateActiveDirectoryRequest) (operateActiveDirectoryResult, error) {
-	params := structs.Map(request)
+	params := request
 	baseURL := fmt.Sprintf("%s/Storage/ActiveDirectory", request.Region)
 	statusCode, response, err := c.CallAPIMethod("POST", baseURL, params)
 	if err != nil {
@@ -71,8 +68,7 @@ func (c *Client) createActiveDirectory(request *operateActiveDirectoryRequest) (
 	}
 
 	var result operateActiveDirectoryResult
-	if err := json.Unmarshal(response, &result); err != nil {
-		log.Print("Failed to unmarshall response from CreateActiveDirectory")
+	if err := decodeResponse(response, &result, statusCode, baseURL, "CreateActiveDirectory"); err != nil {
 		return operateActiveDirectoryResult{}, err
 	}
 
@@ -94,8 +90,7 @@ func (c *Client) listActiveDirectoryForRegion(request listActiveDirectoryRequest
 	}
 
 	var activeDirectories []listActiveDirectoryResult
-	if err := json.Unmarshal(response, &activeDirectories); err != nil {
-		log.Print("Failed to unmarshall response from listActiveDirectoryForRegion")
+	if err := decodeResponse(response, &activeDirectories, statusCode, baseURL, "listActiveDirectoryForRegion"); err != nil {
 		return listActiveDirectoryResult{}, err
 	}
 	for _, v := range activeDirectories {
@@ -126,7 +121,7 @@ func (c *Client) deleteActiveDirectory(request deleteActiveDirectoryRequest) err
 }
 
 func (c *Client) updateActiveDirectory(request operateActiveDirectoryRequest) error {
-	params := structs.Map(request)
+	params := request
 	baseURL := fmt.Sprintf("%s/Storage/ActiveDirectory/%s", request.Region, request.UUID)
 	statusCode, response, err := c.CallAPIMethod("PUT", baseURL, params)
 	if err != nil {
@@ -140,8 +135,7 @@ func (c *Client) updateActiveDirectory(request operateActiveDirectoryRequest) er
 	}
 
 	var result listActiveDirectoryResult
-	if err := json.Unmarshal(response, &result); err != nil {
-		log.Print("Failed to unmarshall response from updateActiveDirectory")
+	if err := decodeResponse(response, &result, statusCode, baseURL, "updateActiveDirectory"); err != nil {
 		return err
 	}
 
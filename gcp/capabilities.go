@@ -0,0 +1,121 @@
+package gcp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// capabilitiesVersion is the version of the capability table. Bump it when a service level or storage class is added.
+const capabilitiesVersion = 2
+
+// defaultServiceLevel is the default service_level of a volume. It predates the capability table and is the
+// API value of premium, so it is accepted without being listed.
+const defaultServiceLevel = "medium"
+
+// serviceLevelCapability is a service level of volumes with the values used for it by the API
+type serviceLevelCapability struct {
+	// name is the service_level of the volume resource
+	name string
+	// requestValue is the value sent to the API
+	requestValue string
+	// responseValue is the value returned by the API. Due to API bugs it doesn't always match the request value.
+	responseValue string
+	// minSizeGiB and maxSizeGiB are the range of the volume size
+	minSizeGiB int
+	maxSizeGiB int
+}
+
+// serviceLevelCapabilities are the service levels known to this version of the provider. The provider
+// additional_service_levels argument adds service levels that are passed to the API as is.
+var serviceLevelCapabilities = []serviceLevelCapability{
+	{name: "standard", requestValue: "low", responseValue: "basic", minSizeGiB: 1024, maxSizeGiB: 102400},
+	{name: "premium", requestValue: "medium", responseValue: "standard", minSizeGiB: 1024, maxSizeGiB: 102400},
+	{name: "extreme", requestValue: "extreme", responseValue: "extreme", minSizeGiB: 1024, maxSizeGiB: 102400},
+	// service levels of software volumes
+	{name: "standard-sw", requestValue: "standard-sw", responseValue: "standard-sw", minSizeGiB: 1, maxSizeGiB: 102400},
+	{name: "zoneredundantstandardsw", requestValue: "zoneredundantstandardsw", responseValue: "zoneredundantstandardsw", minSizeGiB: 1, maxSizeGiB: 102400},
+}
+
+// storageClasses are the storage classes known to this version of the provider
+var storageClasses = []string{"hardware", "software"}
+
+// serviceLevelNames returns the names of the known service levels followed by the additional ones
+func serviceLevelNames(additional []string) []string {
+	names := make([]string, 0, len(serviceLevelCapabilities)+len(additional))
+	for _, capability := range serviceLevelCapabilities {
+		names = append(names, capability.name)
+	}
+	return append(names, additional...)
+}
+
+// validateServiceLevel checks that the service level is known, by its name or API values, or one of the additional
+// service levels, ignoring case
+func validateServiceLevel(level string, additional []string) error {
+	if _, ok := serviceLevelCapabilityOf(level); ok {
+		return nil
+	}
+	names := serviceLevelNames(additional)
+	for _, name := range names {
+		if strings.EqualFold(level, name) {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected service_level to be one of %v, got %s. Service levels added since capability table version %d can be allowed with the provider additional_service_levels argument", names, level, capabilitiesVersion)
+}
+
+// validateVolumeSize checks that the size in GiB is in the range of the service level. The size of volumes of
+// additional service levels isn't checked.
+func validateVolumeSize(size int, level string) error {
+	capability, ok := serviceLevelCapabilityOf(level)
+	if !ok {
+		return nil
+	}
+	if size < capability.minSizeGiB || size > capability.maxSizeGiB {
+		return fmt.Errorf("expected the size of a %s volume to be between %d and %d GiB, got %d", capability.name, capability.minSizeGiB, capability.maxSizeGiB, size)
+	}
+	return nil
+}
+
+// serviceLevelCapabilityOf returns the capability of a service level given by its name or by one of its API values,
+// ignoring case. The API names its service levels basic, standard and extreme rather than standard, premium and
+// extreme, so standard is ambiguous: names take precedence over API values and it is the standard service level.
+func serviceLevelCapabilityOf(level string) (serviceLevelCapability, bool) {
+	for _, capability := range serviceLevelCapabilities {
+		if strings.EqualFold(level, capability.name) {
+			return capability, true
+		}
+	}
+	for _, capability := range serviceLevelCapabilities {
+		if strings.EqualFold(level, capability.requestValue) || strings.EqualFold(level, capability.responseValue) {
+			return capability, true
+		}
+	}
+	return serviceLevelCapability{}, false
+}
+
+// canonicalServiceLevel returns the name of a service level given by its name or API values. Unknown service levels
+// are returned as is.
+func canonicalServiceLevel(level string) string {
+	if capability, ok := serviceLevelCapabilityOf(level); ok {
+		return capability.name
+	}
+	return level
+}
+
+// serviceLevelToAPI returns the API value of the service level. Unknown service levels are passed as is.
+func serviceLevelToAPI(level string) string {
+	if capability, ok := serviceLevelCapabilityOf(level); ok {
+		return capability.requestValue
+	}
+	return level
+}
+
+// serviceLevelFromAPI returns the service level of a value returned by the API. Unknown values are returned as is.
+func serviceLevelFromAPI(value string) string {
+	for _, capability := range serviceLevelCapabilities {
+		if value == capability.responseValue {
+			return capability.name
+		}
+	}
+	return value
+}
@@ -0,0 +1,99 @@
+package gcp
+
+import "testing"
+
+func TestServiceLevelTranslation(t *testing.T) {
+	for _, capability := range serviceLevelCapabilities {
+		if value := serviceLevelToAPI(capability.name); value != capability.requestValue {
+			t.Errorf("serviceLevelToAPI(%s) = %s, expected %s", capability.name, value, capability.requestValue)
+		}
+		if name := serviceLevelFromAPI(capability.responseValue); name != capability.name {
+			t.Errorf("serviceLevelFromAPI(%s) = %s, expected %s", capability.responseValue, name, capability.name)
+		}
+	}
+	// service levels added with additional_service_levels are passed as is
+	if value := serviceLevelToAPI("flex"); value != "flex" {
+		t.Errorf("expected flex to be passed as is, got %s", value)
+	}
+	if name := serviceLevelFromAPI("flex"); name != "flex" {
+		t.Errorf("expected flex to be read as is, got %s", name)
+	}
+}
+
+func TestCanonicalServiceLevel(t *testing.T) {
+	cases := map[string]string{
+		"standard": "standard",
+		"Premium":  "premium",
+		"basic":    "standard",
+		"low":      "standard",
+		"medium":   "premium",
+		"EXTREME":  "extreme",
+		"flex":     "flex",
+	}
+	for level, expected := range cases {
+		if name := canonicalServiceLevel(level); name != expected {
+			t.Errorf("canonicalServiceLevel(%s) = %s, expected %s", level, name, expected)
+		}
+	}
+	if value := serviceLevelToAPI("basic"); value != "low" {
+		t.Errorf("expected basic to be sent as low, got %s", value)
+	}
+	if !suppressServiceLevelDiff("service_level", "premium", defaultServiceLevel, nil) {
+		t.Error("expected no diff between premium and medium")
+	}
+	if !suppressServiceLevelDiff("service_level", "standard", "basic", nil) {
+		t.Error("expected no diff between standard and basic")
+	}
+	if suppressServiceLevelDiff("service_level", "standard", "premium", nil) {
+		t.Error("expected a diff between standard and premium")
+	}
+}
+
+func TestValidateServiceLevel(t *testing.T) {
+	for _, level := range []string{"standard", "Premium", "extreme", "standard-sw", "zoneredundantstandardsw", defaultServiceLevel, "basic", "low"} {
+		if err := validateServiceLevel(level, nil); err != nil {
+			t.Errorf("unexpected error for %s: %s", level, err)
+		}
+	}
+	if err := validateServiceLevel("flex", nil); err == nil {
+		t.Error("expected an error for an unknown service level")
+	}
+	if err := validateServiceLevel("flex", []string{"flex"}); err != nil {
+		t.Errorf("unexpected error for an additional service level: %s", err)
+	}
+}
+
+func TestValidateVolumeSize(t *testing.T) {
+	valid := []struct {
+		size  int
+		level string
+	}{
+		{1024, "standard"},
+		{102400, "extreme"},
+		{2048, defaultServiceLevel},
+		{100, "standard-sw"},
+		{1, "zoneredundantstandardsw"},
+		{1, "flex"},
+		{1024, "basic"},
+	}
+	for _, c := range valid {
+		if err := validateVolumeSize(c.size, c.level); err != nil {
+			t.Errorf("unexpected error for %d GiB %s: %s", c.size, c.level, err)
+		}
+	}
+	invalid := []struct {
+		size  int
+		level string
+	}{
+		{1023, "premium"},
+		{102401, "extreme"},
+		{100, defaultServiceLevel},
+		{0, "standard-sw"},
+		{100, "basic"},
+	}
+	for _, c := range invalid {
+		if err := validateVolumeSize(c.size, c.level); err == nil {
+			t.Errorf("expected an error for %d GiB %s", c.size, c.level)
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package gcp
 
 import (
+	"context"
+	"fmt"
+	"log"
 	"sync"
+	"time"
 
+	"github.com/hashicorp/terraform/helper/logging"
 	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/restapi"
 	"github.com/sirupsen/logrus"
 )
@@ -11,6 +16,12 @@ var ourlog = logrus.WithFields(logrus.Fields{
 	"prefix": "main",
 })
 
+// CVSAPI sends the requests of a Client to the Cloud Volumes Service API. restapi.Client implements it for the
+// service, and tests can substitute a fake.
+type CVSAPI interface {
+	Do(ctx context.Context, baseURL string, req *restapi.Request) (int, []byte, error)
+}
+
 // A Client to interact with the GCP REST API
 type Client struct {
 	Host                  string
@@ -20,31 +31,126 @@ type Client struct {
 	Credentials           string
 	Project               string
 	Audience              string
+	ReadOnly              bool
+	ValidateNetwork       bool
+	DefaultStorageClass   string
+	DefaultZone           string
+	FailoverHosts         []string
+	JournalPath           string
+	// AdditionalServiceLevels are service levels accepted in addition to serviceLevelCapabilities
+	AdditionalServiceLevels []string
+	// PollInterval overrides the interval between polls of the waits for state changes if set
+	PollInterval time.Duration
+	// AutoLabeling adds ownership labels to the labels of every volume, see autoLabels
+	AutoLabeling bool
+	// QuotaWarningPercent is the API quota usage to log a warning at, 0 to disable the warning
+	QuotaWarningPercent int
+	// MaxConcurrentDeletes is the number of volume deletions running at once per region, 0 for no limit
+	MaxConcurrentDeletes int
+	// MaxConcurrentJobs is the number of jobs creating, updating or deleting resources running at once in the
+	// project, 0 for no limit
+	MaxConcurrentJobs int
+	// DeleteSnapshotsOnDestroy deletes the snapshots of every volume before deleting the volume
+	DeleteSnapshotsOnDestroy bool
+	// Token is a token minted outside the provider, used instead of the credentials, if set
+	Token string
+	// ImpersonateServiceAccount is the service account whose tokens authorize the API calls, if set
+	ImpersonateServiceAccount string
+	// RequestTimeout is the longest time of a single API request, 0 for no limit
+	RequestTimeout time.Duration
+	// CABundle is the path of a PEM file of CA certificates trusted in addition to the system ones, if set
+	CABundle string
+	// InsecureSkipVerify disables the verification of TLS certificates, for lab use only
+	InsecureSkipVerify bool
+	// Backoff is the backoff between retries of transient errors, restapi.DefaultBackoff if zero
+	Backoff restapi.Backoff
+	// StopContext is canceled when Terraform stops the provider, e.g. on Ctrl-C, canceling API calls and retry delays
+	StopContext context.Context
+	// API sends the requests, the restapi.Client of Host and the credentials if nil
+	API CVSAPI
 
 	initOnce      sync.Once
 	restapiClient *restapi.Client
 	requestSlots  chan int
+	latencies     apiLatencies
+	networks      networkCache
+	faults        faultInjector
+	journal       operationJournal
+	quota         quotaMonitor
+	deletes       jobQueue
+	jobs          jobQueue
+	// snapshotOperations runs the snapshot operations of each volume one at a time, as the backend fails
+	// concurrent ones, so snapshot resources created with for_each don't fail
+	snapshotOperations jobQueue
+	volumeLists        volumeListCache
+	features           featureCache
+	retries            restapi.RetryPolicy
+}
+
+// CallAPIMethod can be used to make a request to any GCP API method, receiving results as byte.
+// params is the JSON body of the request, or a map of query parameters for GET requests.
+// Transient errors are retried as many times as their retry rule allows.
+func (c *Client) CallAPIMethod(method string, baseURL string, params interface{}) (int, []byte, error) {
+	return c.callAPIMethodUntil(method, baseURL, params, time.Time{})
 }
 
-// CallAPIMethod can be used to make a request to any GCP API method, receiving results as byte
-func (c *Client) CallAPIMethod(method string, baseURL string, params map[string]interface{}) (int, []byte, error) {
+// callAPIMethodUntil calls the API method like CallAPIMethod, retrying transient errors until the deadline if it
+// isn't zero
+func (c *Client) callAPIMethodUntil(method string, baseURL string, params interface{}, deadline time.Time) (int, []byte, error) {
 	c.initOnce.Do(c.init)
 
-	c.waitForAvailableSlot()
+	if err := checkRegionPath(method, baseURL); err != nil {
+		return 0, nil, err
+	}
+	if c.ReadOnly && method != "GET" {
+		return 0, nil, fmt.Errorf("provider is configured with read_only = true, refusing to call %s %s", method, baseURL)
+	}
+
+	defer c.volumeLists.invalidate(method, baseURL)
+	// the deadline also aborts a request in flight, not only the retries
+	ctx := c.context()
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	return c.retries.Do(ctx, method, method+" "+baseURL, deadline, func() (int, []byte, error) {
+		return c.callAPIOnce(ctx, method, baseURL, params)
+	})
+}
+
+// callAPIOnce makes a single call of the API method. Injected faults replace the call.
+func (c *Client) callAPIOnce(ctx context.Context, method string, baseURL string, params interface{}) (int, []byte, error) {
+	if statusCode, response, ok := c.faults.inject(method, baseURL); ok {
+		c.journal.record(method, baseURL, params, statusCode, nil, 0)
+		apiMetrics.recordCall(method, baseURL, statusCode, nil)
+		return statusCode, response, nil
+	}
+
+	if err := c.waitForAvailableSlot(); err != nil {
+		return 0, nil, err
+	}
 	defer c.releaseSlot()
 
 	ourlog.WithFields(logrus.Fields{
 		"method": method,
-		"params": params,
 	}).Debug("Calling API")
 
 	if params == nil {
 		params = map[string]interface{}{}
 	}
-	statusCode, result, err := c.restapiClient.Do(baseURL, &restapi.Request{
+	start := time.Now()
+	statusCode, result, err := c.API.Do(ctx, baseURL, &restapi.Request{
 		Method: method,
 		Params: params,
 	})
+	c.journal.record(method, baseURL, params, statusCode, err, time.Since(start))
+	apiMetrics.recordCall(method, baseURL, statusCode, err)
+	if logging.IsDebugOrHigher() {
+		endpoint := latencyEndpoint(method, baseURL)
+		p50, p95, count := c.latencies.record(endpoint, time.Since(start))
+		log.Printf("[DEBUG] API latency for %s: p50=%s p95=%s calls=%d", endpoint, p50, p95, count)
+	}
 	if err != nil {
 		return statusCode, nil, err
 	}
@@ -59,11 +165,37 @@ func (c *Client) init() {
 		c.MaxConcurrentRequests = 6
 	}
 	c.requestSlots = make(chan int, c.MaxConcurrentRequests)
+	c.faults.loadFaults()
+	startMetricsListener()
+	c.journal.path = c.JournalPath
+	c.quota.threshold = c.QuotaWarningPercent
+	c.deletes.limit = c.MaxConcurrentDeletes
+	c.jobs.limit = c.MaxConcurrentJobs
+	c.snapshotOperations.limit = 1
+	c.retries.Rules = retryRules
+	c.retries.Backoff = c.Backoff
+	c.retries.OnRetry = apiMetrics.recordRetry
+	if c.retries.Backoff == (restapi.Backoff{}) {
+		c.retries.Backoff = restapi.DefaultBackoff
+	}
 	c.restapiClient = &restapi.Client{
-		Host:           c.Host,
-		ServiceAccount: c.ServiceAccount,
-		Credentials:    c.Credentials,
-		Audience:       c.Audience,
+		Host:                      c.Host,
+		ServiceAccount:            c.ServiceAccount,
+		Credentials:               c.Credentials,
+		Audience:                  c.Audience,
+		FailoverHosts:             c.FailoverHosts,
+		Token:                     c.Token,
+		ImpersonateServiceAccount: c.ImpersonateServiceAccount,
+		RequestTimeout:            c.RequestTimeout,
+		CABundle:                  c.CABundle,
+		InsecureSkipVerify:        c.InsecureSkipVerify,
+		LogHTTP:                   logging.IsDebugOrHigher(),
+		ObserveRateLimit: func(rateLimit restapi.RateLimit) {
+			c.quota.observe(rateLimit)
+		},
+	}
+	if c.API == nil {
+		c.API = c.restapiClient
 	}
 }
 
@@ -87,8 +219,51 @@ func (c *Client) GetCredentials() string {
 	return c.Credentials
 }
 
-func (c *Client) waitForAvailableSlot() {
-	c.requestSlots <- 1
+// pollInterval returns the interval between polls of a wait for a state change: the provider poll_interval_seconds
+// if set, otherwise the default of the wait
+func (c *Client) pollInterval(defaultInterval time.Duration) time.Duration {
+	if c.PollInterval > 0 {
+		return c.PollInterval
+	}
+	return defaultInterval
+}
+
+// context returns the context of API calls, canceled when Terraform stops the provider
+func (c *Client) context() context.Context {
+	if c.StopContext == nil {
+		return context.Background()
+	}
+	return c.StopContext
+}
+
+// sleep waits for the delay before a retry or poll. It returns early with an error if Terraform stops the provider.
+func (c *Client) sleep(delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-c.context().Done():
+		return fmt.Errorf("canceled, Terraform is stopping: %s", c.context().Err())
+	}
+}
+
+// backoff waits for the retry backoff before the retry following the attempt, counting from 1
+func (c *Client) backoff(attempt int) error {
+	c.initOnce.Do(c.init)
+	return c.sleep(c.retries.Backoff.Delay(attempt))
+}
+
+func (c *Client) waitForAvailableSlot() error {
+	if err := c.context().Err(); err != nil {
+		return fmt.Errorf("canceled, Terraform is stopping: %s", err)
+	}
+	select {
+	case c.requestSlots <- 1:
+		return nil
+	case <-c.context().Done():
+		return fmt.Errorf("canceled, Terraform is stopping: %s", c.context().Err())
+	}
 }
 
 func (c *Client) releaseSlot() {
@@ -1,24 +1,87 @@
 package gcp
 
-import "fmt"
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/restapi"
+)
 
 // Config is a struct for user input
 type configStuct struct {
-	Project        string
-	ServiceAccount string
-	Credentials    string
+	Project                   string
+	APIHost                   string
+	Audience                  string
+	ServiceAccount            string
+	Credentials               string
+	ReadOnly                  bool
+	ValidateNetwork           bool
+	DefaultStorageClass       string
+	DefaultZone               string
+	FailoverHosts             []string
+	JournalPath               string
+	AdditionalServiceLevels   []string
+	PollInterval              time.Duration
+	QuotaWarningPercent       int
+	AutoLabeling              bool
+	MaxConcurrentDeletes      int
+	MaxConcurrentJobs         int
+	DeleteSnapshotsOnDestroy  bool
+	PreflightRegion           string
+	Token                     string
+	ImpersonateServiceAccount string
+	RequestTimeout            time.Duration
+	CABundle                  string
+	InsecureSkipVerify        bool
+	Backoff                   restapi.Backoff
+	StopContext               context.Context
 }
 
 // Client is the main function to connect to the APi
 func (c *configStuct) clientFun() (*Client, error) {
 	client := &Client{
-		Host:     fmt.Sprintf("https://cloudvolumesgcp-api.netapp.com/v2/projects/%s/locations/", c.Project),
-		Audience: "https://cloudvolumesgcp-api.netapp.com",
+		Host:                      apiHost(c.APIHost, c.Project),
+		Audience:                  c.Audience,
+		ReadOnly:                  c.ReadOnly,
+		ValidateNetwork:           c.ValidateNetwork,
+		DefaultStorageClass:       c.DefaultStorageClass,
+		DefaultZone:               c.DefaultZone,
+		FailoverHosts:             c.FailoverHosts,
+		JournalPath:               c.JournalPath,
+		AdditionalServiceLevels:   c.AdditionalServiceLevels,
+		PollInterval:              c.PollInterval,
+		QuotaWarningPercent:       c.QuotaWarningPercent,
+		AutoLabeling:              c.AutoLabeling,
+		MaxConcurrentDeletes:      c.MaxConcurrentDeletes,
+		MaxConcurrentJobs:         c.MaxConcurrentJobs,
+		DeleteSnapshotsOnDestroy:  c.DeleteSnapshotsOnDestroy,
+		Backoff:                   c.Backoff,
+		Token:                     c.Token,
+		ImpersonateServiceAccount: c.ImpersonateServiceAccount,
+		RequestTimeout:            c.RequestTimeout,
+		CABundle:                  c.CABundle,
+		InsecureSkipVerify:        c.InsecureSkipVerify,
+		StopContext:               c.StopContext,
+	}
+
+	if c.InsecureSkipVerify {
+		log.Printf("[WARN] insecure_skip_verify is set, the TLS certificates of the API aren't verified")
+	}
+	if client.Audience == "" {
+		client.Audience = defaultAudience
 	}
 
 	client.SetServiceAccount(c.ServiceAccount)
 	client.SetCredentials(c.Credentials)
 	client.SetProjectID(c.Project)
 
+	if c.PreflightRegion != "" {
+		if err := client.preflight(c.PreflightRegion); err != nil {
+			return nil, err
+		}
+		client.discoverFeatures(c.PreflightRegion)
+	}
+
 	return client, nil
 }
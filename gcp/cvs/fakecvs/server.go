@@ -0,0 +1,507 @@
+// Package fakecvs is an in-memory fake of the NetApp Cloud Volumes Service API for Google Cloud, implementing the
+// endpoints used by the provider. It backs the fakecvs command and the unit tests of the provider, served with
+// httptest.
+package fakecvs
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const spawnJobCreationErrorMessage = "Error creating volume - Cannot spawn additional jobs. Please wait for the ongoing jobs to finish and try again"
+const spawnJobDeletionErrorMessage = "Error deleting volume - Cannot spawn additional jobs. Please wait for the ongoing jobs to finish and try again"
+
+type object map[string]interface{}
+
+// fault makes the next count requests matching method and resource fail
+type fault struct {
+	method   string
+	resource string
+	status   int
+	count    int
+	message  string
+}
+
+type volume struct {
+	data      object
+	startedAt time.Time
+	readyAt   time.Time
+	// job is the ongoing job of a long running operation on the volume, such as a revert
+	job object
+}
+
+// Server serves the fake API at {host}/v2/projects/{project}/locations/
+type Server struct {
+	mutex        sync.Mutex
+	latency      time.Duration
+	provisioning time.Duration
+	faults       []*fault
+	volumes      map[string]*volume
+	snapshots    map[string]map[string]object
+	backups      map[string]map[string]object
+	jobs         map[string]object
+	directories  map[string]object
+	kmsConfigs   map[string]object
+}
+
+// NewServer returns a fake API delaying every response by the latency, where new volumes stay in creating state for
+// the provisioning time
+func NewServer(latency time.Duration, provisioning time.Duration) *Server {
+	return &Server{
+		latency:      latency,
+		provisioning: provisioning,
+		volumes:      make(map[string]*volume),
+		snapshots:    make(map[string]map[string]object),
+		backups:      make(map[string]map[string]object),
+		jobs:         make(map[string]object),
+		directories:  make(map[string]object),
+		kmsConfigs:   make(map[string]object),
+	}
+}
+
+// AddFault makes requests fail, from a specification of the form method:resource:status:count[:message], e.g.
+// POST:Volumes:500:3 for the next 3 volume creations
+func (s *Server) AddFault(spec string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	parts := strings.SplitN(spec, ":", 5)
+	if len(parts) < 4 {
+		return fmt.Errorf("invalid fault %q, expected method:resource:status:count[:message]", spec)
+	}
+	status, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return fmt.Errorf("invalid status in fault %q: %v", spec, err)
+	}
+	count, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return fmt.Errorf("invalid count in fault %q: %v", spec, err)
+	}
+	f := &fault{method: strings.ToUpper(parts[0]), resource: parts[1], status: status, count: count}
+	if len(parts) == 5 {
+		f.message = parts[4]
+	} else if f.resource == "Volumes" && f.method == "POST" && status == 500 {
+		f.message = spawnJobCreationErrorMessage
+	} else if f.resource == "Volumes" && f.method == "DELETE" && status == 500 {
+		f.message = spawnJobDeletionErrorMessage
+	} else {
+		f.message = "Injected fault"
+	}
+	s.faults = append(s.faults, f)
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.latency > 0 {
+		time.Sleep(s.latency)
+	}
+
+	index := strings.Index(r.URL.Path, "/locations/")
+	if index < 0 {
+		writeError(w, http.StatusNotFound, "Unknown path "+r.URL.Path)
+		return
+	}
+	segments := strings.Split(strings.Trim(r.URL.Path[index+len("/locations/"):], "/"), "/")
+	log.Printf("%s %s", r.Method, r.URL.Path)
+
+	var body object
+	if r.Method == "POST" || r.Method == "PUT" {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "Error parsing request body: "+err.Error())
+			return
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if f := s.matchFault(r.Method, resourceName(segments)); f != nil {
+		writeError(w, f.status, f.message)
+		return
+	}
+
+	region := segments[0]
+	switch {
+	case len(segments) == 2 && segments[1] == "VolumeCreationToken" && r.Method == "GET":
+		writeJSON(w, http.StatusOK, object{"creationToken": "fakecvs-" + newID()[:8]})
+	case len(segments) == 2 && segments[1] == "Volumes" && r.Method == "GET":
+		s.listVolumes(w, region)
+	case len(segments) == 2 && (segments[1] == "Volumes" || segments[1] == "DataProtectionVolumes") && r.Method == "POST":
+		s.createVolume(w, region, body, segments[1] == "DataProtectionVolumes")
+	case len(segments) == 3 && segments[1] == "Volumes":
+		s.volume(w, r.Method, segments[2], body)
+	case len(segments) == 4 && segments[1] == "Volumes" && segments[3] == "Revert" && r.Method == "POST":
+		s.revertVolume(w, segments[2], body)
+	case len(segments) >= 4 && segments[1] == "Volumes" && segments[3] == "Snapshots":
+		s.children(w, r.Method, region, segments, body, s.snapshots, "snapshotId")
+	case len(segments) == 2 && segments[1] == "Backups" && r.Method == "GET":
+		s.listBackups(w, region)
+	case len(segments) >= 4 && segments[1] == "Volumes" && segments[3] == "Backups":
+		s.children(w, r.Method, region, segments, body, s.backups, "backupId")
+	case len(segments) == 2 && segments[1] == "Jobs" && r.Method == "GET":
+		s.listJobs(w, region)
+	case len(segments) == 3 && segments[1] == "Jobs" && r.Method == "GET":
+		if job, ok := s.jobs[segments[2]]; ok {
+			if v, ok := s.volumes[job["volumeId"].(string)]; ok {
+				s.refresh(v)
+			}
+			writeJSON(w, http.StatusOK, job)
+		} else {
+			writeError(w, http.StatusNotFound, "Job not found")
+		}
+	case len(segments) >= 3 && segments[1] == "Storage" && segments[2] == "ActiveDirectory":
+		s.activeDirectory(w, r.Method, region, segments, body)
+	case len(segments) >= 3 && segments[1] == "Storage" && segments[2] == "KmsConfig":
+		s.kmsConfig(w, r.Method, region, segments, body)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Unsupported request %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// resourceName returns the last path segment that isn't an ID, e.g. Snapshots for {region}/Volumes/{id}/Snapshots/{id}
+func resourceName(segments []string) string {
+	for i := len(segments) - 1; i > 0; i-- {
+		if len(segments[i]) != 36 {
+			return segments[i]
+		}
+	}
+	return ""
+}
+
+func (s *Server) matchFault(method string, resource string) *fault {
+	for _, f := range s.faults {
+		if f.count > 0 && f.method == method && f.resource == resource {
+			f.count--
+			return f
+		}
+	}
+	return nil
+}
+
+func (s *Server) listVolumes(w http.ResponseWriter, region string) {
+	volumes := []object{}
+	for _, v := range s.volumes {
+		s.refresh(v)
+		if v.data["region"] == region && v.data["lifeCycleState"] != "deleted" {
+			volumes = append(volumes, v.data)
+		}
+	}
+	writeJSON(w, http.StatusOK, volumes)
+}
+
+// listBackups lists the backups of all volumes of the region
+func (s *Server) listBackups(w http.ResponseWriter, region string) {
+	backups := []object{}
+	for _, items := range s.backups {
+		for _, backup := range items {
+			if backup["region"] == region {
+				backups = append(backups, backup)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, backups)
+}
+
+func (s *Server) createVolume(w http.ResponseWriter, region string, body object, dataProtection bool) {
+	if snapshotID, ok := body["snapshotId"].(string); ok && !s.hasSnapshot(snapshotID) {
+		writeError(w, http.StatusNotFound, "Error creating volume - Snapshot not found")
+		return
+	}
+	id := newID()
+	body["volumeId"] = id
+	body["region"] = region
+	body["isDataProtection"] = dataProtection
+	body["lifeCycleState"] = "creating"
+	body["lifeCycleStateDetails"] = "Creation in progress"
+	body["created"] = time.Now().UTC().Format(time.RFC3339)
+	if _, ok := body["creationToken"]; !ok {
+		body["creationToken"] = "fakecvs-" + id[:8]
+	}
+	mountPoints := []object{}
+	if protocols, ok := body["protocolTypes"].([]interface{}); ok {
+		for _, protocol := range protocols {
+			export := "/" + body["creationToken"].(string)
+			if protocol == "CIFS" {
+				export = `\\fakecvs-smb.example.com\` + body["creationToken"].(string)
+			}
+			mountPoints = append(mountPoints, object{
+				"export":       export,
+				"server":       "10.0.0.2",
+				"protocolType": protocol,
+			})
+		}
+	}
+	body["mountPoints"] = mountPoints
+	s.volumes[id] = &volume{data: body, readyAt: time.Now().Add(s.provisioning)}
+	job := s.addJob(region, id, "create")
+	writeJSON(w, http.StatusAccepted, object{
+		"response": object{"AnyValue": body},
+		"jobs":     []object{job},
+	})
+}
+
+func (s *Server) volume(w http.ResponseWriter, method string, id string, body object) {
+	v, ok := s.volumes[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Error describing volume - Volume not found")
+		return
+	}
+	s.refresh(v)
+	switch method {
+	case "GET":
+		writeJSON(w, http.StatusOK, v.data)
+	case "PUT":
+		for key, value := range body {
+			if value != nil && key != "volumeId" && key != "region" {
+				v.data[key] = value
+			}
+		}
+		job := s.addJob(v.data["region"].(string), id, "update")
+		response := object{}
+		for key, value := range v.data {
+			response[key] = value
+		}
+		response["jobs"] = []object{job}
+		writeJSON(w, http.StatusOK, response)
+	case "DELETE":
+		if len(s.snapshots[id]) > 0 {
+			writeError(w, http.StatusBadRequest, "Error deleting volume - Volume has snapshots")
+			return
+		}
+		v.data["lifeCycleState"] = "deleted"
+		v.data["lifeCycleStateDetails"] = "Volume deleted"
+		job := s.addJob(v.data["region"].(string), id, "delete")
+		writeJSON(w, http.StatusOK, object{"jobs": []object{job}})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// hasSnapshot checks whether a snapshot exists on any volume
+func (s *Server) hasSnapshot(snapshotID string) bool {
+	for _, snapshots := range s.snapshots {
+		if _, ok := snapshots[snapshotID]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) revertVolume(w http.ResponseWriter, id string, body object) {
+	v, ok := s.volumes[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Error describing volume - Volume not found")
+		return
+	}
+	snapshotID, _ := body["snapshotId"].(string)
+	if _, ok := s.snapshots[id][snapshotID]; !ok {
+		writeError(w, http.StatusNotFound, "Error reverting volume - Snapshot not found")
+		return
+	}
+	job := s.addJob(v.data["region"].(string), id, "revert")
+	job["state"] = "ongoing"
+	job["stateDetails"] = "Job is in progress"
+	job["progress"] = 0
+	v.data["lifeCycleState"] = "updating"
+	v.data["lifeCycleStateDetails"] = "Revert in progress"
+	v.startedAt = time.Now()
+	v.readyAt = v.startedAt.Add(s.provisioning)
+	v.job = job
+	writeJSON(w, http.StatusOK, object{"jobs": []object{job}})
+}
+
+// children handles snapshots and backups of a volume, which share the same API shape
+func (s *Server) children(w http.ResponseWriter, method string, region string, segments []string, body object, store map[string]map[string]object, idField string) {
+	volumeID := segments[2]
+	if v, ok := s.volumes[volumeID]; !ok || v.data["lifeCycleState"] == "deleted" {
+		writeError(w, http.StatusNotFound, "Error describing volume - Volume not found")
+		return
+	}
+	if store[volumeID] == nil {
+		store[volumeID] = make(map[string]object)
+	}
+	items := store[volumeID]
+
+	if len(segments) == 4 {
+		switch method {
+		case "GET":
+			list := []object{}
+			for _, item := range items {
+				list = append(list, item)
+			}
+			writeJSON(w, http.StatusOK, list)
+		case "POST":
+			id := newID()
+			body[idField] = id
+			body["volumeId"] = volumeID
+			body["region"] = region
+			body["lifeCycleState"] = "available"
+			body["created"] = time.Now().UTC().Format(time.RFC3339)
+			body["usedBytes"] = 0
+			items[id] = body
+			job := s.addJob(region, volumeID, "create")
+			writeJSON(w, http.StatusAccepted, object{
+				"response": object{"AnyValue": body},
+				"jobs":     []object{job},
+			})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+
+	item, ok := items[segments[4]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	switch method {
+	case "GET":
+		writeJSON(w, http.StatusOK, item)
+	case "PUT":
+		if name, ok := body["name"]; ok {
+			item["name"] = name
+		}
+		writeJSON(w, http.StatusOK, item)
+	case "DELETE":
+		delete(items, segments[4])
+		job := s.addJob(region, volumeID, "delete")
+		writeJSON(w, http.StatusOK, object{"jobs": []object{job}})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *Server) activeDirectory(w http.ResponseWriter, method string, region string, segments []string, body object) {
+	switch {
+	case len(segments) == 3 && method == "GET":
+		list := []object{}
+		for _, directory := range s.directories {
+			list = append(list, directory)
+		}
+		writeJSON(w, http.StatusOK, list)
+	case len(segments) == 3 && method == "POST":
+		if _, ok := s.directories[region]; ok {
+			writeError(w, http.StatusConflict, "Active Directory already exists in region")
+			return
+		}
+		body["UUID"] = newID()
+		body["region"] = region
+		s.directories[region] = body
+		writeJSON(w, http.StatusOK, body)
+	case len(segments) == 4 && method == "PUT":
+		body["UUID"] = segments[3]
+		body["region"] = region
+		s.directories[region] = body
+		writeJSON(w, http.StatusOK, body)
+	case len(segments) == 4 && method == "DELETE":
+		delete(s.directories, region)
+		writeJSON(w, http.StatusOK, object{})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *Server) kmsConfig(w http.ResponseWriter, method string, region string, segments []string, body object) {
+	switch {
+	case len(segments) == 3 && method == "GET":
+		list := []object{}
+		for _, config := range s.kmsConfigs {
+			if config["region"] == region {
+				list = append(list, config)
+			}
+		}
+		writeJSON(w, http.StatusOK, list)
+	case len(segments) == 3 && method == "POST":
+		body["uuid"] = newID()
+		body["region"] = region
+		body["state"] = "ready"
+		body["stateDetails"] = "Available for use"
+		s.kmsConfigs[body["uuid"].(string)] = body
+		writeJSON(w, http.StatusOK, body)
+	case len(segments) == 4 && method == "DELETE":
+		if _, ok := s.kmsConfigs[segments[3]]; !ok {
+			writeError(w, http.StatusNotFound, "KMS config not found")
+			return
+		}
+		delete(s.kmsConfigs, segments[3])
+		writeJSON(w, http.StatusOK, object{})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *Server) listJobs(w http.ResponseWriter, region string) {
+	jobs := []object{}
+	for _, job := range s.jobs {
+		if job["region"] == region {
+			jobs = append(jobs, job)
+		}
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (s *Server) addJob(region string, volumeID string, action string) object {
+	job := object{
+		"jobId":        newID(),
+		"action":       action,
+		"region":       region,
+		"volumeId":     volumeID,
+		"state":        "done",
+		"stateDetails": "Job completed",
+		"progress":     100,
+		"created":      time.Now().UTC().Format(time.RFC3339),
+	}
+	s.jobs[job["jobId"].(string)] = job
+	return job
+}
+
+// refresh moves a volume out of the creating state once its provisioning time has passed,
+// and advances the progress of its ongoing job
+func (s *Server) refresh(v *volume) {
+	if v.data["lifeCycleState"] == "creating" && time.Now().After(v.readyAt) {
+		v.data["lifeCycleState"] = "available"
+		v.data["lifeCycleStateDetails"] = "Available for use"
+	}
+	if v.job == nil {
+		return
+	}
+	if time.Now().After(v.readyAt) {
+		v.job["state"] = "done"
+		v.job["stateDetails"] = "Job completed"
+		v.job["progress"] = 100
+		v.job = nil
+		v.data["lifeCycleState"] = "available"
+		v.data["lifeCycleStateDetails"] = "Available for use"
+		return
+	}
+	v.job["progress"] = int(100 * time.Since(v.startedAt) / v.readyAt.Sub(v.startedAt))
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, object{"code": status, "message": message})
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
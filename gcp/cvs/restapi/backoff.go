@@ -0,0 +1,40 @@
+package restapi
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff is an exponential backoff with jitter: the delay before the nth retry is Base doubled n-1 times, up to
+// Cap, and shortened by a random part so parallel calls failing at once don't retry in lockstep
+type Backoff struct {
+	Base time.Duration
+	Cap  time.Duration
+	// Jitter is the random part of each delay, from 0 for none to 1 for a delay anywhere up to the full delay
+	Jitter float64
+	// MaxElapsed bounds the time spent retrying a call without a deadline, 0 for no bound
+	MaxElapsed time.Duration
+}
+
+// DefaultBackoff is the backoff of calls failing with a transient error if none is configured
+var DefaultBackoff = Backoff{Base: 10 * time.Second, Cap: 60 * time.Second, Jitter: 0.5}
+
+// Delay returns the delay before the retry following the attempt, counting from 1
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Base
+	for i := 1; i < attempt && delay < b.Cap; i++ {
+		delay *= 2
+	}
+	if delay > b.Cap {
+		delay = b.Cap
+	}
+	if jitter := int64(float64(delay) * b.Jitter); jitter > 0 {
+		delay -= time.Duration(rand.Int63n(jitter + 1))
+	}
+	return delay
+}
+
+// exceeded reports whether the time spent retrying since start reached MaxElapsed
+func (b Backoff) exceeded(start time.Time) bool {
+	return b.MaxElapsed > 0 && time.Since(start) >= b.MaxElapsed
+}
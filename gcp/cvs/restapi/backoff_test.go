@@ -0,0 +1,28 @@
+package restapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	backoff := Backoff{Base: 10 * time.Second, Cap: 60 * time.Second}
+	for attempt, expected := range map[int]time.Duration{
+		1:  10 * time.Second,
+		2:  20 * time.Second,
+		3:  40 * time.Second,
+		4:  60 * time.Second,
+		50: 60 * time.Second,
+	} {
+		if delay := backoff.Delay(attempt); delay != expected {
+			t.Errorf("attempt %d: expected %s, got %s", attempt, expected, delay)
+		}
+	}
+
+	backoff.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if delay := backoff.Delay(2); delay < 10*time.Second || delay > 20*time.Second {
+			t.Fatalf("expected a delay between 10s and 20s, got %s", delay)
+		}
+	}
+}
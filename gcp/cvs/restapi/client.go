@@ -1,10 +1,17 @@
 package restapi
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Client represents a client for interaction with a GCP REST API
@@ -13,35 +20,154 @@ type Client struct {
 	ServiceAccount string
 	Credentials    string
 	Audience       string
+	// FailoverHosts are tried in order when Host is unreachable or unavailable
+	FailoverHosts []string
+	// Token is a token minted outside the provider, used instead of the service account key, if set. With
+	// ImpersonateServiceAccount it is the OAuth access token of the caller, otherwise the JWT authorizing requests.
+	Token string
+	// ImpersonateServiceAccount is the email of a service account whose tokens authorize the requests, requested
+	// with the credentials of the client, if set
+	ImpersonateServiceAccount string
+	// RequestTimeout bounds each request, from sending it to reading the response, if set
+	RequestTimeout time.Duration
+	// CABundle is the path of a PEM file of CA certificates trusted in addition to the system ones, if set
+	CABundle string
+	// InsecureSkipVerify disables the verification of the certificates of the API, for lab use only
+	InsecureSkipVerify bool
+	// LogHTTP logs every request and response at DEBUG level, with credentials redacted, including the requests
+	// for tokens
+	LogHTTP bool
+	// ObserveRateLimit is called with the rate limit reported by a response, if set
+	ObserveRateLimit func(RateLimit)
 
-	httpClient http.Client
+	transportOnce sync.Once
+	transportErr  error
+	httpClient    *http.Client
+	tokens        tokenCache
 }
 
-// Do sends the API Request, parses the response as JSON, and returns the HTTP status code as int, the "result" value as byte
-func (c *Client) Do(baseURL string, req *Request) (int, []byte, error) {
+// Do sends the API Request, parses the response as JSON, and returns the HTTP status code as int, the "result" value as byte.
+// If the host is unreachable or answers 502, 503 or 504, the request is sent to the failover hosts in turn.
+// Canceling the context aborts the request in flight and the failover.
+func (c *Client) Do(ctx context.Context, baseURL string, req *Request) (int, []byte, error) {
+	hosts := append([]string{c.Host}, c.FailoverHosts...)
+	var statusCode int
+	var res []byte
+	var err error
+	for i, host := range hosts {
+		statusCode, res, err = c.do(ctx, host, baseURL, req)
+		if i == len(hosts)-1 || ctx.Err() != nil || !shouldFailOver(req.Method, statusCode, err) {
+			break
+		}
+		log.Printf("[WARN] API host %s unavailable (code: %d, error: %v), failing over to %s", host, statusCode, err, hosts[i+1])
+	}
+	return statusCode, res, err
+}
+
+// shouldFailOver decides whether a request can be retried on another host. Requests creating resources are only
+// retried if the connection couldn't be made, as the failed host may have processed them.
+func shouldFailOver(method string, statusCode int, err error) bool {
+	var timeoutErr *RequestTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return method != "POST"
+	}
+	if err != nil && statusCode == 0 {
+		// only transport errors, not e.g. failing to read the service account key
+		var urlErr *url.Error
+		if !errors.As(err, &urlErr) {
+			return false
+		}
+		var opErr *net.OpError
+		if method == "POST" {
+			return errors.As(err, &opErr) && opErr.Op == "dial"
+		}
+		return true
+	}
+	if method == "POST" {
+		return false
+	}
+	return statusCode == http.StatusBadGateway || statusCode == http.StatusServiceUnavailable || statusCode == http.StatusGatewayTimeout
+}
+
+func (c *Client) do(ctx context.Context, host string, baseURL string, req *Request) (int, []byte, error) {
 
-	httpReq, err := req.BuildHTTPReq(c.Host, c.ServiceAccount, c.Credentials, c.Audience, baseURL)
+	jwt, err := c.token()
+	if err != nil {
+		return 0, nil, err
+	}
+	requestCtx := ctx
+	if c.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		requestCtx, cancel = context.WithTimeout(ctx, c.RequestTimeout)
+		defer cancel()
+	}
+	httpReq, err := req.BuildHTTPReq(requestCtx, host, jwt, baseURL)
 	if err != nil {
 		return 0, nil, err
 	}
 
-	httpRes, err := c.httpClient.Do(httpReq)
+	httpClient, err := c.client()
 	if err != nil {
-		log.Print("HTTP req failed")
 		return 0, nil, err
 	}
+	httpRes, err := httpClient.Do(httpReq)
+	if err != nil {
+		log.Print("HTTP req failed")
+		return 0, nil, c.requestError(ctx, httpReq, err)
+	}
 
 	defer httpRes.Body.Close()
 
+	if c.ObserveRateLimit != nil {
+		if rateLimit, ok := ParseRateLimit(httpRes.Header); ok {
+			c.ObserveRateLimit(rateLimit)
+		}
+	}
+
 	res, err := ioutil.ReadAll(httpRes.Body)
 	if err != nil {
 		log.Print("HTTP decoder failed")
-		return 0, nil, err
+		return 0, nil, c.requestError(ctx, httpReq, err)
 	}
 
 	if res == nil {
 		return 0, nil, errors.New("No result returned in REST response")
 	}
 
+	contentType := httpRes.Header.Get("Content-Type")
+	if len(res) > 0 && contentType != "" && !strings.Contains(contentType, "json") {
+		return httpRes.StatusCode, res, &UnexpectedResponseError{
+			Method:      httpReq.Method,
+			URL:         httpReq.URL.String(),
+			StatusCode:  httpRes.StatusCode,
+			ContentType: contentType,
+			Body:        res,
+		}
+	}
+
+	if httpRes.StatusCode == http.StatusUnauthorized && c.Token != "" {
+		return httpRes.StatusCode, res, fmt.Errorf("the API rejected the token of the provider (code: 401, response: %s), it may have expired. Pass a new token to the provider", BodySnippet(res))
+	}
+
 	return httpRes.StatusCode, res, nil
 }
+
+// RequestTimeoutError is returned when a request doesn't complete within the RequestTimeout of the client
+type RequestTimeoutError struct {
+	Method  string
+	URL     string
+	Timeout time.Duration
+}
+
+func (e *RequestTimeoutError) Error() string {
+	return fmt.Sprintf("%s %s didn't complete within the request timeout of %s", e.Method, e.URL, e.Timeout)
+}
+
+// requestError returns a RequestTimeoutError for a request failing because its RequestTimeout expired, the error of
+// the request otherwise. Errors of the deadline or cancellation of the context of the caller are kept as is.
+func (c *Client) requestError(ctx context.Context, req *http.Request, err error) error {
+	if req.Context().Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		return &RequestTimeoutError{Method: req.Method, URL: req.URL.String(), Timeout: c.RequestTimeout}
+	}
+	return err
+}
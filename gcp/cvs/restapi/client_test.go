@@ -0,0 +1,82 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestShouldFailOver(t *testing.T) {
+	dialErr := &url.Error{Op: "Post", URL: "https://example.com", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+	readErr := &url.Error{Op: "Post", URL: "https://example.com", Err: &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}}
+	cases := []struct {
+		method     string
+		statusCode int
+		err        error
+		expected   bool
+	}{
+		{"GET", 0, dialErr, true},
+		{"GET", 0, readErr, true},
+		{"GET", 503, nil, true},
+		{"DELETE", 504, nil, true},
+		{"GET", 500, nil, false},
+		{"GET", 404, nil, false},
+		{"GET", 0, errors.New("Unable to read service account key file"), false},
+		{"POST", 0, dialErr, true},
+		{"POST", 0, readErr, false},
+		{"POST", 503, nil, false},
+		{"GET", 0, &RequestTimeoutError{Method: "GET"}, true},
+		{"POST", 0, &RequestTimeoutError{Method: "POST"}, false},
+	}
+	for _, tc := range cases {
+		if result := shouldFailOver(tc.method, tc.statusCode, tc.err); result != tc.expected {
+			t.Errorf("shouldFailOver(%s, %d, %v) = %v, expected %v", tc.method, tc.statusCode, tc.err, result, tc.expected)
+		}
+	}
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[]`)
+	}))
+	defer fast.Close()
+
+	client := &Client{Host: slow.URL + "/", Token: "opaque-access-token", RequestTimeout: 20 * time.Millisecond}
+	_, _, err := client.Do(context.Background(), "us-east4/Volumes", &Request{Method: "GET"})
+	if _, ok := err.(*RequestTimeoutError); !ok {
+		t.Errorf("expected a request timeout error, got %v", err)
+	}
+
+	// the deadline of the caller isn't reported as the request timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	client.RequestTimeout = time.Minute
+	_, _, err = client.Do(ctx, "us-east4/Volumes", &Request{Method: "GET"})
+	if _, ok := err.(*RequestTimeoutError); ok || err == nil {
+		t.Errorf("expected the error of the deadline of the caller, got %v", err)
+	}
+
+	// a read timing out fails over, a creation doesn't
+	client.RequestTimeout = 20 * time.Millisecond
+	client.FailoverHosts = []string{fast.URL + "/"}
+	if statusCode, _, err := client.Do(context.Background(), "us-east4/Volumes", &Request{Method: "GET"}); err != nil || statusCode != 200 {
+		t.Errorf("expected the read to fail over, got %d, %v", statusCode, err)
+	}
+	if _, _, err := client.Do(context.Background(), "us-east4/Volumes", &Request{Method: "POST", Params: map[string]interface{}{}}); err == nil {
+		t.Error("expected the creation to time out without failing over")
+	}
+}
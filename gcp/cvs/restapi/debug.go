@@ -0,0 +1,141 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// redacted replaces credentials in the debug log
+const redacted = "REDACTED"
+
+// redactedHeaders are the headers carrying credentials
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// redactedFields are the JSON fields and form values carrying credentials, in the API requests, e.g. the password
+// of an Active Directory connection, and in the requests and responses of the token exchanges
+var redactedFields = map[string]bool{
+	"password":       true,
+	"private_key":    true,
+	"private_key_id": true,
+	"client_secret":  true,
+	"assertion":      true,
+	"subject_token":  true,
+	"access_token":   true,
+	"id_token":       true,
+	"refresh_token":  true,
+	"token":          true,
+	"accesstoken":    true,
+}
+
+// loggingTransport logs the requests and responses of a transport with their credentials redacted
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] HTTP request: %s %s\n%s", req.Method, req.URL, dumpMessage(req.Header, body))
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		log.Printf("[DEBUG] HTTP request %s %s failed: %s", req.Method, req.URL, err)
+		return res, err
+	}
+	body, err = readBody(&res.Body)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] HTTP response of %s %s: %s\n%s", req.Method, req.URL, res.Status, dumpMessage(res.Header, body))
+	return res, nil
+}
+
+// readBody reads a request or response body and replaces it with a reader of what was read
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	content, err := ioutil.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = ioutil.NopCloser(bytes.NewReader(content))
+	return content, nil
+}
+
+// dumpMessage formats the headers and body of a request or response with the credentials redacted
+func dumpMessage(header http.Header, body []byte) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var dump strings.Builder
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			value = redacted
+		}
+		fmt.Fprintf(&dump, "%s: %s\n", name, value)
+	}
+	if len(body) > 0 {
+		dump.WriteString("\n")
+		dump.WriteString(redactBody(header.Get("Content-Type"), body))
+	}
+	return dump.String()
+}
+
+// redactBody replaces the credentials of a JSON or form encoded body
+func redactBody(contentType string, body []byte) string {
+	var content interface{}
+	if err := json.Unmarshal(body, &content); err == nil {
+		redacted, err := json.Marshal(redactJSON(content))
+		if err == nil {
+			return string(redacted)
+		}
+	}
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			for name := range values {
+				if redactedFields[strings.ToLower(name)] {
+					values.Set(name, redacted)
+				}
+			}
+			return values.Encode()
+		}
+	}
+	return string(body)
+}
+
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if redactedFields[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
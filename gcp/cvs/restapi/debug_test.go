@@ -0,0 +1,58 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestClientLogHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=cookie-value")
+		fmt.Fprint(w, `{"domain": "example.com", "password": "response-password", "accessToken": "response-token"}`)
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	client := &Client{Host: server.URL + "/", Token: "opaque-access-token", LogHTTP: true}
+	params := map[string]interface{}{"domain": "example.com", "password": "request-password", "labels": []interface{}{map[string]interface{}{"private_key": "key"}}}
+	statusCode, response, err := client.Do(context.Background(), "us-east4/Storage/ActiveDirectory", &Request{Method: "POST", Params: params})
+	if err != nil || statusCode != 200 {
+		t.Fatalf("expected a successful request, got %d, %v", statusCode, err)
+	}
+	if !strings.Contains(string(response), "response-token") {
+		t.Errorf("expected the response to be readable after logging it, got %s", response)
+	}
+	logged := output.String()
+	for _, secret := range []string{"opaque-access-token", "request-password", `"key"`, "response-password", "response-token", "cookie-value"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("expected %s to be redacted, got:\n%s", secret, logged)
+		}
+	}
+	for _, expected := range []string{"HTTP request: POST " + server.URL, "Authorization: REDACTED", `"domain":"example.com"`, "HTTP response of POST", "200 OK"} {
+		if !strings.Contains(logged, expected) {
+			t.Errorf("expected %s in the log, got:\n%s", expected, logged)
+		}
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	form := "grant_type=urn%3Aietf%3Aparams%3Aoauth%3Agrant-type%3Ajwt-bearer&assertion=signed.jwt.value"
+	redactedForm := redactBody("application/x-www-form-urlencoded", []byte(form))
+	if strings.Contains(redactedForm, "signed.jwt.value") || !strings.Contains(redactedForm, "grant_type=") {
+		t.Errorf("expected the assertion to be redacted, got %s", redactedForm)
+	}
+	if text := redactBody("text/plain", []byte("plain text")); text != "plain text" {
+		t.Errorf("expected other bodies as is, got %s", text)
+	}
+}
@@ -0,0 +1,221 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jws"
+)
+
+// cloudPlatformScope is the scope of the federated token exchanged for the tokens of the service account
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// externalAccount is an external_account credential configuration of workload identity federation, e.g. for
+// GitHub Actions OIDC tokens. The token of the external identity is exchanged with the Security Token Service for a
+// federated token, which impersonates the service account to get its tokens.
+type externalAccount struct {
+	Type                           string `json:"type"`
+	Audience                       string `json:"audience"`
+	SubjectTokenType               string `json:"subject_token_type"`
+	TokenURL                       string `json:"token_url"`
+	ServiceAccountImpersonationURL string `json:"service_account_impersonation_url"`
+	CredentialSource               struct {
+		File          string            `json:"file"`
+		URL           string            `json:"url"`
+		Headers       map[string]string `json:"headers"`
+		EnvironmentID string            `json:"environment_id"`
+		Format        struct {
+			Type                  string `json:"type"`
+			SubjectTokenFieldName string `json:"subject_token_field_name"`
+		} `json:"format"`
+	} `json:"credential_source"`
+}
+
+// IsExternalAccount reports whether the credentials are an external_account credential configuration
+func IsExternalAccount(key []byte) bool {
+	var credentials struct {
+		Type string `json:"type"`
+	}
+	return json.Unmarshal(key, &credentials) == nil && credentials.Type == "external_account"
+}
+
+// parseExternalAccount decodes an external_account credential configuration. The service account impersonation
+// is required, as the API only accepts tokens of service accounts.
+func parseExternalAccount(key []byte) (*externalAccount, error) {
+	var account externalAccount
+	if err := json.Unmarshal(key, &account); err != nil {
+		return nil, fmt.Errorf("Error decoding external account credentials: %v", err)
+	}
+	if account.TokenURL == "" || account.Audience == "" || account.SubjectTokenType == "" {
+		return nil, fmt.Errorf("external account credentials require token_url, audience and subject_token_type")
+	}
+	if account.ServiceAccountImpersonationURL == "" {
+		return nil, fmt.Errorf("external account credentials require service_account_impersonation_url, the API only accepts service accounts")
+	}
+	if account.CredentialSource.EnvironmentID != "" {
+		return nil, fmt.Errorf("external account credentials of environment %s aren't supported, only file and url credential sources are", account.CredentialSource.EnvironmentID)
+	}
+	if account.CredentialSource.File == "" && account.CredentialSource.URL == "" {
+		return nil, fmt.Errorf("external account credentials require a file or url credential source")
+	}
+	return &account, nil
+}
+
+// ExternalAccountAccessToken returns an access token of the service account impersonated by external_account
+// credentials for the scope, e.g. for Google APIs other than the API of the client
+func ExternalAccountAccessToken(ctx context.Context, key []byte, scope string) (*oauth2.Token, error) {
+	account, err := parseExternalAccount(key)
+	if err != nil {
+		return nil, err
+	}
+	return account.accessToken(ctx, scope)
+}
+
+// idToken returns an identity token of the impersonated service account for the audience, the JWT the API accepts
+func (a *externalAccount) idToken(ctx context.Context, audience string) (*oauth2.Token, error) {
+	var response struct {
+		Token string `json:"token"`
+	}
+	url := strings.Replace(a.ServiceAccountImpersonationURL, ":generateAccessToken", ":generateIdToken", 1)
+	body := map[string]interface{}{"audience": audience, "includeEmail": true}
+	if err := a.impersonate(ctx, url, body, &response); err != nil {
+		return nil, err
+	}
+	claims, err := jws.Decode(response.Token)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to decode the identity token of the service account: %v", err)
+	}
+	return &oauth2.Token{AccessToken: response.Token, TokenType: "Bearer", Expiry: time.Unix(claims.Exp, 0)}, nil
+}
+
+// accessToken returns an access token of the impersonated service account for the scope
+func (a *externalAccount) accessToken(ctx context.Context, scope string) (*oauth2.Token, error) {
+	var response struct {
+		AccessToken string    `json:"accessToken"`
+		ExpireTime  time.Time `json:"expireTime"`
+	}
+	if err := a.impersonate(ctx, a.ServiceAccountImpersonationURL, map[string]interface{}{"scope": []string{scope}}, &response); err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: response.AccessToken, TokenType: "Bearer", Expiry: response.ExpireTime}, nil
+}
+
+// impersonate calls an IAM Credentials method of the service account with the federated token
+func (a *externalAccount) impersonate(ctx context.Context, url string, body interface{}, v interface{}) error {
+	federatedToken, err := a.federatedToken(ctx)
+	if err != nil {
+		return err
+	}
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyJSON))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+federatedToken)
+	return doJSON(contextClient(ctx), req, v, "impersonating the service account")
+}
+
+// federatedToken exchanges the subject token of the external identity for a federated token
+func (a *externalAccount) federatedToken(ctx context.Context) (string, error) {
+	subjectToken, err := a.subjectToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type":           {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"requested_token_type": {"urn:ietf:params:oauth:token-type:access_token"},
+		"audience":             {a.Audience},
+		"scope":                {cloudPlatformScope},
+		"subject_token_type":   {a.SubjectTokenType},
+		"subject_token":        {subjectToken},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", a.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var response struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSON(contextClient(ctx), req, &response, "exchanging the external account token"); err != nil {
+		return "", err
+	}
+	return response.AccessToken, nil
+}
+
+// subjectToken reads the token of the external identity from the file or URL of the credential source
+func (a *externalAccount) subjectToken(ctx context.Context) (string, error) {
+	source := a.CredentialSource
+	var content []byte
+	var err error
+	if source.File != "" {
+		content, err = ioutil.ReadFile(source.File)
+		if err != nil {
+			return "", fmt.Errorf("Unable to read the external account token file %v", err)
+		}
+	} else {
+		req, err := http.NewRequestWithContext(ctx, "GET", source.URL, nil)
+		if err != nil {
+			return "", err
+		}
+		for name, value := range source.Headers {
+			req.Header.Set(name, value)
+		}
+		res, err := contextClient(ctx).Do(req)
+		if err != nil {
+			return "", fmt.Errorf("Unable to get the external account token: %v", err)
+		}
+		defer res.Body.Close()
+		content, err = ioutil.ReadAll(res.Body)
+		if err != nil {
+			return "", err
+		}
+		if res.StatusCode >= 300 || res.StatusCode < 200 {
+			return "", fmt.Errorf("Unable to get the external account token, code: %d, response: %s", res.StatusCode, BodySnippet(content))
+		}
+	}
+	if source.Format.Type != "json" {
+		return strings.TrimSpace(string(content)), nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return "", fmt.Errorf("Error decoding the external account token: %v", err)
+	}
+	token, ok := fields[source.Format.SubjectTokenFieldName].(string)
+	if !ok {
+		return "", fmt.Errorf("external account token has no %s field", source.Format.SubjectTokenFieldName)
+	}
+	return token, nil
+}
+
+// doJSON sends the request with the HTTP client and decodes the JSON response into v
+func doJSON(httpClient *http.Client, req *http.Request, v interface{}, operation string) error {
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Error %s: %v", operation, err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode >= 300 || res.StatusCode < 200 {
+		return fmt.Errorf("Error %s, code: %d, response: %s", operation, res.StatusCode, BodySnippet(body))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("Error %s, failed to decode response: %v", operation, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package restapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// unsignedJWT returns a JWT expiring at exp. Only the expiry of the tokens is read.
+func unsignedJWT(exp int64) string {
+	return "e30." + base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp": %d}`, exp))) + ".c2ln"
+}
+
+// externalAccountServer serves the token exchange and the impersonation of the caller service account of
+// workload identity federation, and returns the credential configuration using it with a GitHub OIDC token file.
+// The federated token impersonates the caller for an identity token or an access token of the caller.
+func externalAccountServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, string, func()) {
+	tokenFile, err := ioutil.TempFile("", "oidc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokenFile.WriteString(`{"value": "github-oidc-token"}`); err != nil {
+		t.Fatal(err)
+	}
+	tokenFile.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/token":
+			if err := r.ParseForm(); err != nil || r.Form.Get("subject_token") != "github-oidc-token" {
+				http.Error(w, "unexpected subject token", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"access_token": "federated-token"}`)
+		case "/caller/terraform@example.iam.gserviceaccount.com:generateIdToken":
+			var body map[string]interface{}
+			if r.Header.Get("Authorization") != "Bearer federated-token" || json.NewDecoder(r.Body).Decode(&body) != nil || body["audience"] != "https://cloudvolumesgcp-api.netapp.com" {
+				http.Error(w, "unexpected request", http.StatusForbidden)
+				return
+			}
+			fmt.Fprintf(w, `{"token": %q}`, unsignedJWT(time.Now().Add(time.Hour).Unix()))
+		case "/caller/terraform@example.iam.gserviceaccount.com:generateAccessToken":
+			if r.Header.Get("Authorization") != "Bearer federated-token" {
+				http.Error(w, "unexpected request", http.StatusForbidden)
+				return
+			}
+			fmt.Fprintf(w, `{"accessToken": "caller-token", "expireTime": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+		default:
+			handler(w, r)
+		}
+	}))
+
+	credentials := fmt.Sprintf(`{
+		"type": "external_account",
+		"audience": "//iam.googleapis.com/projects/123456/locations/global/workloadIdentityPools/github/providers/github",
+		"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+		"token_url": "%[1]s/v1/token",
+		"service_account_impersonation_url": "%[1]s/caller/terraform@example.iam.gserviceaccount.com:generateAccessToken",
+		"credential_source": {"file": %[2]q, "format": {"type": "json", "subject_token_field_name": "value"}}
+	}`, server.URL, tokenFile.Name())
+	return server, credentials, func() {
+		server.Close()
+		os.Remove(tokenFile.Name())
+	}
+}
+
+func TestExternalAccountToken(t *testing.T) {
+	_, credentials, cleanup := externalAccountServer(t, http.NotFound)
+	defer cleanup()
+	if !IsExternalAccount([]byte(credentials)) {
+		t.Fatal("expected external account credentials")
+	}
+
+	client := &Client{Credentials: credentials, Audience: "https://cloudvolumesgcp-api.netapp.com"}
+	if _, err := client.token(); err != nil {
+		t.Fatal(err)
+	}
+	if time.Until(client.tokens.token.Expiry) < 50*time.Minute {
+		t.Errorf("unexpected token expiring at %s", client.tokens.token.Expiry)
+	}
+}
+
+func TestParseExternalAccount(t *testing.T) {
+	for _, credentials := range []string{
+		`{"type": "external_account", "audience": "a", "subject_token_type": "t", "token_url": "u", "credential_source": {"file": "f"}}`,
+		`{"type": "external_account", "audience": "a", "subject_token_type": "t", "token_url": "u", "service_account_impersonation_url": "i", "credential_source": {"environment_id": "aws1"}}`,
+		`{"type": "external_account", "audience": "a", "subject_token_type": "t", "token_url": "u", "service_account_impersonation_url": "i"}`,
+	} {
+		if _, err := parseExternalAccount([]byte(credentials)); err == nil {
+			t.Errorf("expected an error for %s", credentials)
+		}
+	}
+}
+
+func TestExternalAccountAccessToken(t *testing.T) {
+	_, credentials, cleanup := externalAccountServer(t, http.NotFound)
+	defer cleanup()
+	token, err := ExternalAccountAccessToken(context.Background(), []byte(credentials), cloudPlatformScope)
+	if err != nil || token.AccessToken != "caller-token" {
+		t.Errorf("expected the access token of the caller, got %v, %v", token, err)
+	}
+}
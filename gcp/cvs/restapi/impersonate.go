@@ -0,0 +1,159 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jws"
+)
+
+// iamCredentialsURL is the IAM Credentials API issuing the tokens of impersonated service accounts
+var iamCredentialsURL = "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/"
+
+// impersonatedIDToken returns an identity token of the service account for the audience, requested with the
+// credentials of the caller
+func impersonatedIDToken(ctx context.Context, caller *http.Client, serviceAccount string, audience string) (*oauth2.Token, error) {
+	var response struct {
+		Token string `json:"token"`
+	}
+	body := map[string]interface{}{"audience": audience, "includeEmail": true}
+	if err := callIAMCredentials(ctx, caller, serviceAccount+":generateIdToken", body, &response); err != nil {
+		return nil, err
+	}
+	claims, err := jws.Decode(response.Token)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to decode the identity token of service account %s: %v", serviceAccount, err)
+	}
+	return &oauth2.Token{AccessToken: response.Token, TokenType: "Bearer", Expiry: time.Unix(claims.Exp, 0)}, nil
+}
+
+// impersonatedAccessToken returns an access token of the service account for the scope, requested with the
+// credentials of the caller
+func impersonatedAccessToken(ctx context.Context, caller *http.Client, serviceAccount string, scope string) (*oauth2.Token, error) {
+	var response struct {
+		AccessToken string    `json:"accessToken"`
+		ExpireTime  time.Time `json:"expireTime"`
+	}
+	body := map[string]interface{}{"scope": []string{scope}}
+	if err := callIAMCredentials(ctx, caller, serviceAccount+":generateAccessToken", body, &response); err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: response.AccessToken, TokenType: "Bearer", Expiry: response.ExpireTime}, nil
+}
+
+func callIAMCredentials(ctx context.Context, caller *http.Client, method string, body interface{}, v interface{}) error {
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", iamCredentialsURL+method, bytes.NewReader(bodyJSON))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(caller, req, v, "impersonating service account with "+method)
+}
+
+// callerClient returns an HTTP client authorized with the credentials of the client for the cloud-platform scope,
+// to impersonate a service account
+func (c *Client) callerClient(ctx context.Context) (*http.Client, error) {
+	switch {
+	case c.Token != "":
+		return oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: c.Token})), nil
+	case c.tokens.metadata:
+		return oauth2.NewClient(ctx, google.ComputeTokenSource("")), nil
+	case IsExternalAccount(c.tokens.key):
+		account, err := parseExternalAccount(c.tokens.key)
+		if err != nil {
+			return nil, err
+		}
+		token, err := account.accessToken(ctx, cloudPlatformScope)
+		if err != nil {
+			return nil, err
+		}
+		return oauth2.NewClient(ctx, oauth2.StaticTokenSource(token)), nil
+	}
+	// a service account key or the user credentials of gcloud
+	credentials, err := google.CredentialsFromJSON(ctx, c.tokens.key, cloudPlatformScope)
+	if err != nil {
+		return nil, fmt.Errorf("Error building the credentials to impersonate %s with: %v", c.ImpersonateServiceAccount, err)
+	}
+	return oauth2.NewClient(ctx, credentials.TokenSource), nil
+}
+
+// AccessToken returns an OAuth access token for the scope, e.g. for the Compute API, of the credentials of the
+// client or of the service account they impersonate. The token is reused until it is about to expire.
+func (c *Client) AccessToken(ctx context.Context, scope string) (*oauth2.Token, error) {
+	c.tokens.mutex.Lock()
+	defer c.tokens.mutex.Unlock()
+
+	if token := c.tokens.accessTokens[scope]; token != nil && time.Until(token.Expiry) > tokenRefreshMargin {
+		return token, nil
+	}
+	token, err := c.newAccessToken(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	if c.tokens.accessTokens == nil {
+		c.tokens.accessTokens = make(map[string]*oauth2.Token)
+	}
+	c.tokens.accessTokens[scope] = token
+	return token, nil
+}
+
+// TokenSource returns a token source of the access tokens of the client for the scope, see AccessToken
+func (c *Client) TokenSource(ctx context.Context, scope string) oauth2.TokenSource {
+	return &accessTokenSource{client: c, ctx: ctx, scope: scope}
+}
+
+// accessTokenSource is the token source returned by TokenSource
+type accessTokenSource struct {
+	client *Client
+	ctx    context.Context
+	scope  string
+}
+
+func (s *accessTokenSource) Token() (*oauth2.Token, error) {
+	return s.client.AccessToken(s.ctx, s.scope)
+}
+
+// newAccessToken requests an access token for the scope, with the tokens mutex held
+func (c *Client) newAccessToken(ctx context.Context, scope string) (*oauth2.Token, error) {
+	if err := c.loadCredentials(); err != nil {
+		return nil, err
+	}
+	ctx, err := c.HTTPContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.ImpersonateServiceAccount != "" {
+		caller, err := c.callerClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return impersonatedAccessToken(ctx, caller, c.ImpersonateServiceAccount, scope)
+	}
+	switch {
+	case c.Token != "":
+		return &oauth2.Token{AccessToken: c.Token, TokenType: "Bearer"}, nil
+	case c.tokens.metadata:
+		return google.ComputeTokenSource("").Token()
+	case IsExternalAccount(c.tokens.key):
+		account, err := parseExternalAccount(c.tokens.key)
+		if err != nil {
+			return nil, err
+		}
+		return account.accessToken(ctx, scope)
+	}
+	credentials, err := google.CredentialsFromJSON(ctx, c.tokens.key, scope)
+	if err != nil {
+		return nil, fmt.Errorf("Error building credentials for %s: %v", scope, err)
+	}
+	return credentials.TokenSource.Token()
+}
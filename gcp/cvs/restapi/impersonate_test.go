@@ -0,0 +1,54 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestImpersonateServiceAccount(t *testing.T) {
+	target := "netapp@example.iam.gserviceaccount.com"
+	exp := time.Now().Add(time.Hour).Unix()
+	var accessTokens int32
+	server, credentials, cleanup := externalAccountServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if r.Header.Get("Authorization") != "Bearer caller-token" || json.NewDecoder(r.Body).Decode(&body) != nil {
+			http.Error(w, "unexpected request", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/target/" + target + ":generateIdToken":
+			fmt.Fprintf(w, `{"token": %q}`, unsignedJWT(exp))
+		case "/target/" + target + ":generateAccessToken":
+			atomic.AddInt32(&accessTokens, 1)
+			fmt.Fprintf(w, `{"accessToken": "target-token", "expireTime": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer cleanup()
+	defer func(url string) { iamCredentialsURL = url }(iamCredentialsURL)
+	iamCredentialsURL = server.URL + "/target/"
+
+	client := &Client{Credentials: credentials, Audience: "https://cloudvolumesgcp-api.netapp.com", ImpersonateServiceAccount: target}
+	token, err := client.token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != unsignedJWT(exp) {
+		t.Errorf("expected the identity token of %s, got %s", target, token)
+	}
+	accessToken, err := client.AccessToken(context.Background(), "https://www.googleapis.com/auth/compute.readonly")
+	if err != nil || accessToken.AccessToken != "target-token" {
+		t.Errorf("expected the access token of %s, got %v, %v", target, accessToken, err)
+	}
+	// the access token is reused until it is about to expire
+	accessToken, err = client.TokenSource(context.Background(), "https://www.googleapis.com/auth/compute.readonly").Token()
+	if err != nil || accessToken.AccessToken != "target-token" || atomic.LoadInt32(&accessTokens) != 1 {
+		t.Errorf("expected the cached access token, got %v, %v after %d requests", accessToken, err, accessTokens)
+	}
+}
@@ -0,0 +1,39 @@
+package restapi
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// RateLimit is the API quota usage reported by the rate limit headers of a response
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	// Reset is the value of the reset header as sent, if any
+	Reset string
+}
+
+// Used returns the percentage of the limit used
+func (r RateLimit) Used() int {
+	if r.Limit <= 0 {
+		return 0
+	}
+	return (r.Limit - r.Remaining) * 100 / r.Limit
+}
+
+// ParseRateLimit returns the rate limit reported by the headers, and whether they report one. Both the
+// X-RateLimit-* headers and the RateLimit-* headers of the IETF draft are understood.
+func ParseRateLimit(header http.Header) (RateLimit, bool) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		limit, err := strconv.Atoi(header.Get(prefix + "Limit"))
+		if err != nil {
+			continue
+		}
+		remaining, err := strconv.Atoi(header.Get(prefix + "Remaining"))
+		if err != nil {
+			continue
+		}
+		return RateLimit{Limit: limit, Remaining: remaining, Reset: header.Get(prefix + "Reset")}, true
+	}
+	return RateLimit{}, false
+}
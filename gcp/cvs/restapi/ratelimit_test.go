@@ -0,0 +1,30 @@
+package restapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	header := http.Header{}
+	if _, ok := ParseRateLimit(header); ok {
+		t.Error("expected no rate limit without headers")
+	}
+	header.Set("X-RateLimit-Limit", "600")
+	header.Set("X-RateLimit-Remaining", "90")
+	header.Set("X-RateLimit-Reset", "42")
+	rateLimit, ok := ParseRateLimit(header)
+	if !ok || rateLimit.Limit != 600 || rateLimit.Remaining != 90 || rateLimit.Reset != "42" {
+		t.Errorf("unexpected rate limit %+v", rateLimit)
+	}
+	if rateLimit.Used() != 85 {
+		t.Errorf("expected 85%% used, got %d", rateLimit.Used())
+	}
+
+	header = http.Header{}
+	header.Set("RateLimit-Limit", "100")
+	header.Set("RateLimit-Remaining", "100")
+	if rateLimit, ok := ParseRateLimit(header); !ok || rateLimit.Used() != 0 {
+		t.Errorf("unexpected rate limit %+v", rateLimit)
+	}
+}
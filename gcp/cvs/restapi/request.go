@@ -2,12 +2,11 @@ package restapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
-
-	"golang.org/x/oauth2/google"
+	"net/url"
 )
 
 // Request represents a request to a REST API
@@ -16,9 +15,9 @@ type Request struct {
 	Params interface{} `json:"params"`
 }
 
-// BuildHTTPReq builds an HTTP request to carry out the REST request
-func (r *Request) BuildHTTPReq(host string, serviceAccount string, credentials string, audience string, baseURL string) (*http.Request, error) {
-	var keyBytes []byte
+// BuildHTTPReq builds an HTTP request to carry out the REST request, authorized with the JWT and canceled with the
+// context
+func (r *Request) BuildHTTPReq(ctx context.Context, host string, jwt string, baseURL string) (*http.Request, error) {
 	var err error
 	var req *http.Request
 	url := host + baseURL
@@ -27,36 +26,44 @@ func (r *Request) BuildHTTPReq(host string, serviceAccount string, credentials s
 		if err != nil {
 			return nil, err
 		}
-		req, err = http.NewRequest(r.Method, url, bytes.NewReader(bodyJSON))
+		req, err = http.NewRequestWithContext(ctx, r.Method, url, bytes.NewReader(bodyJSON))
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		req, err = http.NewRequest(r.Method, url, nil)
+		req, err = http.NewRequestWithContext(ctx, r.Method, url, nil)
 		if err != nil {
 			return nil, err
 		}
-	}
-	if credentials != "" {
-		keyBytes = []byte(credentials)
-	} else {
-		keyBytes, err = ioutil.ReadFile(serviceAccount)
-		if err != nil {
-			return nil, fmt.Errorf("Unable to read service account key file  %v", err)
-		}
-	}
-
-	tokenSource, err := google.JWTAccessTokenSourceFromJSON(keyBytes, audience)
-	if err != nil {
-		return nil, fmt.Errorf("Error building JWT access token source: %v", err)
-	}
-	jwt, err := tokenSource.Token()
-	if err != nil {
-		return nil, fmt.Errorf("Unable to generate JWT token: %v", err)
+		req.URL.RawQuery = encodeQuery(r.Params)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+jwt.AccessToken)
+	req.Header.Set("Authorization", "Bearer "+jwt)
 
 	return req, nil
 }
+
+// encodeQuery converts the scalar and string list params of a GET request into URL query parameters
+func encodeQuery(params interface{}) string {
+	values := url.Values{}
+	paramsMap, ok := params.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	for key, value := range paramsMap {
+		switch v := value.(type) {
+		case string:
+			if v != "" {
+				values.Add(key, v)
+			}
+		case []string:
+			for _, item := range v {
+				values.Add(key, item)
+			}
+		case int, int64, bool:
+			values.Add(key, fmt.Sprint(v))
+		}
+	}
+	return values.Encode()
+}
@@ -1,16 +1,89 @@
 package restapi
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"unicode"
 )
 
-// ResponseError represents an Error to a REST API call
+// ResponseError is an error response of the API
 type ResponseError struct {
-	Code    int    `json:"code"`
+	// StatusCode is the HTTP status code of the response
+	StatusCode int `json:"-"`
+	// Code is the code of the error in the response, usually the HTTP status code
+	Code int `json:"code"`
+	// Name is the error code of the service, e.g. xUnknown for resources that don't exist
+	Name    string `json:"name,omitempty"`
 	Message string `json:"message"`
-	Name    string `json:"name"`
+	// Operation is the operation of the provider that made the call
+	Operation string `json:"-"`
+	// RequestID identifies the request in support cases, if the response reports it
+	RequestID string `json:"requestId,omitempty"`
+	// undecoded is set if the response isn't an error of the API, Message is then the start of the body
+	undecoded bool
+}
+
+// ParseResponseError returns the error of an API response with a status code outside of 2xx, for the operation
+// that made the call
+func ParseResponseError(statusCode int, body []byte, operation string) *ResponseError {
+	e := &ResponseError{}
+	if err := json.Unmarshal(body, e); err != nil || (e.Code == 0 && e.Message == "") {
+		e = &ResponseError{Code: statusCode, Message: BodySnippet(bytes.TrimSpace(body)), undecoded: true}
+	}
+	e.StatusCode = statusCode
+	e.Operation = operation
+	return e
 }
 
 func (e *ResponseError) Error() string {
-	return fmt.Sprintf("Request returned an error. %+v", *e)
+	message := fmt.Sprintf("code: %d, message: %s", e.Code, e.Message)
+	if e.undecoded {
+		message = fmt.Sprintf("code: %d, response: %s", e.Code, e.Message)
+	}
+	if e.RequestID != "" {
+		message += ", request ID: " + e.RequestID
+	}
+	return message
+}
+
+// maxBodySnippet is the number of response body bytes included in error messages
+const maxBodySnippet = 512
+
+// UnexpectedResponseError is returned when the API answers with something other than JSON,
+// typically an HTML error page from a proxy or load balancer.
+type UnexpectedResponseError struct {
+	Method      string
+	URL         string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+func (e *UnexpectedResponseError) Error() string {
+	return fmt.Sprintf("unexpected response from %s %s, code: %d, content type: %s, body: %s",
+		e.Method, e.URL, e.StatusCode, e.ContentType, BodySnippet(e.Body))
+}
+
+// BodySnippet returns the start of a response body for use in error messages. Control characters
+// are replaced and the body is truncated to maxBodySnippet bytes.
+func BodySnippet(body []byte) string {
+	truncated := len(body) > maxBodySnippet
+	if truncated {
+		body = body[:maxBodySnippet]
+	}
+	snippet := strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == '\t' {
+			return ' '
+		}
+		if !unicode.IsPrint(r) {
+			return '?'
+		}
+		return r
+	}, string(body))
+	if truncated {
+		snippet += "..."
+	}
+	return snippet
 }
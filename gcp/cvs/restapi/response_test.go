@@ -0,0 +1,36 @@
+package restapi
+
+import "testing"
+
+func TestParseResponseError(t *testing.T) {
+	for _, c := range []struct {
+		statusCode int
+		body       string
+		expected   ResponseError
+		message    string
+	}{
+		{
+			404, `{"code": 404, "message": "Error describing volume - Volume not found", "name": "xUnknown"}`,
+			ResponseError{StatusCode: 404, Code: 404, Name: "xUnknown", Message: "Error describing volume - Volume not found", Operation: "getVolumeByID"},
+			"code: 404, message: Error describing volume - Volume not found",
+		},
+		{
+			500, `{"code": 500, "message": "Cannot spawn additional jobs", "requestId": "f00d"}`,
+			ResponseError{StatusCode: 500, Code: 500, Message: "Cannot spawn additional jobs", Operation: "getVolumeByID", RequestID: "f00d"},
+			"code: 500, message: Cannot spawn additional jobs, request ID: f00d",
+		},
+		{
+			502, "<html>\n<body>502 Bad Gateway</body>\n</html>",
+			ResponseError{StatusCode: 502, Code: 502, Message: "<html> <body>502 Bad Gateway</body> </html>", Operation: "getVolumeByID", undecoded: true},
+			"code: 502, response: <html> <body>502 Bad Gateway</body> </html>",
+		},
+	} {
+		err := ParseResponseError(c.statusCode, []byte(c.body), "getVolumeByID")
+		if *err != c.expected {
+			t.Errorf("%s: expected %+v, got %+v", c.body, c.expected, *err)
+		}
+		if err.Error() != c.message {
+			t.Errorf("%s: expected message %q, got %q", c.body, c.message, err.Error())
+		}
+	}
+}
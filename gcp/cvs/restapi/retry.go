@@ -0,0 +1,117 @@
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// RetryRule describes a transient error of the API and how calls failing with it are retried
+type RetryRule struct {
+	// Name describes the error in logs
+	Name string
+	// StatusCode is the HTTP status code of the error
+	StatusCode int
+	// Methods are the HTTP methods retried, all methods if empty
+	Methods []string
+	// Matches classifies the error of the response. All responses with the status code match if it is nil.
+	Matches func(err *ResponseError) bool
+	// Retries is the number of retries of a call without a deadline
+	Retries int
+}
+
+// RetryPolicy retries API calls failing with the transient errors of its rules, waiting for the backoff between
+// the attempts. The first matching rule applies.
+type RetryPolicy struct {
+	Rules   []RetryRule
+	Backoff Backoff
+	// OnRetry is called with the name of the rule before every retry, if set
+	OnRetry func(rule string)
+}
+
+// Call makes an API call, returning the HTTP status code and the response body
+type Call func() (int, []byte, error)
+
+// RetriesExhaustedError is returned when an API call still fails after retrying it for a transient error
+type RetriesExhaustedError struct {
+	// Operation is the retried call
+	Operation string
+	// Attempts counts the first call
+	Attempts int
+	Elapsed  time.Duration
+	// Message is the error message of the last attempt
+	Message string
+}
+
+func (e *RetriesExhaustedError) Error() string {
+	return fmt.Sprintf("%s gave up after %d attempts over %s: %s", e.Operation, e.Attempts, e.Elapsed.Round(time.Second), e.Message)
+}
+
+// Do makes the call, retrying it while it fails with the error of a rule. Calls with a deadline are retried until
+// the deadline, others the number of retries of the rule within the MaxElapsed of the backoff. Canceling the context
+// stops the retries.
+func (p *RetryPolicy) Do(ctx context.Context, method string, operation string, deadline time.Time, call Call) (int, []byte, error) {
+	start := time.Now()
+	for attempts := 1; ; attempts++ {
+		statusCode, body, err := call()
+		if err != nil {
+			return statusCode, body, err
+		}
+		rule := p.match(method, statusCode, body, operation)
+		if rule == nil {
+			return statusCode, body, nil
+		}
+		if !canRetry(deadline, attempts, rule.Retries) || (deadline.IsZero() && p.Backoff.exceeded(start)) {
+			message := ParseResponseError(statusCode, body, operation).Message
+			return statusCode, body, &RetriesExhaustedError{Operation: operation, Attempts: attempts, Elapsed: time.Since(start), Message: message}
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(rule.Name)
+		}
+		delay := p.Backoff.Delay(attempts)
+		log.Printf("[DEBUG] %s failed with %s (attempt %d), retrying in %s", operation, rule.Name, attempts, delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return statusCode, body, fmt.Errorf("%s canceled while retrying %s: %s", operation, rule.Name, ctx.Err())
+		}
+	}
+}
+
+// match returns the rule of the error of a response, or nil if the response isn't retried
+func (p *RetryPolicy) match(method string, statusCode int, body []byte, operation string) *RetryRule {
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.StatusCode != statusCode || !rule.appliesTo(method) {
+			continue
+		}
+		if rule.Matches == nil || rule.Matches(ParseResponseError(statusCode, body, operation)) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func (r *RetryRule) appliesTo(method string) bool {
+	if len(r.Methods) == 0 {
+		return true
+	}
+	for _, m := range r.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// canRetry reports whether a call failing with a transient error is retried: until the deadline of the call if
+// it has one, otherwise while the attempts made don't exceed the retries
+func canRetry(deadline time.Time, attempts int, retries int) bool {
+	if !deadline.IsZero() {
+		return time.Now().Before(deadline)
+	}
+	return attempts <= retries
+}
@@ -0,0 +1,122 @@
+package restapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetriesExhaustedError(t *testing.T) {
+	err := &RetriesExhaustedError{Operation: "createVolume", Attempts: 11, Elapsed: 452*time.Second + 300*time.Millisecond, Message: "Cannot spawn additional jobs"}
+	if err.Error() != "createVolume gave up after 11 attempts over 7m32s: Cannot spawn additional jobs" {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestCanRetry(t *testing.T) {
+	if !canRetry(time.Time{}, 10, 10) || canRetry(time.Time{}, 11, 10) {
+		t.Error("expected 10 retries without a deadline")
+	}
+	if !canRetry(time.Now().Add(time.Minute), 100, 10) {
+		t.Error("expected a retry before the deadline")
+	}
+	if canRetry(time.Now().Add(-time.Second), 1, 10) {
+		t.Error("expected no retry after the deadline")
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	policy := RetryPolicy{Rules: []RetryRule{
+		{
+			Name:       "the job limit",
+			StatusCode: 500,
+			Matches: func(err *ResponseError) bool {
+				return strings.Contains(err.Message, "Cannot spawn additional jobs")
+			},
+			Retries: 3,
+		},
+		{Name: "a timeout", StatusCode: 504, Methods: []string{"POST"}, Retries: 1},
+	}}
+	var retried []string
+	policy.OnRetry = func(rule string) { retried = append(retried, rule) }
+	jobLimit := []byte(`{"code": 500, "message": "Error creating volume - Cannot spawn additional jobs"}`)
+
+	// retried until the call succeeds
+	calls := 0
+	statusCode, _, err := policy.Do(context.Background(), "POST", "createVolume", time.Time{}, func() (int, []byte, error) {
+		calls++
+		if calls < 3 {
+			return 500, jobLimit, nil
+		}
+		return 202, []byte(`{}`), nil
+	})
+	if err != nil || statusCode != 202 || calls != 3 {
+		t.Errorf("expected success on the third call, got %d, %v after %d calls", statusCode, err, calls)
+	}
+	if len(retried) != 2 || retried[0] != "the job limit" {
+		t.Errorf("expected 2 retries for the job limit, got %v", retried)
+	}
+
+	// other errors and methods are returned as is
+	for _, c := range []struct {
+		method     string
+		statusCode int
+		body       string
+	}{
+		{"POST", 500, `{"code": 500, "message": "internal error"}`},
+		{"GET", 504, `gateway timeout`},
+	} {
+		calls = 0
+		statusCode, body, err := policy.Do(context.Background(), c.method, "call", time.Time{}, func() (int, []byte, error) {
+			calls++
+			return c.statusCode, []byte(c.body), nil
+		})
+		if err != nil || statusCode != c.statusCode || string(body) != c.body || calls != 1 {
+			t.Errorf("%s %d: expected a single call, got %d, %v after %d calls", c.method, c.statusCode, statusCode, err, calls)
+		}
+	}
+
+	// retries exhausted
+	calls = 0
+	_, _, err = policy.Do(context.Background(), "DELETE", "deleteVolume", time.Time{}, func() (int, []byte, error) {
+		calls++
+		return 500, jobLimit, nil
+	})
+	exhausted, ok := err.(*RetriesExhaustedError)
+	if !ok || calls != 4 || exhausted.Attempts != 4 || exhausted.Message != "Error creating volume - Cannot spawn additional jobs" {
+		t.Errorf("expected the retries to be exhausted after 4 calls, got %v after %d calls", err, calls)
+	}
+}
+
+func TestRetryPolicyDoCanceled(t *testing.T) {
+	policy := RetryPolicy{
+		Rules:   []RetryRule{{Name: "a timeout", StatusCode: 504, Retries: 5}},
+		Backoff: Backoff{Base: time.Hour, Cap: time.Hour},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	_, _, err := policy.Do(ctx, "GET", "getVolume", time.Time{}, func() (int, []byte, error) {
+		calls++
+		return 504, nil, nil
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected the retry to be canceled, got %v after %d calls", err, calls)
+	}
+}
+
+func TestRetryPolicyDoMaxElapsed(t *testing.T) {
+	policy := RetryPolicy{
+		Rules:   []RetryRule{{Name: "a timeout", StatusCode: 504, Retries: 100}},
+		Backoff: Backoff{Base: time.Millisecond, Cap: time.Millisecond, MaxElapsed: 20 * time.Millisecond},
+	}
+	calls := 0
+	_, _, err := policy.Do(context.Background(), "GET", "getVolume", time.Time{}, func() (int, []byte, error) {
+		calls++
+		return 504, nil, nil
+	})
+	if _, ok := err.(*RetriesExhaustedError); !ok || calls >= 100 {
+		t.Errorf("expected the retries to stop after 20ms, got %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,164 @@
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jws"
+)
+
+// tokenRefreshMargin is how long before its expiry a token is replaced, so it doesn't expire while a request is in
+// flight
+const tokenRefreshMargin = 5 * time.Minute
+
+// tokenCache holds the service account key and the last token minted from it
+type tokenCache struct {
+	mutex sync.Mutex
+	key   []byte
+	// metadata is set when the Application Default Credentials are those of the metadata server, e.g. on GCE or GKE,
+	// which signs the tokens as it holds the key
+	metadata bool
+	token    *oauth2.Token
+	// accessTokens are the last OAuth access tokens, by scope
+	accessTokens map[string]*oauth2.Token
+}
+
+// token returns the JWT authorizing requests, of the service account of the key or of ImpersonateServiceAccount.
+// The service account key is read on first use, and the token is reused until it is about to expire.
+func (c *Client) token() (string, error) {
+	c.tokens.mutex.Lock()
+	defer c.tokens.mutex.Unlock()
+
+	if c.tokens.token != nil && time.Until(c.tokens.token.Expiry) > tokenRefreshMargin {
+		return c.tokens.token.AccessToken, nil
+	}
+	if c.Token != "" && c.ImpersonateServiceAccount == "" {
+		return staticToken(c.Token)
+	}
+	if err := c.loadCredentials(); err != nil {
+		return "", err
+	}
+	ctx, err := c.HTTPContext(context.Background())
+	if err != nil {
+		return "", err
+	}
+	var token *oauth2.Token
+	switch {
+	case c.ImpersonateServiceAccount != "":
+		var caller *http.Client
+		caller, err = c.callerClient(ctx)
+		if err == nil {
+			token, err = impersonatedIDToken(ctx, caller, c.ImpersonateServiceAccount, c.Audience)
+		}
+	case c.tokens.metadata:
+		token, err = metadataToken(c.Audience)
+	case IsExternalAccount(c.tokens.key):
+		token, err = externalAccountToken(ctx, c.tokens.key, c.Audience)
+	default:
+		token, err = jwtToken(c.tokens.key, c.Audience)
+	}
+	if err != nil {
+		return "", err
+	}
+	c.tokens.token = token
+	return token.AccessToken, nil
+}
+
+// loadCredentials loads the credentials of the client on first use. A Token replaces them.
+func (c *Client) loadCredentials() error {
+	if c.Token != "" || c.tokens.key != nil || c.tokens.metadata {
+		return nil
+	}
+	return c.loadKey()
+}
+
+// staticToken returns the Token of the client. The expiry of a JWT is checked, so an expired token fails with a
+// clear error rather than with the error of the API.
+func staticToken(token string) (string, error) {
+	if claims, err := jws.Decode(token); err == nil && claims.Exp != 0 {
+		if expiry := time.Unix(claims.Exp, 0); time.Now().After(expiry) {
+			return "", fmt.Errorf("the token of the provider expired at %s. Pass a new token to the provider", expiry.Format(time.RFC3339))
+		}
+	}
+	return token, nil
+}
+
+// loadKey reads the service account key, or external account credentials, from Credentials or the ServiceAccount
+// file. Without either, the Application Default Credentials are used: the file of GOOGLE_APPLICATION_CREDENTIALS,
+// the key file of gcloud, or else the service account of the metadata server.
+func (c *Client) loadKey() error {
+	switch {
+	case c.Credentials != "":
+		c.tokens.key = []byte(c.Credentials)
+	case c.ServiceAccount != "":
+		key, err := ioutil.ReadFile(c.ServiceAccount)
+		if err != nil {
+			return fmt.Errorf("Unable to read service account key file  %v", err)
+		}
+		c.tokens.key = key
+	case os.Getenv("GOOGLE_APPLICATION_CREDENTIALS") != "":
+		// read here, as the google package doesn't know external account credentials
+		key, err := ioutil.ReadFile(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
+		if err != nil {
+			return fmt.Errorf("Unable to read the GOOGLE_APPLICATION_CREDENTIALS file  %v", err)
+		}
+		c.tokens.key = key
+	default:
+		credentials, err := google.FindDefaultCredentials(context.Background())
+		if err != nil {
+			return fmt.Errorf("Neither service_account nor credentials is set, and no Application Default Credentials were found: %v", err)
+		}
+		if len(credentials.JSON) == 0 {
+			c.tokens.metadata = true
+		} else {
+			c.tokens.key = credentials.JSON
+		}
+	}
+	return nil
+}
+
+// jwtToken signs a JWT for the audience with the service account key
+func jwtToken(key []byte, audience string) (*oauth2.Token, error) {
+	// a new token source, as the token source of the google package reuses its token until it has expired
+	tokenSource, err := google.JWTAccessTokenSourceFromJSON(key, audience)
+	if err != nil {
+		return nil, fmt.Errorf("Error building JWT access token source, the credentials must be a service account key: %v", err)
+	}
+	token, err := tokenSource.Token()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to generate JWT token: %v", err)
+	}
+	return token, nil
+}
+
+// externalAccountToken returns an identity token for the audience of the service account impersonated by external
+// account credentials
+func externalAccountToken(ctx context.Context, key []byte, audience string) (*oauth2.Token, error) {
+	account, err := parseExternalAccount(key)
+	if err != nil {
+		return nil, err
+	}
+	return account.idToken(ctx, audience)
+}
+
+// metadataToken requests an identity token for the audience from the metadata server
+func metadataToken(audience string) (*oauth2.Token, error) {
+	jwt, err := metadata.Get("instance/service-accounts/default/identity?audience=" + url.QueryEscape(audience) + "&format=full")
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get an identity token from the metadata server: %v", err)
+	}
+	claims, err := jws.Decode(jwt)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to decode the identity token of the metadata server: %v", err)
+	}
+	return &oauth2.Token{AccessToken: jwt, TokenType: "Bearer", Expiry: time.Unix(claims.Exp, 0)}, nil
+}
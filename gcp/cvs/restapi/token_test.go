@@ -0,0 +1,121 @@
+package restapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serviceAccountKey returns the JSON key of a service account with a new private key
+func serviceAccountKey(t *testing.T) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "terraform@example.iam.gserviceaccount.com",
+		"private_key_id": "1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return credentials
+}
+
+func TestClientToken(t *testing.T) {
+	client := &Client{Credentials: string(serviceAccountKey(t)), Audience: "https://cloudvolumesgcp-api.netapp.com"}
+
+	first, err := client.token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.token()
+	if err != nil || second != first {
+		t.Errorf("expected the token to be reused, got %v", err)
+	}
+
+	// a token about to expire is replaced
+	client.tokens.token.Expiry = time.Now().Add(time.Minute)
+	if _, err := client.token(); err != nil {
+		t.Fatal(err)
+	}
+	if time.Until(client.tokens.token.Expiry) < 50*time.Minute {
+		t.Errorf("expected a new token, expiring at %s", client.tokens.token.Expiry)
+	}
+}
+
+func TestClientTokenMissingKey(t *testing.T) {
+	client := &Client{ServiceAccount: "/nonexistent/key.json"}
+	if _, err := client.token(); err == nil {
+		t.Error("expected an error for a missing key file")
+	}
+	if client.tokens.key != nil {
+		t.Error("expected the missing key not to be cached")
+	}
+}
+
+func TestClientTokenApplicationDefaultCredentials(t *testing.T) {
+	file, err := ioutil.TempFile("", "adc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(serviceAccountKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", file.Name())
+	defer os.Unsetenv("GOOGLE_APPLICATION_CREDENTIALS")
+
+	client := &Client{Audience: "https://cloudvolumesgcp-api.netapp.com"}
+	if _, err := client.token(); err != nil {
+		t.Fatalf("expected a token from the Application Default Credentials, got %s", err)
+	}
+	if client.tokens.key == nil || client.tokens.metadata {
+		t.Error("expected the key of GOOGLE_APPLICATION_CREDENTIALS to be used")
+	}
+}
+
+func TestClientStaticToken(t *testing.T) {
+	valid := unsignedJWT(time.Now().Add(time.Hour).Unix())
+	client := &Client{Token: valid, ServiceAccount: "/nonexistent/key.json"}
+	if token, err := client.token(); err != nil || token != valid {
+		t.Errorf("expected the token of the client, got %s, %v", token, err)
+	}
+	client.Token = "opaque-access-token"
+	if token, err := client.token(); err != nil || token != "opaque-access-token" {
+		t.Errorf("expected the token of the client, got %s, %v", token, err)
+	}
+	client.Token = unsignedJWT(time.Now().Add(-time.Minute).Unix())
+	if _, err := client.token(); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("expected an expired token error, got %v", err)
+	}
+}
+
+func TestClientStaticTokenRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"code": 401, "message": "token expired"}`)
+	}))
+	defer server.Close()
+
+	client := &Client{Host: server.URL + "/", Token: "opaque-access-token"}
+	statusCode, _, err := client.Do(context.Background(), "us-east4/Volumes", &Request{Method: "GET"})
+	if statusCode != 401 || err == nil || !strings.Contains(err.Error(), "Pass a new token") {
+		t.Errorf("expected a rejected token error, got %d, %v", statusCode, err)
+	}
+}
@@ -0,0 +1,72 @@
+package restapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// newTransport returns the transport of the requests of a client. Like the default transport, it goes through the
+// proxy of the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables. The certificates of the CA bundle file
+// are trusted in addition to the system ones, e.g. for a proxy inspecting TLS, and insecure skips the verification
+// of certificates altogether.
+func newTransport(caBundle string, insecure bool) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if caBundle == "" && !insecure {
+		return transport, nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure}
+	if caBundle != "" {
+		pem, err := ioutil.ReadFile(caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read the CA bundle file %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA bundle file %s has no PEM encoded certificate", caBundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// client returns the HTTP client of the requests, built on first use
+func (c *Client) client() (*http.Client, error) {
+	c.transportOnce.Do(func() {
+		var transport http.RoundTripper
+		transport, c.transportErr = newTransport(c.CABundle, c.InsecureSkipVerify)
+		if c.LogHTTP {
+			transport = &loggingTransport{next: transport}
+		}
+		c.httpClient = &http.Client{Transport: transport}
+	})
+	return c.httpClient, c.transportErr
+}
+
+// HTTPContext returns the context with the HTTP client of the client, used by the oauth2 package, so requests for
+// tokens and other Google APIs go through the same proxy and trust the same certificates as the API requests
+func (c *Client) HTTPContext(ctx context.Context) (context.Context, error) {
+	httpClient, err := c.client()
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, oauth2.HTTPClient, httpClient), nil
+}
+
+// contextClient returns the HTTP client of the context set by HTTPContext, or the default client
+func contextClient(ctx context.Context) *http.Client {
+	if httpClient, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok {
+		return httpClient
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,82 @@
+package restapi
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClientTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "transport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caBundle := filepath.Join(dir, "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caBundle, certificate, 0600); err != nil {
+		t.Fatal(err)
+	}
+	invalidBundle := filepath.Join(dir, "invalid.pem")
+	if err := ioutil.WriteFile(invalidBundle, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name     string
+		client   *Client
+		expected string
+	}{
+		{"system certificates", &Client{}, "certificate"},
+		{"ca bundle", &Client{CABundle: caBundle}, ""},
+		{"insecure", &Client{InsecureSkipVerify: true}, ""},
+		{"missing ca bundle", &Client{CABundle: filepath.Join(dir, "missing.pem")}, "Unable to read the CA bundle file"},
+		{"invalid ca bundle", &Client{CABundle: invalidBundle}, "has no PEM encoded certificate"},
+	} {
+		c.client.Host = server.URL + "/"
+		c.client.Token = "opaque-access-token"
+		statusCode, _, err := c.client.Do(context.Background(), "us-east4/Volumes", &Request{Method: "GET"})
+		if c.expected == "" {
+			if err != nil || statusCode != 200 {
+				t.Errorf("%s: expected success, got %d, %v", c.name, statusCode, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", c.name, c.expected, err)
+		}
+	}
+}
+
+func TestNewTransportProxy(t *testing.T) {
+	transport, err := newTransport("", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if transport.Proxy == nil {
+		t.Error("expected the transport to use the proxy of the environment")
+	}
+}
+
+func TestHTTPContext(t *testing.T) {
+	client := &Client{InsecureSkipVerify: true}
+	ctx, err := client.HTTPContext(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	httpClient, _ := client.client()
+	if contextClient(ctx) != httpClient || contextClient(context.Background()) != http.DefaultClient {
+		t.Error("expected the HTTP client of the client in the context")
+	}
+}
@@ -6,42 +6,59 @@ import (
 	"github.com/hashicorp/terraform/helper/schema"
 )
 
+// adArgumentDeprecated deprecates the arguments of the data source that it used to accept besides the region. They
+// are kept as arguments so existing configurations still work, but the attributes are read from the connection.
+const adArgumentDeprecated = "The argument is ignored, the attribute is read from the active directory connection of the region. Remove it."
+
 func dataSourceGCPActiveDirectory() *schema.Resource {
 	return &schema.Resource{
 		Read: dataSourceGCPActiveDirectoryRead,
 		Schema: map[string]*schema.Schema{
-			"username": {
+			"region": {
 				Type:     schema.TypeString,
-				Optional: true,
+				Required: true,
+			},
+			"username": {
+				Type:       schema.TypeString,
+				Optional:   true,
+				Computed:   true,
+				Deprecated: adArgumentDeprecated,
 			},
 			"password": {
-				Type:      schema.TypeString,
-				Optional:  true,
-				Sensitive: true,
+				Type:       schema.TypeString,
+				Optional:   true,
+				Sensitive:  true,
+				Deprecated: "The password of the connection is not read, and the argument is ignored. Remove it.",
 			},
 			"domain": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:       schema.TypeString,
+				Optional:   true,
+				Computed:   true,
+				Deprecated: adArgumentDeprecated,
 			},
 			"dns_server": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:       schema.TypeString,
+				Optional:   true,
+				Computed:   true,
+				Deprecated: adArgumentDeprecated,
 			},
 			"netbios": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:       schema.TypeString,
+				Optional:   true,
+				Computed:   true,
+				Deprecated: adArgumentDeprecated,
 			},
 			"organizational_unit": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:       schema.TypeString,
+				Optional:   true,
+				Computed:   true,
+				Deprecated: adArgumentDeprecated,
 			},
 			"site": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"region": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:       schema.TypeString,
+				Optional:   true,
+				Computed:   true,
+				Deprecated: adArgumentDeprecated,
 			},
 			"uuid": {
 				Type:     schema.TypeString,
@@ -60,6 +77,9 @@ func dataSourceGCPActiveDirectoryRead(d *schema.ResourceData, meta interface{})
 	if err != nil {
 		return err
 	}
+	if res.UUID == "" {
+		return fmt.Errorf("no active directory connection found in region %s. CIFS volumes in the region need one", activeDirectory.Region)
+	}
 	d.SetId(res.UUID)
 
 	if err := d.Set("uuid", res.UUID); err != nil {
@@ -0,0 +1,185 @@
+package gcp
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/filestore"
+)
+
+func dataSourceGCPFilestoreConversion() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceGCPFilestoreConversionRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"location": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"tier": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"capacity_gb": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"file_share_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"network": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"nfs_export_options": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ip_ranges": {
+							Type:     schema.TypeList,
+							Required: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"access_mode": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"squash_mode": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"volume_region": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"service_level": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"size": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"volume_path": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"protocol_types": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"export_rules": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"access": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"allowed_clients": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"has_root_access": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"notes": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"hcl": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceGCPFilestoreConversionRead(d *schema.ResourceData, meta interface{}) error {
+	instance := filestore.Instance{
+		Name:          d.Get("name").(string),
+		Location:      d.Get("location").(string),
+		Tier:          d.Get("tier").(string),
+		CapacityGB:    d.Get("capacity_gb").(int),
+		FileShareName: d.Get("file_share_name").(string),
+		Network:       d.Get("network").(string),
+	}
+	for _, v := range d.Get("nfs_export_options").([]interface{}) {
+		option := v.(map[string]interface{})
+		exportOption := filestore.ExportOption{
+			AccessMode: option["access_mode"].(string),
+			SquashMode: option["squash_mode"].(string),
+		}
+		for _, ipRange := range option["ip_ranges"].([]interface{}) {
+			exportOption.IPRanges = append(exportOption.IPRanges, ipRange.(string))
+		}
+		instance.ExportOptions = append(instance.ExportOptions, exportOption)
+	}
+
+	volume, err := filestore.Convert(instance)
+	if err != nil {
+		return fmt.Errorf("Error converting Filestore instance %s: %s", instance.Name, err)
+	}
+
+	exportRules := make([]map[string]interface{}, 0, len(volume.ExportRules))
+	for _, rule := range volume.ExportRules {
+		exportRules = append(exportRules, map[string]interface{}{
+			"access":          rule.Access,
+			"allowed_clients": rule.AllowedClients,
+			"has_root_access": rule.HasRootAccess,
+		})
+	}
+	notes := volume.Notes
+	if notes == nil {
+		notes = []string{}
+	}
+	if err := d.Set("volume_region", volume.Region); err != nil {
+		return fmt.Errorf("Error reading Filestore conversion volume_region: %s", err)
+	}
+	if err := d.Set("service_level", volume.ServiceLevel); err != nil {
+		return fmt.Errorf("Error reading Filestore conversion service_level: %s", err)
+	}
+	if err := d.Set("size", volume.Size); err != nil {
+		return fmt.Errorf("Error reading Filestore conversion size: %s", err)
+	}
+	if err := d.Set("volume_path", volume.VolumePath); err != nil {
+		return fmt.Errorf("Error reading Filestore conversion volume_path: %s", err)
+	}
+	if err := d.Set("protocol_types", volume.ProtocolTypes); err != nil {
+		return fmt.Errorf("Error reading Filestore conversion protocol_types: %s", err)
+	}
+	if err := d.Set("export_rules", exportRules); err != nil {
+		return fmt.Errorf("Error reading Filestore conversion export_rules: %s", err)
+	}
+	if err := d.Set("notes", notes); err != nil {
+		return fmt.Errorf("Error reading Filestore conversion notes: %s", err)
+	}
+	if err := d.Set("hcl", volume.HCL()); err != nil {
+		return fmt.Errorf("Error reading Filestore conversion hcl: %s", err)
+	}
+	d.SetId(instance.Location + "/" + instance.Name)
+	return nil
+}
@@ -0,0 +1,128 @@
+package gcp
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceGCPSnapshotMount() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceGCPSnapshotMountRead,
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"volume_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"snapshot_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"snapshot_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"snapshot_directory": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"mounts": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"protocol_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"server": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"source": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"mount_options": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceGCPSnapshotMountRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	region := d.Get("region").(string)
+	volumeID := d.Get("volume_id").(string)
+	snapshotID := d.Get("snapshot_id").(string)
+
+	volume, err := client.getVolumeByID(volumeRequest{Region: region, VolumeID: volumeID})
+	if err != nil {
+		return err
+	}
+	snapshot, err := client.getSnapshotByID(listSnapshotRequest{Region: region, VolumeID: volumeID, SnapshotID: snapshotID})
+	if err != nil {
+		return err
+	}
+	if snapshot.SnapshotID != snapshotID {
+		return fmt.Errorf("snapshot with id: %s not found on volume %s", snapshotID, volumeID)
+	}
+	if !volume.SnapshotDirectory {
+		log.Printf("[WARN] The snapshot directory of volume %s is hidden. Set snapshot_directory of the volume to browse snapshots from clients", volume.Name)
+	}
+	d.SetId(snapshotID)
+
+	if err := d.Set("snapshot_name", snapshot.Name); err != nil {
+		return fmt.Errorf("Error reading snapshot_name: %s", err)
+	}
+	if err := d.Set("snapshot_directory", volume.SnapshotDirectory); err != nil {
+		return fmt.Errorf("Error reading snapshot_directory: %s", err)
+	}
+	if err := d.Set("mounts", snapshotMounts(volume.MountPoints, snapshot.Name)); err != nil {
+		return fmt.Errorf("Error reading mounts: %s", err)
+	}
+	return nil
+}
+
+// snapshotMounts returns where clients find the snapshot for every mount point of the volume: the .snapshot
+// directory of NFS exports, which is mounted read-only, or the ~snapshot directory of SMB shares
+func snapshotMounts(mounts []mountPoints, snapshotName string) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(mounts))
+	for _, mount := range mounts {
+		entry := map[string]interface{}{
+			"protocol_type": mount.ProtocolType,
+			"server":        mount.Server,
+		}
+		switch apiProtocolType(mount.ProtocolType) {
+		case "CIFS":
+			path := strings.TrimRight(mount.Export, `\`) + `\~snapshot\` + snapshotName
+			entry["path"] = path
+			entry["source"] = path
+			entry["mount_options"] = "ro"
+		case "NFSv4":
+			entry["path"] = strings.TrimRight(mount.Export, "/") + "/.snapshot/" + snapshotName
+			entry["source"] = fmt.Sprintf("%s:%s", mount.Server, entry["path"])
+			entry["mount_options"] = "ro,vers=4.1"
+		default:
+			entry["path"] = strings.TrimRight(mount.Export, "/") + "/.snapshot/" + snapshotName
+			entry["source"] = fmt.Sprintf("%s:%s", mount.Server, entry["path"])
+			entry["mount_options"] = "ro,vers=3"
+		}
+		result = append(result, entry)
+	}
+	return result
+}
@@ -0,0 +1,26 @@
+package gcp
+
+import "testing"
+
+func TestSnapshotMounts(t *testing.T) {
+	mounts := snapshotMounts([]mountPoints{
+		{Export: "/data", Server: "10.0.0.2", ProtocolType: "NFSv3"},
+		{Export: "/data", Server: "10.0.0.2", ProtocolType: "NFSv4"},
+		{Export: `\\cvs-smb.example.com\data`, Server: "10.0.0.2", ProtocolType: "CIFS"},
+	}, "daily-2020-06-01")
+	expected := []map[string]string{
+		{"path": "/data/.snapshot/daily-2020-06-01", "source": "10.0.0.2:/data/.snapshot/daily-2020-06-01", "mount_options": "ro,vers=3"},
+		{"path": "/data/.snapshot/daily-2020-06-01", "source": "10.0.0.2:/data/.snapshot/daily-2020-06-01", "mount_options": "ro,vers=4.1"},
+		{"path": `\\cvs-smb.example.com\data\~snapshot\daily-2020-06-01`, "source": `\\cvs-smb.example.com\data\~snapshot\daily-2020-06-01`, "mount_options": "ro"},
+	}
+	if len(mounts) != len(expected) {
+		t.Fatalf("expected %d mounts, got %d", len(expected), len(mounts))
+	}
+	for i, mount := range mounts {
+		for key, value := range expected[i] {
+			if mount[key] != value {
+				t.Errorf("mount %d: expected %s %s, got %v", i, key, value, mount[key])
+			}
+		}
+	}
+}
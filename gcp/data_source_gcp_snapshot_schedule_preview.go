@@ -0,0 +1,295 @@
+package gcp
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// maxPreviewHours bounds the search for scheduled snapshots, so a policy whose schedules rarely or never fire,
+// e.g. a weekly schedule without days, doesn't loop forever.
+const maxPreviewHours = 4 * 366 * 24
+
+func dataSourceGCPSnapshotSchedulePreview() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceGCPSnapshotSchedulePreviewRead,
+		Schema: map[string]*schema.Schema{
+			"snapshot_policy": {
+				Type:     schema.TypeList,
+				Required: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+						"daily_schedule": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"hour": {
+										Type:         schema.TypeInt,
+										Optional:     true,
+										Default:      0,
+										ValidateFunc: validation.IntBetween(0, 23),
+									},
+									"minute": {
+										Type:         schema.TypeInt,
+										Optional:     true,
+										Default:      0,
+										ValidateFunc: validation.IntBetween(0, 59),
+									},
+									"snapshots_to_keep": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+								},
+							},
+						},
+						"hourly_schedule": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"minute": {
+										Type:         schema.TypeInt,
+										Optional:     true,
+										Default:      0,
+										ValidateFunc: validation.IntBetween(0, 59),
+									},
+									"snapshots_to_keep": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+								},
+							},
+						},
+						"monthly_schedule": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"days_of_month": {
+										Type:     schema.TypeString,
+										Optional: true,
+										Default:  "1",
+									},
+									"hour": {
+										Type:         schema.TypeInt,
+										Optional:     true,
+										Default:      0,
+										ValidateFunc: validation.IntBetween(0, 23),
+									},
+									"minute": {
+										Type:         schema.TypeInt,
+										Optional:     true,
+										Default:      0,
+										ValidateFunc: validation.IntBetween(0, 59),
+									},
+									"snapshots_to_keep": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+								},
+							},
+						},
+						"weekly_schedule": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"day": {
+										Type:     schema.TypeString,
+										Optional: true,
+										Default:  "Sunday",
+									},
+									"hour": {
+										Type:         schema.TypeInt,
+										Optional:     true,
+										Default:      0,
+										ValidateFunc: validation.IntBetween(0, 23),
+									},
+									"minute": {
+										Type:         schema.TypeInt,
+										Optional:     true,
+										Default:      0,
+										ValidateFunc: validation.IntBetween(0, 59),
+									},
+									"snapshots_to_keep": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"snapshot_count": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntBetween(1, 1000),
+			},
+			"start_time": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.ValidateRFC3339TimeString,
+			},
+			"snapshots": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"schedule": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceGCPSnapshotSchedulePreviewRead(d *schema.ResourceData, meta interface{}) error {
+	start := time.Now().UTC()
+	if v, ok := d.GetOk("start_time"); ok {
+		parsed, err := time.Parse(time.RFC3339, v.(string))
+		if err != nil {
+			return fmt.Errorf("Error parsing start_time: %s", err)
+		}
+		start = parsed.UTC()
+	}
+
+	var policy snapshotPolicy
+	if v := d.Get("snapshot_policy").([]interface{}); len(v) > 0 && v[0] != nil {
+		policy = expandSnapshotPolicy(v[0].(map[string]interface{}))
+	}
+
+	snapshots, err := snapshotSchedulePreview(policy, start, d.Get("snapshot_count").(int))
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		result = append(result, map[string]interface{}{
+			"time":     snapshot.Time.Format(time.RFC3339),
+			"schedule": snapshot.Schedule,
+		})
+	}
+	if err := d.Set("snapshots", result); err != nil {
+		return fmt.Errorf("Error reading snapshot schedule preview snapshots: %s", err)
+	}
+	d.SetId(start.Format(time.RFC3339))
+	return nil
+}
+
+// scheduledSnapshot is a snapshot a schedule of a snapshot policy will make
+type scheduledSnapshot struct {
+	Time     time.Time
+	Schedule string
+}
+
+// snapshotSchedulePreview returns the next count snapshots made by the policy after start, in UTC.
+// A schedule is active if it keeps at least one snapshot. Snapshots of different schedules at the same time
+// are all returned.
+func snapshotSchedulePreview(policy snapshotPolicy, start time.Time, count int) ([]scheduledSnapshot, error) {
+	if !policy.Enabled {
+		return []scheduledSnapshot{}, nil
+	}
+	daysOfMonth, err := parseDaysOfMonth(policy.MonthlySchedule.DaysOfMonth)
+	if err != nil {
+		return nil, err
+	}
+	weekdays, err := parseWeekdays(policy.WeeklySchedule.Day)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []scheduledSnapshot{}
+	hour := start.UTC().Truncate(time.Hour)
+	for i := 0; i < maxPreviewHours && len(snapshots) < count; i++ {
+		var candidates []scheduledSnapshot
+		if policy.HourlySchedule.SnapshotsToKeep > 0 {
+			candidates = append(candidates, scheduledSnapshot{hour.Add(time.Duration(policy.HourlySchedule.Minute) * time.Minute), "hourly"})
+		}
+		if policy.DailySchedule.SnapshotsToKeep > 0 && hour.Hour() == policy.DailySchedule.Hour {
+			candidates = append(candidates, scheduledSnapshot{hour.Add(time.Duration(policy.DailySchedule.Minute) * time.Minute), "daily"})
+		}
+		if policy.WeeklySchedule.SnapshotsToKeep > 0 && hour.Hour() == policy.WeeklySchedule.Hour && weekdays[hour.Weekday()] {
+			candidates = append(candidates, scheduledSnapshot{hour.Add(time.Duration(policy.WeeklySchedule.Minute) * time.Minute), "weekly"})
+		}
+		if policy.MonthlySchedule.SnapshotsToKeep > 0 && hour.Hour() == policy.MonthlySchedule.Hour && daysOfMonth[hour.Day()] {
+			candidates = append(candidates, scheduledSnapshot{hour.Add(time.Duration(policy.MonthlySchedule.Minute) * time.Minute), "monthly"})
+		}
+		sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].Time.Before(candidates[b].Time) })
+		for _, candidate := range candidates {
+			if candidate.Time.After(start) && len(snapshots) < count {
+				snapshots = append(snapshots, candidate)
+			}
+		}
+		hour = hour.Add(time.Hour)
+	}
+	return snapshots, nil
+}
+
+// parseDaysOfMonth parses a comma delimited list of days of the month, e.g. "1,15,31"
+func parseDaysOfMonth(value string) (map[int]bool, error) {
+	days := make(map[int]bool)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		day, err := strconv.Atoi(item)
+		if err != nil || day < 1 || day > 31 {
+			return nil, fmt.Errorf("invalid day of month %q in days_of_month, expected 1 to 31", item)
+		}
+		days[day] = true
+	}
+	return days, nil
+}
+
+// parseWeekdays parses a comma delimited list of week day names, e.g. "Monday,Friday"
+func parseWeekdays(value string) (map[time.Weekday]bool, error) {
+	weekdays := make(map[time.Weekday]bool)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		found := false
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(item, day.String()) {
+				weekdays[day] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid week day %q in day, expected a name such as Monday", item)
+		}
+	}
+	return weekdays, nil
+}
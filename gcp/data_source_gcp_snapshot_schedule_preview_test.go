@@ -0,0 +1,52 @@
+package gcp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotSchedulePreview(t *testing.T) {
+	policy := snapshotPolicy{
+		Enabled:         true,
+		HourlySchedule:  hourlySchedule{Minute: 30, SnapshotsToKeep: 0},
+		DailySchedule:   dailySchedule{Hour: 2, Minute: 15, SnapshotsToKeep: 7},
+		WeeklySchedule:  weeklySchedule{Day: "Monday,friday", Hour: 2, Minute: 15, SnapshotsToKeep: 4},
+		MonthlySchedule: monthlySchedule{DaysOfMonth: "1,31", Hour: 23, Minute: 0, SnapshotsToKeep: 12},
+	}
+	// Thursday
+	start := time.Date(2020, 10, 29, 2, 15, 0, 0, time.UTC)
+	expected := []scheduledSnapshot{
+		{time.Date(2020, 10, 30, 2, 15, 0, 0, time.UTC), "daily"},
+		{time.Date(2020, 10, 30, 2, 15, 0, 0, time.UTC), "weekly"},
+		{time.Date(2020, 10, 31, 2, 15, 0, 0, time.UTC), "daily"},
+		{time.Date(2020, 10, 31, 23, 0, 0, 0, time.UTC), "monthly"},
+		{time.Date(2020, 11, 1, 2, 15, 0, 0, time.UTC), "daily"},
+		{time.Date(2020, 11, 1, 23, 0, 0, 0, time.UTC), "monthly"},
+		{time.Date(2020, 11, 2, 2, 15, 0, 0, time.UTC), "daily"},
+		{time.Date(2020, 11, 2, 2, 15, 0, 0, time.UTC), "weekly"},
+	}
+
+	snapshots, err := snapshotSchedulePreview(policy, start, len(expected))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(snapshots) != len(expected) {
+		t.Fatalf("expected %d snapshots, got %d: %v", len(expected), len(snapshots), snapshots)
+	}
+	for i := range expected {
+		if !snapshots[i].Time.Equal(expected[i].Time) || snapshots[i].Schedule != expected[i].Schedule {
+			t.Errorf("snapshot %d: expected %v, got %v", i, expected[i], snapshots[i])
+		}
+	}
+
+	policy.Enabled = false
+	if snapshots, _ := snapshotSchedulePreview(policy, start, 10); len(snapshots) != 0 {
+		t.Errorf("expected no snapshots for a disabled policy, got %v", snapshots)
+	}
+
+	policy.Enabled = true
+	policy.WeeklySchedule.Day = "Funday"
+	if _, err := snapshotSchedulePreview(policy, start, 10); err == nil {
+		t.Error("expected error for an invalid week day, got nil")
+	}
+}
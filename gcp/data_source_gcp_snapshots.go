@@ -0,0 +1,138 @@
+package gcp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func dataSourceGCPSnapshots() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceGCPSnapshotsRead,
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"volume_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"created_after": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.ValidateRFC3339TimeString,
+			},
+			"created_before": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.ValidateRFC3339TimeString,
+			},
+			"snapshots": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"snapshot_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"created": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"used_bytes": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"lifecycle_state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceGCPSnapshotsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	region := d.Get("region").(string)
+	volumeID := d.Get("volume_id").(string)
+
+	var after, before time.Time
+	if v, ok := d.GetOk("created_after"); ok {
+		parsed, err := time.Parse(time.RFC3339, v.(string))
+		if err != nil {
+			return fmt.Errorf("Error parsing created_after: %s", err)
+		}
+		after = parsed
+	}
+	if v, ok := d.GetOk("created_before"); ok {
+		parsed, err := time.Parse(time.RFC3339, v.(string))
+		if err != nil {
+			return fmt.Errorf("Error parsing created_before: %s", err)
+		}
+		before = parsed
+	}
+
+	snapshots, err := client.listSnapshotsForVolume(region, volumeID)
+	if err != nil {
+		return err
+	}
+	snapshots, err = filterSnapshotsByCreation(snapshots, after, before)
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		result = append(result, map[string]interface{}{
+			"snapshot_id":     snapshot.SnapshotID,
+			"name":            snapshot.Name,
+			"created":         snapshot.Created,
+			"used_bytes":      int(snapshot.UsedBytes),
+			"lifecycle_state": snapshot.LifeCycleState,
+		})
+	}
+	if err := d.Set("snapshots", result); err != nil {
+		return fmt.Errorf("Error reading snapshots: %s", err)
+	}
+	d.SetId(volumeID)
+	return nil
+}
+
+// filterSnapshotsByCreation returns the snapshots created after after and before before, oldest first.
+// A zero time doesn't filter.
+func filterSnapshotsByCreation(snapshots []listSnapshotResult, after time.Time, before time.Time) ([]listSnapshotResult, error) {
+	type createdSnapshot struct {
+		snapshot listSnapshotResult
+		created  time.Time
+	}
+	var filtered []createdSnapshot
+	for _, snapshot := range snapshots {
+		created, err := time.Parse(time.RFC3339, snapshot.Created)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing creation time of snapshot %s: %s", snapshot.SnapshotID, err)
+		}
+		if (!after.IsZero() && !created.After(after)) || (!before.IsZero() && !created.Before(before)) {
+			continue
+		}
+		filtered = append(filtered, createdSnapshot{snapshot, created})
+	}
+	sort.SliceStable(filtered, func(i, j int) bool { return filtered[i].created.Before(filtered[j].created) })
+
+	result := make([]listSnapshotResult, 0, len(filtered))
+	for _, f := range filtered {
+		result = append(result, f.snapshot)
+	}
+	return result, nil
+}
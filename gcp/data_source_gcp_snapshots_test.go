@@ -0,0 +1,49 @@
+package gcp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterSnapshotsByCreation(t *testing.T) {
+	snapshots := []listSnapshotResult{
+		{SnapshotID: "c", Created: "2020-10-03T00:00:00.000Z"},
+		{SnapshotID: "a", Created: "2020-10-01T00:00:00.000Z"},
+		{SnapshotID: "b", Created: "2020-10-02T00:00:00.000Z"},
+	}
+	cases := []struct {
+		after    string
+		before   string
+		expected string
+	}{
+		{"", "", "abc"},
+		{"2020-10-01T00:00:00Z", "", "bc"},
+		{"", "2020-10-03T00:00:00Z", "ab"},
+		{"2020-10-01T12:00:00Z", "2020-10-02T12:00:00Z", "b"},
+		{"2020-10-04T00:00:00Z", "", ""},
+	}
+	for _, tc := range cases {
+		var after, before time.Time
+		if tc.after != "" {
+			after, _ = time.Parse(time.RFC3339, tc.after)
+		}
+		if tc.before != "" {
+			before, _ = time.Parse(time.RFC3339, tc.before)
+		}
+		filtered, err := filterSnapshotsByCreation(snapshots, after, before)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ids := ""
+		for _, snapshot := range filtered {
+			ids += snapshot.SnapshotID
+		}
+		if ids != tc.expected {
+			t.Errorf("after %q before %q: expected %q, got %q", tc.after, tc.before, tc.expected, ids)
+		}
+	}
+
+	if _, err := filterSnapshotsByCreation([]listSnapshotResult{{SnapshotID: "x", Created: "yesterday"}}, time.Time{}, time.Time{}); err == nil {
+		t.Error("expected an error for an invalid creation time")
+	}
+}
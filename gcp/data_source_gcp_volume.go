@@ -3,7 +3,6 @@ package gcp
 import (
 	"fmt"
 	"log"
-	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -14,7 +13,8 @@ func dataSourceGCPVolume() *schema.Resource {
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
+				Computed: true,
 			},
 			"type_dp": {
 				Type:     schema.TypeBool,
@@ -35,6 +35,10 @@ func dataSourceGCPVolume() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"network_full_path": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"size": {
 				Type:     schema.TypeInt,
 				Computed: true,
@@ -44,6 +48,15 @@ func dataSourceGCPVolume() *schema.Resource {
 				Computed: true,
 			},
 			"volume_path": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"lifecycle_state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"lifecycle_state_details": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
@@ -67,6 +80,30 @@ func dataSourceGCPVolume() *schema.Resource {
 					},
 				},
 			},
+			"backup_policy": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"daily_backups_to_keep": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"weekly_backups_to_keep": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"monthly_backups_to_keep": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
 			"snapshot_policy": {
 				Type:     schema.TypeList,
 				Computed: true,
@@ -239,6 +276,36 @@ func dataSourceGCPVolume() *schema.Resource {
 					},
 				},
 			},
+			"labels": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"smb_share_settings": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"snapshot_directory": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"smb_share_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"nfs_mount_command": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"smb_unc_path": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"zone": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -257,11 +324,16 @@ func dataSourceGCPVolumeRead(d *schema.ResourceData, meta interface{}) error {
 
 	volume := volumeRequest{}
 
+	// look the volume up by name, volume path (creation token) or both
 	volume.Name = d.Get("name").(string)
+	volume.CreationToken = d.Get("volume_path").(string)
 	volume.Region = d.Get("region").(string)
+	if volume.Name == "" && volume.CreationToken == "" {
+		return fmt.Errorf("one of name or volume_path must be set")
+	}
 
 	var res volumeResult
-	res, err := client.getVolumeByNameOrCreationToken(volume)
+	res, err := client.getSharedVolumeByNameOrCreationToken(volume)
 	if err != nil {
 		return err
 	}
@@ -279,13 +351,19 @@ func dataSourceGCPVolumeRead(d *schema.ResourceData, meta interface{}) error {
 	if err := d.Set("name", res.Name); err != nil {
 		return fmt.Errorf("Error reading volume name: %s", err)
 	}
+	if err := d.Set("lifecycle_state", res.LifeCycleState); err != nil {
+		return fmt.Errorf("Error reading volume lifecycle_state: %s", err)
+	}
+	if err := d.Set("lifecycle_state_details", res.LifeCycleStateDetails); err != nil {
+		return fmt.Errorf("Error reading volume lifecycle_state_details: %s", err)
+	}
 	if err := d.Set("type_dp", res.TypeDP); err != nil {
 		return fmt.Errorf("Error reading type_dp: %s", err)
 	}
-	if err := d.Set("size", res.Size/GiBToBytes); err != nil {
+	if err := d.Set("size", sizeInGiB(res.Size)); err != nil {
 		return fmt.Errorf("Error reading volume size: %s", err)
 	}
-	if err := d.Set("service_level", res.ServiceLevel); err != nil {
+	if err := d.Set("service_level", serviceLevelFromAPI(res.ServiceLevel)); err != nil {
 		return fmt.Errorf("Error reading volume service_level: %s", err)
 	}
 	for i, protocol := range res.ProtocolTypes {
@@ -293,20 +371,18 @@ func dataSourceGCPVolumeRead(d *schema.ResourceData, meta interface{}) error {
 			res.ProtocolTypes[i] = "SMB"
 		}
 	}
-	if err := d.Set("protocol_types", res.ProtocolTypes); err != nil {
+	if err := d.Set("protocol_types", sortProtocolTypes(res.ProtocolTypes, nil)); err != nil {
 		return fmt.Errorf("Error reading volume protocol_types: %s", err)
 	}
 	if err := d.Set("volume_path", res.CreationToken); err != nil {
 		return fmt.Errorf("Error reading volume path or Creation Token: %s", err)
 	}
-	network := res.Network
-	index := strings.Index(network, "networks/")
-	if index > -1 {
-		network = network[index+len("networks/"):]
-	}
-	if err := d.Set("network", network); err != nil {
+	if err := d.Set("network", networkShortName(res.Network)); err != nil {
 		return fmt.Errorf("Error reading volume network: %s", err)
 	}
+	if err := d.Set("network_full_path", res.Network); err != nil {
+		return fmt.Errorf("Error reading volume network_full_path: %s", err)
+	}
 	if err := d.Set("region", res.Region); err != nil {
 		return fmt.Errorf("Error reading volume region: %s", err)
 	}
@@ -315,6 +391,9 @@ func dataSourceGCPVolumeRead(d *schema.ResourceData, meta interface{}) error {
 	if err := d.Set("snapshot_policy", snapshotPolicy); err != nil {
 		return fmt.Errorf("Error reading volume snapshot_policy: %s", err)
 	}
+	if err := d.Set("backup_policy", flattenBackupPolicy(res.BackupPolicy)); err != nil {
+		return fmt.Errorf("Error reading volume backup_policy: %s", err)
+	}
 	if len(res.ExportPolicy.Rules) > 0 {
 		if err := d.Set("export_policy", exportPolicy); err != nil {
 			return fmt.Errorf("Error reading volume export_policy: %s", err)
@@ -325,10 +404,29 @@ func dataSourceGCPVolumeRead(d *schema.ResourceData, meta interface{}) error {
 			return fmt.Errorf("Error reading volume export_policy: %s", err)
 		}
 	}
+	sortMountPoints(res.MountPoints)
 	mountPoints := flattenMountPoints(res.MountPoints)
 	if err := d.Set("mount_points", mountPoints); err != nil {
 		return fmt.Errorf("Error reading volume mount_points: %s", err)
 	}
+	if err := d.Set("labels", res.Labels); err != nil {
+		return fmt.Errorf("Error reading volume labels: %s", err)
+	}
+	if err := d.Set("smb_share_settings", res.SmbShareSettings); err != nil {
+		return fmt.Errorf("Error reading volume smb_share_settings: %s", err)
+	}
+	if err := d.Set("snapshot_directory", res.SnapshotDirectory); err != nil {
+		return fmt.Errorf("Error reading volume snapshot_directory: %s", err)
+	}
+	if err := d.Set("smb_share_name", smbShareName(res.MountPoints)); err != nil {
+		return fmt.Errorf("Error reading volume smb_share_name: %s", err)
+	}
+	if err := d.Set("nfs_mount_command", nfsMountCommand(res.MountPoints)); err != nil {
+		return fmt.Errorf("Error reading volume nfs_mount_command: %s", err)
+	}
+	if err := d.Set("smb_unc_path", smbUNCPath(res.MountPoints)); err != nil {
+		return fmt.Errorf("Error reading volume smb_unc_path: %s", err)
+	}
 	if err := d.Set("zone", res.Zone); err != nil {
 		return fmt.Errorf("Error reading zone: %s", err)
 	}
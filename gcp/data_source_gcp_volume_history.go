@@ -0,0 +1,88 @@
+package gcp
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func dataSourceGCPVolumeHistory() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceGCPVolumeHistoryRead,
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"volume_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"changes": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"job_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"action": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"state_details": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"created": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceGCPVolumeHistoryRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	region := d.Get("region").(string)
+	volumeID := d.Get("volume_id").(string)
+
+	jobs, err := client.listJobsForVolume(region, volumeID)
+	if err != nil {
+		return err
+	}
+	if limit := d.Get("limit").(int); len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+
+	changes := make([]map[string]interface{}, 0, len(jobs))
+	for _, job := range jobs {
+		changes = append(changes, map[string]interface{}{
+			"job_id":        job.JobID,
+			"action":        job.Action,
+			"state":         job.State,
+			"state_details": job.StateDetails,
+			"created":       job.Created,
+		})
+	}
+	if err := d.Set("changes", changes); err != nil {
+		return fmt.Errorf("Error reading volume history changes: %s", err)
+	}
+	d.SetId(volumeID)
+	return nil
+}
@@ -0,0 +1,186 @@
+package gcp
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func dataSourceGCPVolumes() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceGCPVolumesRead,
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.ValidateRegexp,
+			},
+			"service_level": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"network": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"protocol_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"NFSv3", "NFSv4", "CIFS", "SMB"}, true),
+			},
+			"creation_token": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"volumes": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"volume_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"volume_path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"service_level": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"network": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"protocol_types": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"lifecycle_state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"zone": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"storage_class": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceGCPVolumesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	region := d.Get("region").(string)
+
+	filter := volumeFilter{
+		serviceLevel:  d.Get("service_level").(string),
+		network:       d.Get("network").(string),
+		protocolType:  d.Get("protocol_type").(string),
+		creationToken: d.Get("creation_token").(string),
+	}
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex, err := regexp.Compile(v.(string))
+		if err != nil {
+			return fmt.Errorf("Error parsing name_regex: %s", err)
+		}
+		filter.nameRegex = nameRegex
+	}
+
+	volumes, err := client.listVolumesShared(region)
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(volumes))
+	for _, volume := range filterVolumes(volumes, filter) {
+		protocolTypes := make([]string, 0, len(volume.ProtocolTypes))
+		for _, protocol := range volume.ProtocolTypes {
+			if protocol == "CIFS" {
+				protocol = "SMB"
+			}
+			protocolTypes = append(protocolTypes, protocol)
+		}
+		result = append(result, map[string]interface{}{
+			"volume_id":       volume.VolumeID,
+			"name":            volume.Name,
+			"volume_path":     volume.CreationToken,
+			"size":            sizeInGiB(volume.Size),
+			"service_level":   serviceLevelFromAPI(volume.ServiceLevel),
+			"network":         networkShortName(volume.Network),
+			"protocol_types":  sortProtocolTypes(protocolTypes, nil),
+			"lifecycle_state": volume.LifeCycleState,
+			"zone":            volume.Zone,
+			"storage_class":   volume.StorageClass,
+		})
+	}
+	if err := d.Set("volumes", result); err != nil {
+		return fmt.Errorf("Error reading volumes: %s", err)
+	}
+	d.SetId(region)
+	return nil
+}
+
+// volumeFilter selects volumes of a region. Empty fields don't filter.
+type volumeFilter struct {
+	nameRegex    *regexp.Regexp
+	serviceLevel string
+	network      string
+	protocolType string
+	// creationToken is a prefix of the creation tokens of the volumes
+	creationToken string
+}
+
+// filterVolumes returns the volumes matching all fields of the filter sorted by name, ignoring deleted volumes
+func filterVolumes(volumes []volumeResult, filter volumeFilter) []volumeResult {
+	filtered := []volumeResult{}
+	for _, volume := range volumes {
+		if volume.LifeCycleState == "deleted" || volume.LifeCycleState == "deleting" {
+			continue
+		}
+		if filter.nameRegex != nil && !filter.nameRegex.MatchString(volume.Name) {
+			continue
+		}
+		if filter.serviceLevel != "" && !strings.EqualFold(serviceLevelFromAPI(volume.ServiceLevel), canonicalServiceLevel(filter.serviceLevel)) {
+			continue
+		}
+		if filter.network != "" && networkShortName(volume.Network) != networkShortName(filter.network) {
+			continue
+		}
+		if filter.protocolType != "" && !hasProtocolType(volume.ProtocolTypes, apiProtocolType(filter.protocolType)) {
+			continue
+		}
+		if !strings.HasPrefix(volume.CreationToken, filter.creationToken) {
+			continue
+		}
+		filtered = append(filtered, volume)
+	}
+	sort.SliceStable(filtered, func(i, j int) bool { return filtered[i].Name < filtered[j].Name })
+	return filtered
+}
@@ -0,0 +1,46 @@
+package gcp
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFilterVolumes(t *testing.T) {
+	volumes := []volumeResult{
+		{Name: "prod-db", CreationToken: "team-a-db", ServiceLevel: "extreme", Network: "projects/123/global/networks/prod", ProtocolTypes: []string{"NFSv3"}, LifeCycleState: "available"},
+		{Name: "dev-share", CreationToken: "team-b-share", ServiceLevel: "basic", Network: "projects/123/global/networks/dev", ProtocolTypes: []string{"CIFS"}, LifeCycleState: "available"},
+		{Name: "prod-home", CreationToken: "team-a-home", ServiceLevel: "standard", Network: "projects/123/global/networks/prod", ProtocolTypes: []string{"NFSv3", "NFSv4"}, LifeCycleState: "available"},
+		{Name: "prod-old", CreationToken: "team-a-old", ServiceLevel: "extreme", Network: "projects/123/global/networks/prod", ProtocolTypes: []string{"NFSv3"}, LifeCycleState: "deleted"},
+	}
+	cases := []struct {
+		filter   volumeFilter
+		expected []string
+	}{
+		{volumeFilter{}, []string{"dev-share", "prod-db", "prod-home"}},
+		{volumeFilter{nameRegex: regexp.MustCompile("^prod-")}, []string{"prod-db", "prod-home"}},
+		{volumeFilter{serviceLevel: "Premium"}, []string{"prod-home"}},
+		{volumeFilter{serviceLevel: "standard"}, []string{"dev-share"}},
+		{volumeFilter{network: "prod", protocolType: "nfsv4"}, []string{"prod-home"}},
+		{volumeFilter{protocolType: "SMB"}, []string{"dev-share"}},
+		{volumeFilter{creationToken: "team-a-"}, []string{"prod-db", "prod-home"}},
+		{volumeFilter{creationToken: "team-a-home", protocolType: "NFSv4"}, []string{"prod-home"}},
+		{volumeFilter{creationToken: "Team-a-"}, []string{}},
+	}
+	for i, tc := range cases {
+		filtered := filterVolumes(volumes, tc.filter)
+		names := make([]string, 0, len(filtered))
+		for _, volume := range filtered {
+			names = append(names, volume.Name)
+		}
+		if len(names) != len(tc.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, tc.expected, names)
+			continue
+		}
+		for j := range names {
+			if names[j] != tc.expected[j] {
+				t.Errorf("case %d: expected %v, got %v", i, tc.expected, names)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package gcp
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// descriptions holds the description of every attribute per resource type. Data sources share the table
+// of the resource with the same name. Nested attributes are keyed by their path, e.g.
+// "snapshot_policy.daily_schedule.hour"; if a path has no entry, the longest matching suffix of the path is used,
+// e.g. "nfsv3.checked" or "hour".
+// The descriptions end up in the provider schema, which is also the source for cmd/docgen.
+var descriptions = map[string]map[string]string{
+	"netapp-gcp_volume": {
+		"name":                         "The name of the volume.",
+		"type_dp":                      "Whether the volume is a data protection (replication destination) volume.",
+		"region":                       "The region of the volume.",
+		"protocol_types":               "The protocol types of the volume: NFSv3, NFSv4, CIFS or SMB. The values are case insensitive.",
+		"network":                      "The name of the VPC network of the volume.",
+		"network_full_path":            "The full network path of the volume as returned by the API.",
+		"volume_id":                    "The ID of the volume, the same as id.",
+		"size":                         "The size of the volume in GiB, between 1024 and 102400, or from 1 for software volumes. Conflicts with size_in_gib.",
+		"size_in_gib":                  "The size of the volume in GiB, the same as size. Conflicts with size.",
+		"service_level":                "The service level of the volume: standard, premium or extreme. The API names basic, standard and extreme are accepted for them.",
+		"volume_path":                  "The volume path (creation token) of the volume. Generated if not set.",
+		"shared_vpc_project_number":    "The host project number when deploying in a shared VPC service project.",
+		"mount_points":                 "The mount points of the volume.",
+		"mount_points.export":          "The export path of the mount point.",
+		"mount_points.server":          "The server IP address of the mount point.",
+		"mount_points.protocol_type":   "The protocol of the mount point.",
+		"snapshot_policy":              "The schedules for automatic snapshots of the volume.",
+		"enabled":                      "Whether snapshots are made automatically according to the schedules.",
+		"daily_schedule":               "Make a snapshot every day.",
+		"hourly_schedule":              "Make a snapshot every hour.",
+		"monthly_schedule":             "Make a snapshot every month on specific days.",
+		"weekly_schedule":              "Make a snapshot every week on specific days.",
+		"hour":                         "The hour to start the snapshot (0-23).",
+		"minute":                       "The minute of the hour to start the snapshot (0-59).",
+		"snapshots_to_keep":            "The maximum number of snapshots to keep for the schedule.",
+		"days_of_month":                "A comma delimited list of the days of the month to make a snapshot (1-31), e.g. '1,15,31'.",
+		"day":                          "A comma delimited list of week day names to make a snapshot, e.g. 'Monday,Friday'.",
+		"backup_policy":                "The schedule of automatic backups of the volume.",
+		"backup_policy.enabled":        "Whether backups are made automatically.",
+		"daily_backups_to_keep":        "The number of daily backups to keep.",
+		"weekly_backups_to_keep":       "The number of weekly backups to keep.",
+		"monthly_backups_to_keep":      "The number of monthly backups to keep.",
+		"export_policy":                "The export policy of the volume.",
+		"export_policy.rule":           "An export policy rule.",
+		"access":                       "The access type for clients matching allowed_clients: ReadWrite, ReadOnly or None.",
+		"allowed_clients":              "A comma separated list of IPv4 CIDRs, IPv4 host addresses and host names allowed to access the volume.",
+		"allow_vpc":                    "Allow the primary IP ranges of the subnets of the volume's network, looked up with the Compute API when the rule is applied.",
+		"has_root_access":              "Whether clients matching the rule have root access.",
+		"kerberos5_readonly":           "Allow read only access with Kerberos 5 authentication.",
+		"kerberos5_readwrite":          "Allow read write access with Kerberos 5 authentication.",
+		"kerberos5i_readonly":          "Allow read only access with Kerberos 5 integrity checking.",
+		"kerberos5i_readwrite":         "Allow read write access with Kerberos 5 integrity checking.",
+		"kerberos5p_readonly":          "Allow read only access with Kerberos 5 privacy.",
+		"kerberos5p_readwrite":         "Allow read write access with Kerberos 5 privacy.",
+		"nfsv3":                        "NFSv3 settings of the rule.",
+		"nfsv3.checked":                "Whether the rule allows NFSv3.",
+		"nfsv4":                        "NFSv4 settings of the rule.",
+		"nfsv4.checked":                "Whether the rule allows NFSv4.",
+		"export_policy_from_volume_id": "The ID of a volume in the same region whose export rules are copied at creation, and again when it changes.",
+		"exports_disabled":             "Remove the export rules from the volume, e.g. for a maintenance window, keeping export_policy to restore them.",
+		"labels":                       "The labels of the volume.",
+		"snapshot_id":                  "The ID of a snapshot to create the volume from as a clone. Changing it replaces the volume.",
+		"read_only":                    "Make a clone read-only: its export rules must not allow writes. Requires snapshot_id.",
+		"refresh_from_snapshot_id":     "The ID of a snapshot of the volume. Changing it reverts the volume in place to the snapshot.",
+		"smb_share_settings":           "The settings of the SMB share of a CIFS volume, e.g. encrypt_data to require SMB encryption.",
+		"snapshot_directory":           "Whether the snapshot directory of the volume is visible to clients.",
+		"smb_share_name":               "The name of the SMB share of a CIFS volume.",
+		"nfs_mount_command":            "A command mounting the NFS export of the volume, preferring NFSv3.",
+		"smb_unc_path":                 "The UNC path of the SMB share of a CIFS volume.",
+		"wait_for_state":               "The state to wait for after creating the volume: available, creating or any. Default is available.",
+		"wait_for_snapshot_policy":     "Wait for an enabled snapshot policy to be applied after creating the volume. Default is true.",
+		"recreate_on_error":            "Replace the volume if it is found in error state, instead of failing.",
+		"lifecycle_state":              "The lifecycle state of the volume, e.g. available or error.",
+		"lifecycle_state_details":      "Details of the lifecycle state of the volume.",
+		"delete_on_creation_error":     "Delete the volume if it is in error state after creation.",
+		"allow_shrink":                 "Allow updates lowering the size of the volume.",
+		"deletion_protection":          "Refuse to delete the volume while true.",
+		"delete_snapshots_on_destroy":  "Delete the snapshots of the volume before deleting the volume.",
+		"extra_request_parameters":     "A JSON object merged into the body of the create and update requests of the volume, for API fields the provider doesn't support yet.",
+		"kms_key_ring":                 "The key ring of a customer-managed Cloud KMS key to encrypt the volume with. Requires crypto_key.",
+		"crypto_key":                   "The name of the customer-managed Cloud KMS key to encrypt the volume with, registered with a netapp-gcp_kms_config in the region.",
+		"zone":                         "The zone of the volume. Required if storage_class is software.",
+		"storage_class":                "The storage class of the volume: hardware or software.",
+	},
+	"netapp-gcp_snapshot": {
+		"name":           "The name of the snapshot.",
+		"region":         "The region of the volume.",
+		"volume_name":    "The name of the volume to snapshot.",
+		"creation_token": "The creation token of the volume to snapshot.",
+		"created":        "The creation time of the snapshot.",
+		"used_bytes":     "The space used by the snapshot in bytes.",
+	},
+	"netapp-gcp_volume_backup": {
+		"name":           "The name of the backup.",
+		"region":         "The region of the volume.",
+		"volume_name":    "The name of the volume to back up.",
+		"creation_token": "The creation token of the volume to back up.",
+	},
+	"netapp-gcp_kms_config": {
+		"region":                    "The region to use the key in.",
+		"key_ring_location":         "The location of the key ring, e.g. global or the region.",
+		"key_ring":                  "The name of the key ring of the key.",
+		"crypto_key":                "The name of the crypto key.",
+		"key_project_id":            "The ID of the project of the key ring.",
+		"network":                   "The name of the VPC network the service uses to reach Cloud KMS.",
+		"shared_vpc_project_number": "The host project number when the network is a shared VPC.",
+		"state":                     "The state of the KMS config.",
+		"state_details":             "Details of the state of the KMS config.",
+	},
+	"netapp-gcp_snapshot_schedule_preview": {
+		"snapshot_policy":   "The snapshot policy to preview, in the same format as the snapshot_policy of a volume.",
+		"enabled":           "Whether snapshots are made according to the schedules. Default is true.",
+		"daily_schedule":    "Make a snapshot every day.",
+		"hourly_schedule":   "Make a snapshot every hour.",
+		"monthly_schedule":  "Make a snapshot every month on specific days.",
+		"weekly_schedule":   "Make a snapshot every week on specific days.",
+		"hour":              "The hour to start the snapshot (0-23).",
+		"minute":            "The minute of the hour to start the snapshot (0-59).",
+		"snapshots_to_keep": "The maximum number of snapshots to keep for the schedule. A schedule keeping no snapshots is inactive.",
+		"days_of_month":     "A comma delimited list of the days of the month to make a snapshot (1-31), e.g. '1,15,31'.",
+		"day":               "A comma delimited list of week day names to make a snapshot, e.g. 'Monday,Friday'.",
+		"snapshot_count":    "The number of scheduled snapshots to return. Default is 10.",
+		"start_time":        "The RFC 3339 time to start the preview from. Defaults to the current time.",
+		"snapshots":         "The next scheduled snapshots in chronological order.",
+		"snapshots.time":    "The UTC time of the snapshot in RFC 3339 format.",
+		"schedule":          "The schedule making the snapshot: hourly, daily, weekly or monthly.",
+	},
+	"netapp-gcp_snapshots": {
+		"region":          "The region of the volume.",
+		"volume_id":       "The ID of the volume.",
+		"created_after":   "Only return snapshots created after this RFC 3339 time.",
+		"created_before":  "Only return snapshots created before this RFC 3339 time.",
+		"snapshots":       "The snapshots of the volume, oldest first.",
+		"snapshot_id":     "The ID of the snapshot.",
+		"name":            "The name of the snapshot.",
+		"created":         "The time the snapshot was created, in RFC 3339 format.",
+		"used_bytes":      "The space used by the snapshot in bytes.",
+		"lifecycle_state": "The lifecycle state of the snapshot.",
+	},
+	"netapp-gcp_volumes": {
+		"region":                "The region to list the volumes of.",
+		"name_regex":            "Only return volumes whose name matches this regular expression.",
+		"service_level":         "Only return volumes of this service level, e.g. premium.",
+		"network":               "Only return volumes in this VPC network, by name or full path.",
+		"protocol_type":         "Only return volumes supporting this protocol type: NFSv3, NFSv4, CIFS or SMB.",
+		"creation_token":        "Only return volumes whose volume path (creation token) starts with this prefix.",
+		"volumes":               "The matching volumes, sorted by name.",
+		"volume_id":             "The ID of the volume.",
+		"name":                  "The name of the volume.",
+		"volume_path":           "The volume path (creation token) of the volume.",
+		"size":                  "The size of the volume in GiB.",
+		"volumes.service_level": "The service level of the volume.",
+		"volumes.network":       "The name of the VPC network of the volume.",
+		"protocol_types":        "The protocol types of the volume.",
+		"lifecycle_state":       "The lifecycle state of the volume.",
+		"zone":                  "The zone of the volume.",
+		"storage_class":         "The storage class of the volume.",
+	},
+	"netapp-gcp_snapshot_mount": {
+		"region":             "The region of the volume.",
+		"volume_id":          "The ID of the volume.",
+		"snapshot_id":        "The ID of the snapshot to restore files from.",
+		"snapshot_name":      "The name of the snapshot, which is its directory name in the snapshot directory.",
+		"snapshot_directory": "Whether the snapshot directory of the volume is visible to clients.",
+		"mounts":             "Where to find the snapshot, per mount point of the volume.",
+		"protocol_type":      "The protocol of the mount point.",
+		"server":             "The server IP address of the mount point.",
+		"path":               "The path of the snapshot: the .snapshot directory of NFS exports or the ~snapshot directory of SMB shares.",
+		"source":             "What to mount or open: server:path for NFS, the UNC path for SMB.",
+		"mount_options":      "The options to mount the snapshot read-only with.",
+	},
+	"netapp-gcp_filestore_conversion": {
+		"name":               "The name of the Filestore instance, used as the name of the volume.",
+		"location":           "The zone or region of the Filestore instance.",
+		"tier":               "The tier of the Filestore instance, e.g. BASIC_HDD or BASIC_SSD.",
+		"capacity_gb":        "The capacity of the file share of the Filestore instance.",
+		"file_share_name":    "The name of the file share, used as the volume path of the volume.",
+		"network":            "The VPC network of the Filestore instance.",
+		"nfs_export_options": "The NFS export options of the file share.",
+		"ip_ranges":          "The IP ranges the export option applies to.",
+		"access_mode":        "The access of the export option: READ_WRITE or READ_ONLY. Default is READ_WRITE.",
+		"squash_mode":        "The root squash of the export option: NO_ROOT_SQUASH or ROOT_SQUASH. Default is NO_ROOT_SQUASH.",
+		"volume_region":      "The region of the volume, the region of location.",
+		"service_level":      "The service level of the volume equivalent to the tier.",
+		"size":               "The size of the volume in GiB.",
+		"volume_path":        "The volume path of the volume.",
+		"protocol_types":     "The protocol types of the volume.",
+		"export_rules":       "The export policy rules of the volume, one per NFS export option.",
+		"access":             "The access of the rule: ReadWrite or ReadOnly.",
+		"allowed_clients":    "The clients of the rule, comma delimited.",
+		"has_root_access":    "Whether root has access through the rule.",
+		"notes":              "Where the volume differs from the Filestore instance.",
+		"hcl":                "A netapp-gcp_volume resource block with the arguments of the volume.",
+	},
+	"netapp-gcp_volume_history": {
+		"region":        "The region of the volume.",
+		"volume_id":     "The ID of the volume.",
+		"limit":         "The maximum number of changes to return. Default is 10.",
+		"changes":       "The most recent changes to the volume, most recent first.",
+		"job_id":        "The ID of the job that made the change.",
+		"action":        "The kind of change, e.g. create, update or revert.",
+		"state":         "The state of the job.",
+		"state_details": "Details of the state of the job.",
+		"created":       "The time the job was created, in RFC 3339 format.",
+	},
+	"netapp-gcp_active_directory": {
+		"username":            "The user name of an account that can join computers to the domain.",
+		"password":            "The password of the account.",
+		"domain":              "The fully qualified domain name of the Active Directory.",
+		"dns_server":          "The IP address of the DNS server of the domain.",
+		"net_bios":            "The NetBIOS name of the server.",
+		"netbios":             "The NetBIOS name of the server.",
+		"organizational_unit": "The organizational unit to create the computer account in.",
+		"site":                "The Active Directory site to use.",
+		"region":              "The region of the Active Directory connection.",
+		"uuid":                "The UUID of the Active Directory connection.",
+	},
+}
+
+// withDescriptions sets the description of every attribute of the resource from the descriptions table
+func withDescriptions(name string, resource *schema.Resource) *schema.Resource {
+	setDescriptions(descriptions[name], "", resource.Schema)
+	return resource
+}
+
+func setDescriptions(table map[string]string, prefix string, attributes map[string]*schema.Schema) {
+	for key, attribute := range attributes {
+		path := prefix + key
+		if attribute.Description == "" {
+			segments := strings.Split(path, ".")
+			for i := range segments {
+				if description, ok := table[strings.Join(segments[i:], ".")]; ok {
+					attribute.Description = description
+					break
+				}
+			}
+		}
+		if elem, ok := attribute.Elem.(*schema.Resource); ok {
+			setDescriptions(table, path+".", elem.Schema)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package gcp
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// defaultAPIEndpoint is the public endpoint of the API
+	defaultAPIEndpoint = "https://cloudvolumesgcp-api.netapp.com"
+	// defaultAudience is the audience of the tokens of the API, also expected by its regional and private endpoints
+	defaultAudience = "https://cloudvolumesgcp-api.netapp.com"
+)
+
+// apiHost returns the base URL of the API calls of the project at an endpoint. An endpoint without a path, e.g. a
+// Private Service Connect endpoint, gets the path of the project. An endpoint with a path is a full base URL, e.g.
+// of the fakecvs server, and is used as is.
+func apiHost(endpoint string, project string) string {
+	if endpoint == "" {
+		endpoint = defaultAPIEndpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || strings.Trim(u.Path, "/") == "" {
+		return fmt.Sprintf("%s/v2/projects/%s/locations/", strings.TrimSuffix(endpoint, "/"), project)
+	}
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	return endpoint
+}
+
+// validateURL is a ValidateFunc accepting an absolute http or https URL
+func validateURL(v interface{}, k string) ([]string, []error) {
+	value := v.(string)
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, []error{fmt.Errorf("expected %s to be an http or https URL, got %s", k, value)}
+	}
+	return nil, nil
+}
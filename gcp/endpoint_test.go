@@ -0,0 +1,34 @@
+package gcp
+
+import "testing"
+
+func TestAPIHost(t *testing.T) {
+	cases := []struct {
+		endpoint string
+		expected string
+	}{
+		{"", "https://cloudvolumesgcp-api.netapp.com/v2/projects/123456/locations/"},
+		{"https://cvs.p.googleapis.com", "https://cvs.p.googleapis.com/v2/projects/123456/locations/"},
+		{"https://cvs.p.googleapis.com/", "https://cvs.p.googleapis.com/v2/projects/123456/locations/"},
+		{"http://127.0.0.1:8080/v2/projects/123456789/locations/", "http://127.0.0.1:8080/v2/projects/123456789/locations/"},
+		{"http://127.0.0.1:8080/v2/projects/123456789/locations", "http://127.0.0.1:8080/v2/projects/123456789/locations/"},
+	}
+	for _, c := range cases {
+		if host := apiHost(c.endpoint, "123456"); host != c.expected {
+			t.Errorf("apiHost(%q) = %s, expected %s", c.endpoint, host, c.expected)
+		}
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	for _, value := range []string{"https://cvs.p.googleapis.com", "http://127.0.0.1:8080/v2/projects/1/locations/"} {
+		if _, errs := validateURL(value, "api_host"); len(errs) != 0 {
+			t.Errorf("unexpected error for %s: %v", value, errs)
+		}
+	}
+	for _, value := range []string{"cvs.p.googleapis.com", "ftp://cvs", "https://"} {
+		if _, errs := validateURL(value, "api_host"); len(errs) == 0 {
+			t.Errorf("expected an error for %s", value)
+		}
+	}
+}
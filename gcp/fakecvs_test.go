@@ -0,0 +1,275 @@
+package gcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/fakecvs"
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/restapi"
+)
+
+// fakeCVS is a fakecvs server for the tests, counting the requests it receives
+type fakeCVS struct {
+	*fakecvs.Server
+	mutex    sync.Mutex
+	requests map[string]int
+}
+
+// newFakeCVS starts a fakecvs server failing with the faults, see fakecvs.Server.AddFault, and returns a client of
+// it that polls and retries without delay
+func newFakeCVS(t *testing.T, faults ...string) (*Client, *fakeCVS, func()) {
+	fake := &fakeCVS{Server: fakecvs.NewServer(0, 0), requests: make(map[string]int)}
+	for _, spec := range faults {
+		if err := fake.AddFault(spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path[strings.Index(r.URL.Path, "/locations/")+len("/locations/"):]
+		fake.mutex.Lock()
+		fake.requests[r.Method+" "+uuidPattern.ReplaceAllString(path, "{id}")]++
+		fake.mutex.Unlock()
+		fake.ServeHTTP(w, r)
+	}))
+	client := &Client{
+		Host:         apiHost(server.URL, "123456789"),
+		Project:      "123456789",
+		Token:        "opaque-access-token",
+		PollInterval: time.Millisecond,
+		Backoff:      restapi.Backoff{Base: time.Millisecond, Cap: time.Millisecond},
+	}
+	return client, fake, server.Close
+}
+
+// count returns the number of requests received for a method and path, with IDs replaced by {id}
+func (f *fakeCVS) count(request string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.requests[request]
+}
+
+func newTestVolume(t *testing.T) *schema.ResourceData {
+	return schema.TestResourceDataRaw(t, resourceGCPVolume().Schema, map[string]interface{}{
+		"name":           "vol1",
+		"region":         "us-east4",
+		"protocol_types": []interface{}{"NFSv3"},
+		"network":        "default",
+		"size":           1024,
+		"service_level":  "premium",
+	})
+}
+
+func TestVolumeLifecycle(t *testing.T) {
+	client, fake, stop := newFakeCVS(t)
+	defer stop()
+	resource := resourceGCPVolume()
+	d := newTestVolume(t)
+
+	if err := resource.Create(d, client); err != nil {
+		t.Fatalf("unexpected error creating the volume: %s", err)
+	}
+	if d.Id() == "" || d.Get("lifecycle_state") != "available" || d.Get("size") != 1024 {
+		t.Errorf("expected an available volume of 1024 GiB, got %q, %v, %v", d.Id(), d.Get("lifecycle_state"), d.Get("size"))
+	}
+
+	if err := d.Set("size", 2048); err != nil {
+		t.Fatal(err)
+	}
+	if err := resource.Update(d, client); err != nil {
+		t.Fatalf("unexpected error updating the volume: %s", err)
+	}
+	if err := resource.Read(d, client); err != nil {
+		t.Fatalf("unexpected error reading the volume: %s", err)
+	}
+	if d.Get("size") != 2048 || fake.count("PUT us-east4/Volumes/{id}") != 1 {
+		t.Errorf("expected the volume to be resized with one update, got %v after %d updates", d.Get("size"), fake.count("PUT us-east4/Volumes/{id}"))
+	}
+
+	if err := resource.Delete(d, client); err != nil {
+		t.Fatalf("unexpected error deleting the volume: %s", err)
+	}
+	if err := resource.Read(d, client); err != nil || d.Id() != "" {
+		t.Errorf("expected the volume to be gone, got %q, %v", d.Id(), err)
+	}
+}
+
+func TestVolumeLifecycleRetries(t *testing.T) {
+	// the job limit is retried until the job can be spawned
+	client, fake, stop := newFakeCVS(t, "POST:Volumes:500:2", "DELETE:Volumes:500:1")
+	defer stop()
+	resource := resourceGCPVolume()
+	d := newTestVolume(t)
+
+	if err := resource.Create(d, client); err != nil {
+		t.Fatalf("unexpected error creating the volume: %s", err)
+	}
+	if calls := fake.count("POST us-east4/Volumes"); calls != 3 {
+		t.Errorf("expected the creation to succeed on the third call, got %d calls", calls)
+	}
+	if err := resource.Delete(d, client); err != nil {
+		t.Fatalf("unexpected error deleting the volume: %s", err)
+	}
+	if calls := fake.count("DELETE us-east4/Volumes/{id}"); calls != 2 {
+		t.Errorf("expected the deletion to succeed on the second call, got %d calls", calls)
+	}
+
+	// other errors aren't retried
+	client, fake, stop = newFakeCVS(t, "POST:Volumes:400:1:Error creating volume - Invalid network")
+	defer stop()
+	err := resource.Create(newTestVolume(t), client)
+	if responseError, ok := err.(*restapi.ResponseError); !ok || responseError.Code != 400 || !strings.Contains(responseError.Message, "Invalid network") {
+		t.Errorf("expected the error of the API, got %v", err)
+	}
+	if calls := fake.count("POST us-east4/Volumes"); calls != 1 {
+		t.Errorf("expected a single call, got %d calls", calls)
+	}
+
+	// the retries end with the retries of the rule
+	client, fake, stop = newFakeCVS(t, "GET:Volumes:500:20:Cannot spawn additional jobs")
+	defer stop()
+	d = newTestVolume(t)
+	d.SetId("0a1b2c3d-1234-5678-9abc-def012345678")
+	if err := resource.Read(d, client); err == nil || !strings.Contains(err.Error(), "gave up after 11 attempts") {
+		t.Errorf("expected the retries to be exhausted, got %v", err)
+	}
+}
+
+func TestSnapshotLifecycle(t *testing.T) {
+	client, _, stop := newFakeCVS(t)
+	defer stop()
+	if err := resourceGCPVolume().Create(newTestVolume(t), client); err != nil {
+		t.Fatalf("unexpected error creating the volume: %s", err)
+	}
+
+	resource := resourceGCPSnapshot()
+	d := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		"name":        "snap1",
+		"region":      "us-east4",
+		"volume_name": "vol1",
+	})
+	if err := resource.Create(d, client); err != nil {
+		t.Fatalf("unexpected error creating the snapshot: %s", err)
+	}
+	if err := d.Set("name", "snap2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := resource.Update(d, client); err != nil {
+		t.Fatalf("unexpected error updating the snapshot: %s", err)
+	}
+	if err := resource.Read(d, client); err != nil || d.Get("name") != "snap2" {
+		t.Fatalf("expected the renamed snapshot, got %v, %v", d.Get("name"), err)
+	}
+	if err := resource.Delete(d, client); err != nil {
+		t.Fatalf("unexpected error deleting the snapshot: %s", err)
+	}
+}
+
+func TestVolumeDeleteSnapshots(t *testing.T) {
+	client, fake, stop := newFakeCVS(t)
+	defer stop()
+	resource := resourceGCPVolume()
+	d := newTestVolume(t)
+	if err := d.Set("delete_snapshots_on_destroy", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := resource.Create(d, client); err != nil {
+		t.Fatalf("unexpected error creating the volume: %s", err)
+	}
+	for _, name := range []string{"snap1", "snap2"} {
+		snapshot := schema.TestResourceDataRaw(t, resourceGCPSnapshot().Schema, map[string]interface{}{
+			"name":        name,
+			"region":      "us-east4",
+			"volume_name": "vol1",
+		})
+		if err := resourceGCPSnapshot().Create(snapshot, client); err != nil {
+			t.Fatalf("unexpected error creating the snapshot: %s", err)
+		}
+	}
+
+	if err := resource.Delete(d, client); err != nil {
+		t.Fatalf("unexpected error deleting the volume: %s", err)
+	}
+	if calls := fake.count("DELETE us-east4/Volumes/{id}/Snapshots/{id}"); calls != 2 {
+		t.Errorf("expected both snapshots to be deleted, got %d deletions", calls)
+	}
+	if err := resource.Read(d, client); err != nil || d.Id() != "" {
+		t.Errorf("expected the volume to be gone, got %q, %v", d.Id(), err)
+	}
+}
+
+func TestVolumeExportPolicyFromVolume(t *testing.T) {
+	client, _, stop := newFakeCVS(t)
+	defer stop()
+	resource := resourceGCPVolume()
+	sources := map[string]string{}
+	for _, clients := range []string{"10.0.0.0/8", "192.168.0.0/16"} {
+		source := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+			"name":           "source",
+			"region":         "us-east4",
+			"protocol_types": []interface{}{"NFSv3"},
+			"network":        "default",
+			"size":           1024,
+			"export_policy": []interface{}{map[string]interface{}{
+				"rule": []interface{}{map[string]interface{}{"access": "ReadWrite", "allowed_clients": clients}},
+			}},
+		})
+		if err := resource.Create(source, client); err != nil {
+			t.Fatalf("unexpected error creating the source volume: %s", err)
+		}
+		sources[clients] = source.Id()
+	}
+
+	volume := func(source string) *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+			"name":                         "vol1",
+			"region":                       "us-east4",
+			"protocol_types":               []interface{}{"NFSv3"},
+			"network":                      "default",
+			"size":                         1024,
+			"export_policy_from_volume_id": source,
+		})
+	}
+	created := volume(sources["10.0.0.0/8"])
+	if err := resource.Create(created, client); err != nil {
+		t.Fatalf("unexpected error creating the volume: %s", err)
+	}
+	d := volume(sources["192.168.0.0/16"])
+	d.SetId(created.Id())
+	if err := resource.Update(d, client); err != nil {
+		t.Fatalf("unexpected error updating the volume: %s", err)
+	}
+	res, err := client.getVolumeByID(volumeRequest{Region: "us-east4", VolumeID: d.Id()})
+	if err != nil || len(res.ExportPolicy.Rules) != 1 || res.ExportPolicy.Rules[0].AllowedClients != "192.168.0.0/16" {
+		t.Errorf("expected the rules of the other volume to be copied, got %+v, %v", res.ExportPolicy, err)
+	}
+}
+
+// stubAPI answers every request with the same response
+type stubAPI struct {
+	statusCode int
+	body       string
+	requests   []string
+}
+
+func (s *stubAPI) Do(ctx context.Context, baseURL string, req *restapi.Request) (int, []byte, error) {
+	s.requests = append(s.requests, req.Method+" "+baseURL)
+	return s.statusCode, []byte(s.body), nil
+}
+
+func TestClientAPI(t *testing.T) {
+	api := &stubAPI{statusCode: 200, body: `[{"volumeId": "v1", "name": "vol1", "region": "us-east4"}]`}
+	client := &Client{API: api}
+	volumes, err := client.getVolumeByRegion("us-east4")
+	if err != nil || len(volumes) != 1 || volumes[0].VolumeID != "v1" {
+		t.Errorf("expected the volume of the API, got %v, %v", volumes, err)
+	}
+	if len(api.requests) != 1 || api.requests[0] != "GET us-east4/Volumes" {
+		t.Errorf("expected the request to go through the API, got %v", api.requests)
+	}
+}
@@ -0,0 +1,108 @@
+package gcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/restapi"
+)
+
+// faultsEnvVar enables failure injection for testing the retry paths against a real or fake API, e.g.
+// NETAPP_GCP_FAULTS=create:500:3 fails the next three create calls with code 500.
+const faultsEnvVar = "NETAPP_GCP_FAULTS"
+
+// faultMethods maps the operations of a fault specification to HTTP methods
+var faultMethods = map[string]string{
+	"create": "POST",
+	"read":   "GET",
+	"update": "PUT",
+	"delete": "DELETE",
+}
+
+// fault makes the next count API calls with the method fail with the status and message
+type fault struct {
+	method  string
+	status  int
+	count   int
+	message string
+}
+
+// faultInjector holds the faults configured for the client
+type faultInjector struct {
+	mutex  sync.Mutex
+	faults []*fault
+}
+
+// parseFaults parses a comma separated list of faults of the form operation:status:count[:message], where
+// operation is create, read, update or delete. Without a message, create and delete faults with status 500
+// use the message of the API when it can't spawn more jobs, which the provider retries.
+func parseFaults(specs string) ([]*fault, error) {
+	var faults []*fault
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		parts := strings.SplitN(spec, ":", 4)
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("invalid fault %q, expected operation:status:count[:message]", spec)
+		}
+		method, ok := faultMethods[strings.ToLower(parts[0])]
+		if !ok {
+			return nil, fmt.Errorf("invalid operation in fault %q, expected create, read, update or delete", spec)
+		}
+		status, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid status in fault %q: %v", spec, err)
+		}
+		count, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid count in fault %q: %v", spec, err)
+		}
+		f := &fault{method: method, status: status, count: count, message: "Injected fault"}
+		if len(parts) == 4 {
+			f.message = parts[3]
+		} else if method == "POST" && status == 500 {
+			f.message = spawnJobCreationErrorMessage
+		} else if method == "DELETE" && status == 500 {
+			f.message = spawnJobDeletionErrorMessage
+		}
+		faults = append(faults, f)
+	}
+	return faults, nil
+}
+
+// loadFaults reads the faults from the environment. An invalid specification is logged and ignored.
+func (f *faultInjector) loadFaults() {
+	specs := os.Getenv(faultsEnvVar)
+	if specs == "" {
+		return
+	}
+	faults, err := parseFaults(specs)
+	if err != nil {
+		log.Printf("[WARN] Ignoring %s: %s", faultsEnvVar, err)
+		return
+	}
+	log.Printf("[WARN] Injecting API failures from %s=%s", faultsEnvVar, specs)
+	f.faults = faults
+}
+
+// inject returns the response of a matching fault, if any
+func (f *faultInjector) inject(method string, baseURL string) (int, []byte, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, fault := range f.faults {
+		if fault.count > 0 && fault.method == method {
+			fault.count--
+			log.Printf("[WARN] Injected fault for %s %s: code: %d, message: %s", method, baseURL, fault.status, fault.message)
+			response, _ := json.Marshal(restapi.ResponseError{Code: fault.status, Message: fault.message})
+			return fault.status, response, true
+		}
+	}
+	return 0, nil, false
+}
@@ -0,0 +1,58 @@
+package gcp
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestParseFaults(t *testing.T) {
+	faults, err := parseFaults("create:500:3, delete:500:1,read:503:2:Service unavailable")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []fault{
+		{method: "POST", status: 500, count: 3, message: spawnJobCreationErrorMessage},
+		{method: "DELETE", status: 500, count: 1, message: spawnJobDeletionErrorMessage},
+		{method: "GET", status: 503, count: 2, message: "Service unavailable"},
+	}
+	if len(faults) != len(expected) {
+		t.Fatalf("expected %d faults, got %d", len(expected), len(faults))
+	}
+	for i := range expected {
+		if *faults[i] != expected[i] {
+			t.Errorf("fault %d: expected %+v, got %+v", i, expected[i], *faults[i])
+		}
+	}
+
+	for _, spec := range []string{"create:500", "patch:500:1", "create:five:1", "create:500:x"} {
+		if _, err := parseFaults(spec); err == nil {
+			t.Errorf("expected error for %q, got nil", spec)
+		}
+	}
+}
+
+func TestInjectFaults(t *testing.T) {
+	os.Setenv(faultsEnvVar, "create:500:2")
+	defer os.Unsetenv(faultsEnvVar)
+
+	client := &Client{Host: "http://127.0.0.1:0/"}
+	client.initOnce.Do(client.init)
+	// single calls, CallAPIMethod would retry the faults
+	for i := 0; i < 2; i++ {
+		statusCode, response, err := client.callAPIOnce(context.Background(), "POST", "us-east4/Volumes", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if statusCode != 500 {
+			t.Errorf("expected code 500, got %d", statusCode)
+		}
+		if err := apiResponseChecker(statusCode, response, "createVolume"); err == nil || err.Error() != "code: 500, message: "+spawnJobCreationErrorMessage {
+			t.Errorf("unexpected error response: %v", err)
+		}
+	}
+	// the faults are used up, so the call is sent and fails for lack of credentials
+	if _, _, err := client.CallAPIMethod("POST", "us-east4/Volumes", nil); err == nil {
+		t.Error("expected the third call to be sent to the API")
+	}
+}
@@ -0,0 +1,110 @@
+package gcp
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+)
+
+// backendFeature is an optional endpoint of the API, which not every backend or region serves
+type backendFeature struct {
+	// name is the feature in errors
+	name string
+	// probePath is a list endpoint of the feature in the region, answering 404 if the feature isn't supported
+	probePath string
+}
+
+const (
+	featureBackups         = "backups"
+	featureKMS             = "kms"
+	featureActiveDirectory = "active_directory"
+)
+
+// backendFeatures are the optional features used by the resources of the provider
+var backendFeatures = map[string]backendFeature{
+	featureBackups:         {name: "volume backups", probePath: "Backups"},
+	featureKMS:             {name: "customer-managed encryption keys (KMS)", probePath: "Storage/KmsConfig"},
+	featureActiveDirectory: {name: "Active Directory connections", probePath: "Storage/ActiveDirectory"},
+}
+
+// unsupportedFeatureError is returned by resources using a feature the backend doesn't serve in the region
+type unsupportedFeatureError struct {
+	Feature string
+	Region  string
+}
+
+func (e *unsupportedFeatureError) Error() string {
+	return fmt.Sprintf("the backend does not support %s in region %s", e.Feature, e.Region)
+}
+
+// featureCache remembers the features found supported or unsupported in each region, so each is probed once per run
+type featureCache struct {
+	mutex sync.Mutex
+	// probes are keyed by region and feature
+	probes map[string]*featureProbe
+}
+
+// featureProbe is a probe of a feature in a region, done is closed when it returns. err is nil for supported
+// features.
+type featureProbe struct {
+	done chan struct{}
+	err  error
+}
+
+// requireFeature returns an unsupportedFeatureError if the backend doesn't serve the feature in the region. The
+// feature is probed on first use, once for all concurrent callers. Probes failing otherwise than with a 404 aren't
+// conclusive: the feature is assumed to be supported and probed again on next use, leaving the error to the call of
+// the resource.
+func (c *Client) requireFeature(region string, feature string) error {
+	key := region + "/" + feature
+	c.features.mutex.Lock()
+	if c.features.probes == nil {
+		c.features.probes = make(map[string]*featureProbe)
+	}
+	if probe, ok := c.features.probes[key]; ok {
+		c.features.mutex.Unlock()
+		<-probe.done
+		return probe.err
+	}
+	probe := &featureProbe{done: make(chan struct{})}
+	c.features.probes[key] = probe
+	c.features.mutex.Unlock()
+
+	conclusive := true
+	backendFeature := backendFeatures[feature]
+	statusCode, _, err := c.CallAPIMethod("GET", fmt.Sprintf("%s/%s", region, backendFeature.probePath), nil)
+	switch {
+	case err != nil:
+		log.Printf("[DEBUG] probing %s in %s failed: %s", backendFeature.name, region, err)
+		conclusive = false
+	case statusCode == 404:
+		probe.err = &unsupportedFeatureError{Feature: backendFeature.name, Region: region}
+	case statusCode >= 200 && statusCode < 300:
+		// supported, probe.err stays nil
+	default:
+		log.Printf("[DEBUG] probing %s in %s failed with code %d", backendFeature.name, region, statusCode)
+		conclusive = false
+	}
+	if !conclusive {
+		c.features.mutex.Lock()
+		delete(c.features.probes, key)
+		c.features.mutex.Unlock()
+	}
+	close(probe.done)
+	return probe.err
+}
+
+// discoverFeatures probes all optional features in the region and logs the unsupported ones
+func (c *Client) discoverFeatures(region string) {
+	features := make([]string, 0, len(backendFeatures))
+	for feature := range backendFeatures {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	for _, feature := range features {
+		if err := c.requireFeature(region, feature); err != nil {
+			log.Printf("[INFO] %s", err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package gcp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequireFeature(t *testing.T) {
+	var probes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/us-east4/Backups":
+			fmt.Fprint(w, `[]`)
+		case "/us-east4/Storage/KmsConfig":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"code": 400, "message": "bad request"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"code": 404, "message": "Not found"}`)
+		}
+	}))
+	defer server.Close()
+	client := &Client{Host: server.URL + "/", Token: "opaque-access-token"}
+
+	if err := client.requireFeature("us-east4", featureBackups); err != nil {
+		t.Errorf("expected backups to be supported, got %v", err)
+	}
+	err := client.requireFeature("asia-east1", featureBackups)
+	if _, ok := err.(*unsupportedFeatureError); !ok || err.Error() != "the backend does not support volume backups in region asia-east1" {
+		t.Errorf("expected backups to be unsupported in asia-east1, got %v", err)
+	}
+	// an inconclusive probe doesn't block the resource
+	if err := client.requireFeature("us-east4", featureKMS); err != nil {
+		t.Errorf("expected an inconclusive probe to be ignored, got %v", err)
+	}
+
+	// conclusive probes are made once, inconclusive ones again
+	atomic.StoreInt32(&probes, 0)
+	client.requireFeature("us-east4", featureBackups)
+	client.requireFeature("asia-east1", featureBackups)
+	client.requireFeature("us-east4", featureKMS)
+	if n := atomic.LoadInt32(&probes); n != 1 {
+		t.Errorf("expected only the inconclusive probe to be made again, got %d probes", n)
+	}
+}
+
+func TestRequireFeatureConcurrent(t *testing.T) {
+	var probes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/us-east4/Backups" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"code": 404, "message": "Not found"}`)
+	}))
+	defer server.Close()
+	client := &Client{Host: server.URL + "/", Token: "opaque-access-token"}
+
+	// concurrent callers share the probe of a feature, and probes of different features run at once
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		for _, region := range []string{"us-east4", "asia-east1"} {
+			wg.Add(1)
+			go func(region string) {
+				defer wg.Done()
+				client.requireFeature(region, featureBackups)
+			}(region)
+		}
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&probes); n != 2 {
+		t.Errorf("expected one probe per region, got %d probes", n)
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("expected the probes to run at once, took %s", elapsed)
+	}
+	if _, ok := client.requireFeature("asia-east1", featureBackups).(*unsupportedFeatureError); !ok {
+		t.Error("expected backups to be unsupported in asia-east1")
+	}
+}
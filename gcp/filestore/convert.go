@@ -0,0 +1,165 @@
+package filestore
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MinSizeGiB is the smallest size of a hardware volume. Smaller Filestore instances are converted to this size.
+const MinSizeGiB = 1024
+
+// Instance is the part of a google_filestore_instance that maps to a volume
+type Instance struct {
+	Name string
+	// Location is the zone or region of the instance
+	Location      string
+	Tier          string
+	CapacityGB    int
+	FileShareName string
+	Network       string
+	ExportOptions []ExportOption
+}
+
+// ExportOption is an nfs_export_options block of the file share
+type ExportOption struct {
+	IPRanges   []string
+	AccessMode string
+	SquashMode string
+}
+
+// Volume is the netapp-gcp_volume arguments equivalent to a Filestore instance
+type Volume struct {
+	Name          string
+	Region        string
+	ServiceLevel  string
+	Size          int
+	VolumePath    string
+	Network       string
+	ProtocolTypes []string
+	ExportRules   []ExportRule
+	// Notes explain where the volume differs from the instance
+	Notes []string
+}
+
+// ExportRule is a rule of the export policy of the volume
+type ExportRule struct {
+	Access         string
+	AllowedClients string
+	HasRootAccess  bool
+}
+
+// serviceLevels maps Filestore tiers, including the legacy STANDARD and PREMIUM names, to service levels
+var serviceLevels = map[string]string{
+	"STANDARD":       "standard",
+	"BASIC_HDD":      "standard",
+	"PREMIUM":        "premium",
+	"BASIC_SSD":      "premium",
+	"ZONAL":          "premium",
+	"HIGH_SCALE_SSD": "extreme",
+	"ENTERPRISE":     "extreme",
+	"REGIONAL":       "extreme",
+}
+
+// accessModes maps Filestore access modes to the access of export rules
+var accessModes = map[string]string{
+	"":           "ReadWrite",
+	"READ_WRITE": "ReadWrite",
+	"READ_ONLY":  "ReadOnly",
+}
+
+// zonePattern matches a zone, e.g. us-central1-c, capturing its region
+var zonePattern = regexp.MustCompile(`^([a-z]+-[a-z]+[0-9]+)-[a-z]$`)
+
+// Convert returns the volume arguments equivalent to the Filestore instance. The volume uses NFSv3, the protocol
+// of Filestore.
+func Convert(instance Instance) (Volume, error) {
+	serviceLevel, ok := serviceLevels[strings.ToUpper(instance.Tier)]
+	if !ok {
+		return Volume{}, fmt.Errorf("unknown Filestore tier %q", instance.Tier)
+	}
+	volume := Volume{
+		Name:          instance.Name,
+		Region:        instance.Location,
+		ServiceLevel:  serviceLevel,
+		Size:          instance.CapacityGB,
+		VolumePath:    instance.FileShareName,
+		Network:       instance.Network,
+		ProtocolTypes: []string{"NFSv3"},
+	}
+	if match := zonePattern.FindStringSubmatch(instance.Location); match != nil {
+		volume.Region = match[1]
+		volume.Notes = append(volume.Notes, fmt.Sprintf("zonal instance in %s is converted to a regional volume in %s", instance.Location, volume.Region))
+	}
+	if volume.Size < MinSizeGiB {
+		volume.Size = MinSizeGiB
+		volume.Notes = append(volume.Notes, fmt.Sprintf("capacity of %d GB is raised to the minimum volume size of %d GiB", instance.CapacityGB, MinSizeGiB))
+	}
+	for i, option := range instance.ExportOptions {
+		access, ok := accessModes[strings.ToUpper(option.AccessMode)]
+		if !ok {
+			return Volume{}, fmt.Errorf("unknown access_mode %q of nfs_export_options %d", option.AccessMode, i+1)
+		}
+		rule := ExportRule{
+			Access:         access,
+			AllowedClients: strings.Join(option.IPRanges, ","),
+			HasRootAccess:  true,
+		}
+		switch strings.ToUpper(option.SquashMode) {
+		case "", "NO_ROOT_SQUASH":
+		case "ROOT_SQUASH":
+			rule.HasRootAccess = false
+		default:
+			return Volume{}, fmt.Errorf("unknown squash_mode %q of nfs_export_options %d", option.SquashMode, i+1)
+		}
+		volume.ExportRules = append(volume.ExportRules, rule)
+	}
+	if len(volume.ExportRules) == 0 {
+		volume.Notes = append(volume.Notes, "file share without nfs_export_options is converted to a volume with the default export policy")
+	}
+	return volume, nil
+}
+
+// resourceNamePattern matches the characters not allowed in a Terraform resource name
+var resourceNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// HCL returns a netapp-gcp_volume resource block with the arguments of the volume, named after the volume
+func (v Volume) HCL() string {
+	var b strings.Builder
+	for _, note := range v.Notes {
+		fmt.Fprintf(&b, "# %s\n", note)
+	}
+	name := resourceNamePattern.ReplaceAllString(v.Name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "volume_" + name
+	}
+	fmt.Fprintf(&b, "resource \"netapp-gcp_volume\" %s {\n", strconv.Quote(name))
+	fmt.Fprintf(&b, "  name           = %s\n", strconv.Quote(v.Name))
+	fmt.Fprintf(&b, "  region         = %s\n", strconv.Quote(v.Region))
+	protocols := make([]string, len(v.ProtocolTypes))
+	for i, protocol := range v.ProtocolTypes {
+		protocols[i] = strconv.Quote(protocol)
+	}
+	fmt.Fprintf(&b, "  protocol_types = [%s]\n", strings.Join(protocols, ", "))
+	fmt.Fprintf(&b, "  network        = %s\n", strconv.Quote(v.Network))
+	fmt.Fprintf(&b, "  size           = %d\n", v.Size)
+	fmt.Fprintf(&b, "  service_level  = %s\n", strconv.Quote(v.ServiceLevel))
+	if v.VolumePath != "" {
+		fmt.Fprintf(&b, "  volume_path    = %s\n", strconv.Quote(v.VolumePath))
+	}
+	if len(v.ExportRules) > 0 {
+		b.WriteString("\n  export_policy {\n")
+		for _, rule := range v.ExportRules {
+			b.WriteString("    rule {\n")
+			fmt.Fprintf(&b, "      access          = %s\n", strconv.Quote(rule.Access))
+			fmt.Fprintf(&b, "      allowed_clients = %s\n", strconv.Quote(rule.AllowedClients))
+			fmt.Fprintf(&b, "      has_root_access = %t\n", rule.HasRootAccess)
+			b.WriteString("      nfsv3 {\n        checked = true\n      }\n")
+			b.WriteString("    }\n")
+		}
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
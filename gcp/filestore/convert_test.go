@@ -0,0 +1,99 @@
+package filestore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	volume, err := Convert(Instance{
+		Name:          "shared-data",
+		Location:      "us-central1-c",
+		Tier:          "BASIC_SSD",
+		CapacityGB:    2560,
+		FileShareName: "data",
+		Network:       "default",
+		ExportOptions: []ExportOption{
+			{IPRanges: []string{"10.0.0.0/24", "10.0.1.0/24"}, AccessMode: "READ_WRITE", SquashMode: "NO_ROOT_SQUASH"},
+			{IPRanges: []string{"10.1.0.0/16"}, AccessMode: "READ_ONLY", SquashMode: "ROOT_SQUASH"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if volume.Region != "us-central1" || volume.ServiceLevel != "premium" || volume.Size != 2560 || volume.VolumePath != "data" {
+		t.Errorf("unexpected volume %+v", volume)
+	}
+	expected := []ExportRule{
+		{Access: "ReadWrite", AllowedClients: "10.0.0.0/24,10.0.1.0/24", HasRootAccess: true},
+		{Access: "ReadOnly", AllowedClients: "10.1.0.0/16", HasRootAccess: false},
+	}
+	if len(volume.ExportRules) != len(expected) {
+		t.Fatalf("expected %d export rules, got %d", len(expected), len(volume.ExportRules))
+	}
+	for i, rule := range volume.ExportRules {
+		if rule != expected[i] {
+			t.Errorf("export rule %d: expected %+v, got %+v", i, expected[i], rule)
+		}
+	}
+	if len(volume.Notes) != 1 {
+		t.Errorf("expected a note for the zonal instance, got %v", volume.Notes)
+	}
+}
+
+func TestConvertMinimumSize(t *testing.T) {
+	volume, err := Convert(Instance{Name: "small", Location: "us-east4", Tier: "zonal", CapacityGB: 100, Network: "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if volume.Region != "us-east4" || volume.Size != MinSizeGiB {
+		t.Errorf("unexpected volume %+v", volume)
+	}
+	// raised size and default export policy
+	if len(volume.Notes) != 2 {
+		t.Errorf("expected 2 notes, got %v", volume.Notes)
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	instances := []Instance{
+		{Name: "unknown-tier", Location: "us-east4", Tier: "ARCHIVE", CapacityGB: 1024},
+		{Name: "unknown-access", Location: "us-east4", Tier: "BASIC_HDD", CapacityGB: 1024, ExportOptions: []ExportOption{{AccessMode: "WRITE_ONLY"}}},
+		{Name: "unknown-squash", Location: "us-east4", Tier: "BASIC_HDD", CapacityGB: 1024, ExportOptions: []ExportOption{{SquashMode: "ALL_SQUASH"}}},
+	}
+	for _, instance := range instances {
+		if _, err := Convert(instance); err == nil {
+			t.Errorf("expected an error for %s", instance.Name)
+		}
+	}
+}
+
+func TestVolumeHCL(t *testing.T) {
+	volume := Volume{
+		Name:          "1-data.share",
+		Region:        "us-east4",
+		ServiceLevel:  "standard",
+		Size:          1024,
+		Network:       "default",
+		ProtocolTypes: []string{"NFSv3"},
+		ExportRules:   []ExportRule{{Access: "ReadOnly", AllowedClients: "10.0.0.0/8"}},
+		Notes:         []string{"a note"},
+	}
+	hcl := volume.HCL()
+	for _, expected := range []string{
+		"# a note\n",
+		`resource "netapp-gcp_volume" "volume_1-data_share" {`,
+		`  name           = "1-data.share"`,
+		`  protocol_types = ["NFSv3"]`,
+		`  size           = 1024`,
+		`      allowed_clients = "10.0.0.0/8"`,
+		`      has_root_access = false`,
+	} {
+		if !strings.Contains(hcl, expected) {
+			t.Errorf("expected %q in:\n%s", expected, hcl)
+		}
+	}
+	if strings.Contains(hcl, "volume_path") {
+		t.Errorf("expected no volume_path in:\n%s", hcl)
+	}
+}
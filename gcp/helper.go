@@ -1,34 +1,71 @@
 package gcp
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"log"
 	"math/rand"
+	"strings"
+
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/restapi"
 )
 
-type apiErrorResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+// apiResponseChecker returns the error of the response if the HTTP request didn't succeed, nil otherwise
+func apiResponseChecker(statusCode int, response []byte, funcName string) *restapi.ResponseError {
+	if statusCode >= 300 || statusCode < 200 {
+		log.Printf("%s request failed", funcName)
+		return restapi.ParseResponseError(statusCode, response, funcName)
+	}
+	return nil
 }
 
-// Check HTTP response code, return error if HTTP request is not successed.
-func apiResponseChecker(statusCode int, response []byte, funcName string) error {
+// decodeResponse unmarshals an API response into v. If that fails, the error says where the response came from
+// and what it looked like, as it is often an error page from a proxy rather than an API response.
+func decodeResponse(response []byte, v interface{}, statusCode int, baseURL string, funcName string) error {
+	if err := json.Unmarshal(response, v); err != nil {
+		log.Printf("Failed to unmarshall response from %s", funcName)
+		return fmt.Errorf("failed to decode response from %s (%s), code: %d, error: %v, response: %s",
+			funcName, baseURL, statusCode, err, restapi.BodySnippet(response))
+	}
+	return nil
+}
 
-	if statusCode >= 300 || statusCode < 200 {
-		log.Printf("%s request failed", funcName)
-		var errorResponse apiErrorResponse
-		responseContent := bytes.NewBuffer(response).String()
-		if err := json.Unmarshal(response, &errorResponse); err != nil {
-			log.Printf("Failed to unmarshall error response from %s", funcName)
-			return fmt.Errorf(responseContent)
+// quotaErrorPatterns are fragments of API error messages that report exhausted quota or capacity.
+// Retrying these errors doesn't help, so they are reported right away. The fragments are whole phrases, since
+// other errors mention the quota of a volume (quotaInBytes) or a rate limit.
+var quotaErrorPatterns = []string{
+	"quota exceeded",
+	"insufficient capacity",
+	"not enough capacity",
+	"capacity exceeded",
+	"maximum number of volumes",
+}
+
+// isQuotaError checks whether an API error message reports exhausted quota or capacity
+func isQuotaError(message string) bool {
+	message = strings.ToLower(message)
+	for _, pattern := range quotaErrorPatterns {
+		if strings.Contains(message, pattern) {
+			return true
 		}
-		return fmt.Errorf("code: %d, message: %s", errorResponse.Code, errorResponse.Message)
 	}
+	return false
+}
 
-	return nil
+// isJobLimitError checks whether an API error reports that the job limit of the project is reached, e.g.
+// spawnJobCreationErrorMessage
+func isJobLimitError(err *restapi.ResponseError) bool {
+	return err.Code == 500 && strings.Contains(err.Message, "Cannot spawn additional jobs")
+}
+
+// isBackendTimeoutError checks whether an API error reports that the backend timed out calling one of its
+// services, e.g. contextDeadlineExceededErrorMessage
+func isBackendTimeoutError(err *restapi.ResponseError) bool {
+	return err.Code == 500 && strings.HasSuffix(err.Message, "context deadline exceeded")
+}
 
+func quotaExceededError(region string, message string) error {
+	return fmt.Errorf("quota exceeded in region %s: %s", region, message)
 }
 
 func nextRandomInt(min int, max int) int {
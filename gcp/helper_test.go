@@ -0,0 +1,68 @@
+package gcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/restapi"
+)
+
+func TestIsQuotaError(t *testing.T) {
+	cases := []struct {
+		message  string
+		expected bool
+	}{
+		{"Error creating volume - Quota exceeded for resource 'volumes' in region us-east4", true},
+		{"Error creating volume - Insufficient capacity in pool", true},
+		{"Error updating volume - Requested quota is smaller than used capacity", false},
+		{"Error creating volume - quotaInBytes must be at least 1099511627776", false},
+		{"Error creating volume - Rate limit exceeded, try again later", false},
+		{spawnJobCreationErrorMessage, false},
+		{contextDeadlineExceededErrorMessage, false},
+	}
+	for _, tc := range cases {
+		if result := isQuotaError(tc.message); result != tc.expected {
+			t.Errorf("isQuotaError(%q) = %v, expected %v", tc.message, result, tc.expected)
+		}
+	}
+}
+
+func TestRetryRuleErrors(t *testing.T) {
+	parse := func(body string) *restapi.ResponseError {
+		return restapi.ParseResponseError(500, []byte(body), "createVolume")
+	}
+	jobLimit := parse(`{"code": 500, "message": "` + spawnJobDeletionErrorMessage + `"}`)
+	timeout := parse(`{"code": 500, "message": "` + contextDeadlineExceededErrorMessage + `"}`)
+	// the URL of the service differs between deployments
+	otherTimeout := parse(`{"code": 500, "message": "Post https://cvs.internal.example/v2/Volumes: context deadline exceeded"}`)
+	other := parse(`{"code": 500, "message": "internal error"}`)
+
+	if !isJobLimitError(jobLimit) || isJobLimitError(timeout) || isJobLimitError(other) {
+		t.Error("expected only the job limit error to match isJobLimitError")
+	}
+	if !isBackendTimeoutError(timeout) || !isBackendTimeoutError(otherTimeout) || isBackendTimeoutError(jobLimit) || isBackendTimeoutError(other) {
+		t.Error("expected only the timeouts to match isBackendTimeoutError")
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	page := "<html>\n<body>502 Bad Gateway</body>\n</html>" + strings.Repeat(" ", 1024)
+	var result volumeResult
+	err := decodeResponse([]byte(page), &result, 502, "us-east4/Volumes", "getVolumeByRegion")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	message := err.Error()
+	for _, expected := range []string{"getVolumeByRegion", "us-east4/Volumes", "code: 502", "<html> <body>502 Bad Gateway"} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("expected %q in error, got %q", expected, message)
+		}
+	}
+	if len(message) > 700 {
+		t.Errorf("expected response to be truncated, got %d characters", len(message))
+	}
+
+	if err := decodeResponse([]byte(`{"name": "vol1"}`), &result, 200, "us-east4/Volumes", "getVolumeByRegion"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,75 @@
+package gcp
+
+import (
+	"log"
+	"sync"
+)
+
+// jobQueue limits the number of jobs of an operation running at once in each region, so a destroy of many
+// volumes doesn't trip the job spawn limit of the API and spend its time in retries. Waiting callers are let
+// in the order they arrived. Jobs can be keyed by volume ID instead of region, e.g. for snapshot operations, or by
+// project for the jobs of the whole tenant.
+type jobQueue struct {
+	// limit is the number of jobs running at once per region. The queue is disabled if it is 0.
+	limit   int
+	mutex   sync.Mutex
+	regions map[string]*regionJobs
+}
+
+// regionJobs are the running and waiting jobs of a region
+type regionJobs struct {
+	running int
+	waiting []chan struct{}
+}
+
+// acquire blocks until a job can run in the region and returns the function releasing it when the job is done
+func (q *jobQueue) acquire(region string, operation string) func() {
+	if q.limit == 0 {
+		return func() {}
+	}
+	q.mutex.Lock()
+	if q.regions == nil {
+		q.regions = make(map[string]*regionJobs)
+	}
+	jobs, ok := q.regions[region]
+	if !ok {
+		jobs = &regionJobs{}
+		q.regions[region] = jobs
+	}
+	if jobs.running < q.limit && len(jobs.waiting) == 0 {
+		jobs.running++
+		q.mutex.Unlock()
+	} else {
+		ready := make(chan struct{})
+		jobs.waiting = append(jobs.waiting, ready)
+		log.Printf("[DEBUG] %s in %s is queued behind %d running and %d waiting jobs", operation, region, jobs.running, len(jobs.waiting)-1)
+		q.mutex.Unlock()
+		<-ready
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { q.release(region) })
+	}
+}
+
+// acquireJob blocks until a job creating, updating or deleting a resource can run within the max_concurrent_jobs of
+// the project, and returns the function releasing it when the job is done
+func (c *Client) acquireJob(operation string) func() {
+	c.initOnce.Do(c.init)
+	return c.jobs.acquire("project "+c.Project, operation)
+}
+
+// release hands the slot of a finished job to the first waiting caller of the region
+func (q *jobQueue) release(region string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	jobs := q.regions[region]
+	if len(jobs.waiting) == 0 {
+		jobs.running--
+		return
+	}
+	ready := jobs.waiting[0]
+	jobs.waiting = jobs.waiting[1:]
+	close(ready)
+}
@@ -0,0 +1,99 @@
+package gcp
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJobQueueLimit(t *testing.T) {
+	queue := jobQueue{limit: 2}
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release := queue.acquire("us-east4", "deleteVolume")
+			defer release()
+			mutex.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mutex.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+	if maxRunning != 2 {
+		t.Errorf("expected 2 jobs running at once, got %d", maxRunning)
+	}
+	// regions are limited separately, a full region doesn't block another one
+	queue.acquire("us-east4", "deleteVolume")
+	queue.acquire("us-east4", "deleteVolume")
+	queue.acquire("us-west2", "deleteVolume")
+}
+
+func TestJobQueueOrder(t *testing.T) {
+	queue := jobQueue{limit: 1}
+	release := queue.acquire("us-east4", "deleteVolume")
+	order := make(chan int, 5)
+	for i := 0; i < 5; i++ {
+		go func(i int) {
+			queue.acquire("us-east4", "deleteVolume")()
+			order <- i
+		}(i)
+		// wait for the caller to be queued before starting the next one
+		for {
+			queue.mutex.Lock()
+			waiting := len(queue.regions["us-east4"].waiting)
+			queue.mutex.Unlock()
+			if waiting == i+1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	release()
+	for i := 0; i < 5; i++ {
+		if got := <-order; got != i {
+			t.Fatalf("expected caller %d to run, got %d", i, got)
+		}
+	}
+	if running := queue.regions["us-east4"].running; running != 0 {
+		t.Errorf("expected no running jobs, got %d", running)
+	}
+}
+
+func TestJobQueueDisabled(t *testing.T) {
+	queue := jobQueue{}
+	for i := 0; i < 10; i++ {
+		queue.acquire("us-east4", "deleteVolume")
+	}
+}
+
+func TestAcquireJob(t *testing.T) {
+	client := &Client{Project: "123456", MaxConcurrentJobs: 1}
+	release := client.acquireJob("createVolume")
+	acquired := make(chan struct{})
+	go func() {
+		defer client.acquireJob("deleteSnapshot")()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("expected the job to wait for the running one")
+	case <-time.After(20 * time.Millisecond):
+	}
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("expected the job to run after the running one was released")
+	}
+}
@@ -0,0 +1,80 @@
+package gcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// journalEntry is a line of the operation journal, recording a mutating API call
+type journalEntry struct {
+	Time        string `json:"time"`
+	Operation   string `json:"operation"`
+	Resource    string `json:"resource"`
+	RequestHash string `json:"request_hash"`
+	StatusCode  int    `json:"status_code"`
+	Result      string `json:"result"`
+	Error       string `json:"error,omitempty"`
+	DurationMs  int64  `json:"duration_ms"`
+}
+
+// operationJournal appends a JSON line per mutating API call to a file, for auditing applies without parsing
+// the Terraform logs. The journal is disabled if path is empty.
+type operationJournal struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// requestHash returns the SHA-256 of the JSON encoded request parameters, so the journal can tell requests
+// apart without recording secrets such as Active Directory passwords
+func requestHash(params interface{}) string {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// record writes an entry for a mutating call. Failing to write the journal is logged and doesn't fail the call.
+func (j *operationJournal) record(method string, baseURL string, params interface{}, statusCode int, err error, duration time.Duration) {
+	if j.path == "" || method == "GET" {
+		return
+	}
+	entry := journalEntry{
+		Time:        time.Now().UTC().Format(time.RFC3339),
+		Operation:   method,
+		Resource:    baseURL,
+		RequestHash: requestHash(params),
+		StatusCode:  statusCode,
+		Result:      "success",
+		DurationMs:  int64(duration / time.Millisecond),
+	}
+	if err != nil {
+		entry.Result = "error"
+		entry.Error = err.Error()
+	} else if statusCode >= 300 {
+		entry.Result = "failure"
+	}
+	line, jsonErr := json.Marshal(entry)
+	if jsonErr != nil {
+		log.Printf("[WARN] Failed to encode journal entry for %s %s: %s", method, baseURL, jsonErr)
+		return
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	file, fileErr := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if fileErr != nil {
+		log.Printf("[WARN] Failed to open journal %s: %s", j.path, fileErr)
+		return
+	}
+	defer file.Close()
+	if _, fileErr := file.Write(append(line, '\n')); fileErr != nil {
+		log.Printf("[WARN] Failed to write journal %s: %s", j.path, fileErr)
+	}
+}
@@ -0,0 +1,57 @@
+package gcp
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOperationJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	journal := operationJournal{path: filepath.Join(dir, "journal.jsonl")}
+	params := map[string]interface{}{"name": "vol1", "password": "secret"}
+	journal.record("GET", "us-east4/Volumes", nil, 200, nil, time.Second)
+	journal.record("POST", "us-east4/Volumes", params, 202, nil, 1500*time.Millisecond)
+	journal.record("DELETE", "us-east4/Volumes/1234", nil, 500, nil, time.Second)
+	journal.record("PUT", "us-east4/Volumes/1234", params, 0, errors.New("connection refused"), time.Second)
+
+	content, err := ioutil.ReadFile(journal.path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Contains(string(content), "secret") {
+		t.Error("journal contains request parameters")
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	expected := []journalEntry{
+		{Operation: "POST", Resource: "us-east4/Volumes", RequestHash: requestHash(params), StatusCode: 202, Result: "success", DurationMs: 1500},
+		{Operation: "DELETE", Resource: "us-east4/Volumes/1234", RequestHash: requestHash(nil), StatusCode: 500, Result: "failure", DurationMs: 1000},
+		{Operation: "PUT", Resource: "us-east4/Volumes/1234", RequestHash: requestHash(params), Result: "error", Error: "connection refused", DurationMs: 1000},
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d journal entries, got %d", len(expected), len(lines))
+	}
+	for i, line := range lines {
+		var entry journalEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if entry.Time == "" {
+			t.Errorf("entry %d: expected a time", i)
+		}
+		entry.Time = ""
+		if entry != expected[i] {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entry)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package gcp
+
+import (
+	"fmt"
+	"log"
+)
+
+// kmsConfigRequest the user's input for registering a Cloud KMS key with the service
+type kmsConfigRequest struct {
+	Region          string `json:"region"`
+	KeyRingLocation string `json:"keyRingLocation"`
+	KeyRing         string `json:"keyRing"`
+	KeyName         string `json:"keyName"`
+	KeyProjectID    string `json:"keyProjectID"`
+	Network         string `json:"network"`
+}
+
+// kmsConfigResult the api response for a KMS config
+type kmsConfigResult struct {
+	UUID            string `json:"uuid"`
+	KeyRingLocation string `json:"keyRingLocation"`
+	KeyRing         string `json:"keyRing"`
+	KeyName         string `json:"keyName"`
+	KeyProjectID    string `json:"keyProjectID"`
+	Network         string `json:"network"`
+	State           string `json:"state"`
+	StateDetails    string `json:"stateDetails"`
+}
+
+func (c *Client) createKMSConfig(request kmsConfigRequest) (kmsConfigResult, error) {
+	baseURL := fmt.Sprintf("%s/Storage/KmsConfig", request.Region)
+	statusCode, response, err := c.CallAPIMethod("POST", baseURL, request)
+	if err != nil {
+		log.Print("createKMSConfig request failed")
+		return kmsConfigResult{}, err
+	}
+
+	responseError := apiResponseChecker(statusCode, response, "createKMSConfig")
+	if responseError != nil {
+		return kmsConfigResult{}, responseError
+	}
+
+	var result kmsConfigResult
+	if err := decodeResponse(response, &result, statusCode, baseURL, "createKMSConfig"); err != nil {
+		return kmsConfigResult{}, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) listKMSConfigsForRegion(region string) ([]kmsConfigResult, error) {
+	baseURL := fmt.Sprintf("%s/Storage/KmsConfig", region)
+	statusCode, response, err := c.CallAPIMethod("GET", baseURL, nil)
+	if err != nil {
+		log.Print("listKMSConfigsForRegion request failed")
+		return nil, err
+	}
+
+	responseError := apiResponseChecker(statusCode, response, "listKMSConfigsForRegion")
+	if responseError != nil {
+		return nil, responseError
+	}
+
+	var result []kmsConfigResult
+	if err := decodeResponse(response, &result, statusCode, baseURL, "listKMSConfigsForRegion"); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// getKMSConfigByID returns the KMS config with the ID, or an empty result if the region has none
+func (c *Client) getKMSConfigByID(region string, id string) (kmsConfigResult, error) {
+	configs, err := c.listKMSConfigsForRegion(region)
+	if err != nil {
+		return kmsConfigResult{}, err
+	}
+	for _, config := range configs {
+		if config.UUID == id {
+			return config, nil
+		}
+	}
+
+	return kmsConfigResult{}, nil
+}
+
+func (c *Client) deleteKMSConfig(region string, id string) error {
+	baseURL := fmt.Sprintf("%s/Storage/KmsConfig/%s", region, id)
+	statusCode, response, err := c.CallAPIMethod("DELETE", baseURL, nil)
+	if err != nil {
+		log.Print("deleteKMSConfig request failed")
+		return err
+	}
+
+	responseError := apiResponseChecker(statusCode, response, "deleteKMSConfig")
+	if responseError != nil {
+		return responseError
+	}
+
+	return nil
+}
+
+// findKMSConfig returns the KMS config of the region registering the key, or an empty result if the key isn't registered
+func (c *Client) findKMSConfig(region string, keyRing string, keyName string) (kmsConfigResult, error) {
+	configs, err := c.listKMSConfigsForRegion(region)
+	if err != nil {
+		return kmsConfigResult{}, err
+	}
+	for _, config := range configs {
+		if config.KeyRing == keyRing && config.KeyName == keyName {
+			return config, nil
+		}
+	}
+
+	return kmsConfigResult{}, nil
+}
@@ -0,0 +1,54 @@
+package gcp
+
+import (
+	"os"
+	"strings"
+)
+
+// the keys of the labels added by auto_labeling, so Read can tell them from the configured labels
+const (
+	autoLabelManaged   = "terraform-managed"
+	autoLabelWorkspace = "terraform-workspace"
+)
+
+// autoLabels returns the ownership labels auto_labeling adds to every volume. Terraform doesn't pass the module
+// path or the resource address to providers, so the labels carry what the provider can tell: that Terraform manages
+// the volume and the workspace selected with TF_WORKSPACE.
+func autoLabels() []string {
+	workspace := os.Getenv("TF_WORKSPACE")
+	if workspace == "" {
+		workspace = "default"
+	}
+	return []string{autoLabelManaged + ":true", autoLabelWorkspace + ":" + workspace}
+}
+
+// withAutoLabels returns the labels followed by the auto labels they don't contain yet
+func withAutoLabels(labels []string, auto []string) []string {
+	result := append([]string{}, labels...)
+	for _, label := range auto {
+		found := false
+		for _, existing := range labels {
+			if existing == label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, label)
+		}
+	}
+	return result
+}
+
+// stripAutoLabels returns the labels without the ones added by auto_labeling. The labels are matched by key, as the
+// workspace of a volume can differ from the current one.
+func stripAutoLabels(labels []string) []string {
+	result := make([]string, 0, len(labels))
+	for _, label := range labels {
+		key := strings.SplitN(label, ":", 2)[0]
+		if key != autoLabelManaged && key != autoLabelWorkspace {
+			result = append(result, label)
+		}
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package gcp
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestAutoLabels(t *testing.T) {
+	workspace, ok := os.LookupEnv("TF_WORKSPACE")
+	defer func() {
+		if ok {
+			os.Setenv("TF_WORKSPACE", workspace)
+		} else {
+			os.Unsetenv("TF_WORKSPACE")
+		}
+	}()
+
+	os.Unsetenv("TF_WORKSPACE")
+	if labels := fmt.Sprint(autoLabels()); labels != "[terraform-managed:true terraform-workspace:default]" {
+		t.Errorf("unexpected labels %s", labels)
+	}
+	os.Setenv("TF_WORKSPACE", "prod")
+	labels := withAutoLabels([]string{"team:storage", "terraform-managed:true"}, autoLabels())
+	if fmt.Sprint(labels) != "[team:storage terraform-managed:true terraform-workspace:prod]" {
+		t.Errorf("unexpected labels %v", labels)
+	}
+	if stripped := fmt.Sprint(stripAutoLabels(labels)); stripped != "[team:storage]" {
+		t.Errorf("unexpected labels %s", stripped)
+	}
+	labels = []string{"terraform-owner:platform", "terraform-workspace:staging", "terraform-managed-by:ci"}
+	if stripped := fmt.Sprint(stripAutoLabels(labels)); stripped != "[terraform-owner:platform terraform-managed-by:ci]" {
+		t.Errorf("unexpected labels %s", stripped)
+	}
+}
@@ -0,0 +1,53 @@
+package gcp
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var uuidPattern = regexp.MustCompile("[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}")
+
+// apiLatencies keeps the duration of every API call per endpoint during a run
+type apiLatencies struct {
+	mutex   sync.Mutex
+	samples map[string][]time.Duration
+}
+
+// record adds a sample for the endpoint and returns the p50 and p95 latencies seen so far
+func (l *apiLatencies) record(endpoint string, duration time.Duration) (time.Duration, time.Duration, int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.samples == nil {
+		l.samples = make(map[string][]time.Duration)
+	}
+	l.samples[endpoint] = append(l.samples[endpoint], duration)
+	sorted := make([]time.Duration, len(l.samples[endpoint]))
+	copy(sorted, l.samples[endpoint])
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return percentile(sorted, 0.50), percentile(sorted, 0.95), len(sorted)
+}
+
+// percentile returns the nearest-rank percentile of an ascending list of durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// latencyEndpoint groups calls by method and URL shape, e.g. "GET {region}/Volumes/{id}"
+func latencyEndpoint(method string, baseURL string) string {
+	path := uuidPattern.ReplaceAllString(baseURL, "{id}")
+	if index := strings.Index(path, "/"); index > -1 {
+		path = "{region}" + path[index:]
+	}
+	return method + " " + path
+}
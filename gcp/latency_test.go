@@ -0,0 +1,31 @@
+package gcp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyEndpoint(t *testing.T) {
+	endpoint := latencyEndpoint("GET", "us-east4/Volumes/0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10/Snapshots")
+	if endpoint != "GET {region}/Volumes/{id}/Snapshots" {
+		t.Errorf("unexpected endpoint: %s", endpoint)
+	}
+}
+
+func TestAPILatenciesRecord(t *testing.T) {
+	var latencies apiLatencies
+	var p50, p95 time.Duration
+	var count int
+	for i := 1; i <= 20; i++ {
+		p50, p95, count = latencies.record("GET {region}/Volumes", time.Duration(i)*time.Second)
+	}
+	if count != 20 {
+		t.Errorf("expected 20 samples, got %d", count)
+	}
+	if p50 != 10*time.Second {
+		t.Errorf("expected p50 of 10s, got %s", p50)
+	}
+	if p95 != 19*time.Second {
+		t.Errorf("expected p95 of 19s, got %s", p95)
+	}
+}
@@ -0,0 +1,156 @@
+package gcp
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// metricsEnvVar enables a Prometheus metrics listener on a loopback address, e.g.
+// NETAPP_GCP_METRICS_ADDR=127.0.0.1:9464, for monitoring the provider when it's driven by automation
+const metricsEnvVar = "NETAPP_GCP_METRICS_ADDR"
+
+// apiMetrics counts the API calls of the provider process. It is shared by the clients of every provider
+// configuration, as a process serves a single metrics listener.
+var apiMetrics providerMetrics
+
+var metricsListenerOnce sync.Once
+
+// apiCall labels the API calls counted by providerMetrics
+type apiCall struct {
+	method   string
+	endpoint string
+	code     int
+}
+
+// providerMetrics counts API calls by endpoint and status code, errors and retries by rule
+type providerMetrics struct {
+	mutex   sync.Mutex
+	calls   map[apiCall]int
+	errors  map[apiCall]int
+	retries map[string]int
+}
+
+// recordCall counts an API call. Calls failing without a response or with a status code of 400 or more are also
+// counted as errors, by endpoint.
+func (m *providerMetrics) recordCall(method string, baseURL string, statusCode int, err error) {
+	endpoint := strings.TrimPrefix(latencyEndpoint(method, baseURL), method+" ")
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.calls == nil {
+		m.calls = make(map[apiCall]int)
+		m.errors = make(map[apiCall]int)
+	}
+	m.calls[apiCall{method: method, endpoint: endpoint, code: statusCode}]++
+	if err != nil || statusCode >= 400 {
+		m.errors[apiCall{method: method, endpoint: endpoint}]++
+	}
+}
+
+// recordRetry counts a retry of a call failing with the transient error of the rule
+func (m *providerMetrics) recordRetry(rule string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.retries == nil {
+		m.retries = make(map[string]int)
+	}
+	m.retries[rule]++
+}
+
+// write writes the metrics in the Prometheus text format
+func (m *providerMetrics) write(w io.Writer) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	fmt.Fprintln(w, "# HELP netapp_gcp_api_calls_total API calls by method, endpoint and status code, 0 for calls without a response.")
+	fmt.Fprintln(w, "# TYPE netapp_gcp_api_calls_total counter")
+	for _, call := range sortedCalls(m.calls) {
+		fmt.Fprintf(w, "netapp_gcp_api_calls_total{method=%q,endpoint=%q,code=\"%d\"} %d\n", call.method, call.endpoint, call.code, m.calls[call])
+	}
+	fmt.Fprintln(w, "# HELP netapp_gcp_api_errors_total API calls failing without a response or with a status code of 400 or more.")
+	fmt.Fprintln(w, "# TYPE netapp_gcp_api_errors_total counter")
+	for _, call := range sortedCalls(m.errors) {
+		fmt.Fprintf(w, "netapp_gcp_api_errors_total{method=%q,endpoint=%q} %d\n", call.method, call.endpoint, m.errors[call])
+	}
+	fmt.Fprintln(w, "# HELP netapp_gcp_api_retries_total Retries of API calls failing with a transient error, by error.")
+	fmt.Fprintln(w, "# TYPE netapp_gcp_api_retries_total counter")
+	rules := make([]string, 0, len(m.retries))
+	for rule := range m.retries {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		fmt.Fprintf(w, "netapp_gcp_api_retries_total{error=%q} %d\n", rule, m.retries[rule])
+	}
+}
+
+func (m *providerMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.write(w)
+}
+
+func sortedCalls(counts map[apiCall]int) []apiCall {
+	calls := make([]apiCall, 0, len(counts))
+	for call := range counts {
+		calls = append(calls, call)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		if calls[i].endpoint != calls[j].endpoint {
+			return calls[i].endpoint < calls[j].endpoint
+		}
+		if calls[i].method != calls[j].method {
+			return calls[i].method < calls[j].method
+		}
+		return calls[i].code < calls[j].code
+	})
+	return calls
+}
+
+// validateMetricsAddress checks that the metrics listener address is on a loopback interface, as the metrics
+// name the API paths of the project and have no authentication
+func validateMetricsAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %q, expected host:port: %v", address, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("address %q is not a loopback address, expected e.g. 127.0.0.1:9464", address)
+	}
+	return nil
+}
+
+// startMetricsListener serves the metrics at /metrics on the address of the environment, once per process. An
+// invalid address or failing listener is logged and doesn't fail the provider.
+func startMetricsListener() {
+	metricsListenerOnce.Do(func() {
+		address := os.Getenv(metricsEnvVar)
+		if address == "" {
+			return
+		}
+		if err := validateMetricsAddress(address); err != nil {
+			log.Printf("[WARN] Ignoring %s: %s", metricsEnvVar, err)
+			return
+		}
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			log.Printf("[WARN] Ignoring %s: %s", metricsEnvVar, err)
+			return
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", &apiMetrics)
+		log.Printf("[INFO] Serving metrics at http://%s/metrics", listener.Addr())
+		go func() {
+			if err := http.Serve(listener, mux); err != nil {
+				log.Printf("[WARN] Metrics listener stopped: %s", err)
+			}
+		}()
+	})
+}
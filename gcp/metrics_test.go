@@ -0,0 +1,50 @@
+package gcp
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestProviderMetrics(t *testing.T) {
+	var metrics providerMetrics
+	metrics.recordCall("GET", "us-east4/Volumes/6f3a2b1c-1234-5678-9abc-def012345678", 200, nil)
+	metrics.recordCall("GET", "us-east4/Volumes/0a1b2c3d-1234-5678-9abc-def012345678", 200, nil)
+	metrics.recordCall("POST", "us-east4/Volumes", 500, nil)
+	metrics.recordCall("POST", "us-east4/Volumes", 0, errors.New("connection reset"))
+	metrics.recordRetry("the job limit")
+
+	var out bytes.Buffer
+	metrics.write(&out)
+	for _, line := range []string{
+		`netapp_gcp_api_calls_total{method="GET",endpoint="{region}/Volumes/{id}",code="200"} 2`,
+		`netapp_gcp_api_calls_total{method="POST",endpoint="{region}/Volumes",code="0"} 1`,
+		`netapp_gcp_api_calls_total{method="POST",endpoint="{region}/Volumes",code="500"} 1`,
+		`netapp_gcp_api_errors_total{method="POST",endpoint="{region}/Volumes"} 2`,
+		`netapp_gcp_api_retries_total{error="the job limit"} 1`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("expected %s in the metrics, got:\n%s", line, out.String())
+		}
+	}
+	if strings.Contains(out.String(), `netapp_gcp_api_errors_total{method="GET"`) {
+		t.Errorf("expected no errors for successful calls, got:\n%s", out.String())
+	}
+}
+
+func TestValidateMetricsAddress(t *testing.T) {
+	for address, valid := range map[string]bool{
+		"127.0.0.1:9464": true,
+		"localhost:9464": true,
+		"[::1]:9464":     true,
+		"0.0.0.0:9464":   false,
+		":9464":          false,
+		"10.0.0.1:9464":  false,
+		"127.0.0.1":      false,
+	} {
+		if err := validateMetricsAddress(address); (err == nil) != valid {
+			t.Errorf("%s: expected valid %t, got %v", address, valid, err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package gcp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/restapi"
+	"golang.org/x/oauth2"
+)
+
+const computeNetworkURL = "https://compute.googleapis.com/compute/v1/"
+
+// computeScope is the OAuth scope of the Compute API calls
+const computeScope = "https://www.googleapis.com/auth/compute.readonly"
+
+// networkCache remembers the result of validating a network path and the IP ranges of the network,
+// so a network shared by many volumes is only looked up once per run.
+type networkCache struct {
+	mutex   sync.Mutex
+	results map[string]error
+	ranges  map[string][]string
+}
+
+// resolveNetwork returns the full network path for the volume. If network validation is enabled,
+// the existence of the network is checked through the Compute API the first time the path is seen.
+func (c *Client) resolveNetwork(request volumeRequest) (string, error) {
+	path := c.networkFullPath(request)
+	if !c.ValidateNetwork {
+		return path, nil
+	}
+
+	c.networks.mutex.Lock()
+	defer c.networks.mutex.Unlock()
+	if c.networks.results == nil {
+		c.networks.results = make(map[string]error)
+	}
+	if err, ok := c.networks.results[path]; ok {
+		return path, err
+	}
+
+	exists, err := c.networkExists(path)
+	if err != nil {
+		// don't cache transient failures
+		return path, err
+	}
+	if !exists {
+		c.networks.results[path] = fmt.Errorf("network %s does not exist", path)
+	} else {
+		c.networks.results[path] = nil
+	}
+	return path, c.networks.results[path]
+}
+
+// networkExists looks up the network path with the Compute API
+func (c *Client) networkExists(path string) (bool, error) {
+	log.Printf("Validating network %s", path)
+	var network computeNetwork
+	return c.computeGet(computeNetworkURL+path, &network)
+}
+
+// computeNetwork is the part of a Compute API network used by the provider
+type computeNetwork struct {
+	IPv4Range   string   `json:"IPv4Range"`
+	Subnetworks []string `json:"subnetworks"`
+}
+
+// computeSubnetwork is the part of a Compute API subnetwork used by the provider
+type computeSubnetwork struct {
+	IPCidrRange string `json:"ipCidrRange"`
+}
+
+// networkRanges returns the primary IP ranges of the subnets of a network, or the range of a legacy network.
+func (c *Client) networkRanges(path string) ([]string, error) {
+	c.networks.mutex.Lock()
+	defer c.networks.mutex.Unlock()
+	if c.networks.ranges == nil {
+		c.networks.ranges = make(map[string][]string)
+	}
+	if ranges, ok := c.networks.ranges[path]; ok {
+		return ranges, nil
+	}
+
+	log.Printf("Looking up the IP ranges of network %s", path)
+	var network computeNetwork
+	exists, err := c.computeGet(computeNetworkURL+path, &network)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("network %s does not exist", path)
+	}
+	ranges := []string{}
+	if network.IPv4Range != "" {
+		ranges = append(ranges, network.IPv4Range)
+	}
+	for _, subnetworkURL := range network.Subnetworks {
+		var subnetwork computeSubnetwork
+		if _, err := c.computeGet(subnetworkURL, &subnetwork); err != nil {
+			return nil, err
+		}
+		if subnetwork.IPCidrRange != "" {
+			ranges = append(ranges, subnetwork.IPCidrRange)
+		}
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("network %s has no IP ranges", path)
+	}
+	c.networks.ranges[path] = ranges
+	return ranges, nil
+}
+
+// expandVPCExportRules adds the IP ranges of the network to the allowed clients of the export rules with allow_vpc
+func (c *Client) expandVPCExportRules(policy *exportPolicy, networkPath string) error {
+	for i, rule := range policy.Rules {
+		if !rule.AllowVpc {
+			continue
+		}
+		ranges, err := c.networkRanges(networkPath)
+		if err != nil {
+			return fmt.Errorf("Error resolving allow_vpc of export rule %d: %s", i+1, err)
+		}
+		allowedClients := strings.Join(ranges, ",")
+		if rule.AllowedClients != "" {
+			allowedClients = rule.AllowedClients + "," + allowedClients
+		}
+		policy.Rules[i].AllowedClients = allowedClients
+	}
+	return nil
+}
+
+// computeGet reads a Compute API resource into v. It returns false if the resource doesn't exist.
+func (c *Client) computeGet(url string, v interface{}) (bool, error) {
+	httpClient, err := c.computeClient()
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(c.context(), "GET", url, nil)
+	if err != nil {
+		return false, err
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return false, err
+	}
+	if res.StatusCode >= 300 || res.StatusCode < 200 {
+		return false, fmt.Errorf("Error reading %s from the Compute API, code: %d, response: %s", url, res.StatusCode, restapi.BodySnippet(body))
+	}
+	return true, decodeResponse(body, v, res.StatusCode, url, "computeGet")
+}
+
+// computeClient returns an HTTP client authorized for the Compute API with the credentials of the provider. The
+// access token is cached by the API client, so it is requested once for all Compute API calls until it expires.
+func (c *Client) computeClient() (*http.Client, error) {
+	c.initOnce.Do(c.init)
+	// the Compute API calls go through the transport of the API calls
+	ctx, err := c.restapiClient.HTTPContext(c.context())
+	if err != nil {
+		return nil, err
+	}
+	return oauth2.NewClient(ctx, c.restapiClient.TokenSource(ctx, computeScope)), nil
+}
@@ -0,0 +1,40 @@
+package gcp
+
+import (
+	"fmt"
+	"log"
+)
+
+// preflightRoles are the roles of the service account of the provider: the admin role manages resources, the
+// viewer role is enough for read_only providers
+const preflightRoles = "roles/netappcloudvolumes.admin, or roles/netappcloudvolumes.viewer with read_only = true"
+
+// preflight lists the volumes of the region, the cheapest read of the API, to report missing credentials or
+// permissions when the provider is configured rather than in the middle of an apply
+func (c *Client) preflight(region string) error {
+	baseURL := fmt.Sprintf("%s/Volumes", region)
+	statusCode, response, err := c.CallAPIMethod("GET", baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("preflight check listing the volumes of project %s in %s failed: %s", c.Project, region, err)
+	}
+	if err := preflightError(c.Project, region, statusCode, response); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] preflight check of project %s in %s passed", c.Project, region)
+	return nil
+}
+
+// preflightError returns the error of a failed preflight call, explaining the permissions missing for 401 and 403
+func preflightError(project string, region string, statusCode int, response []byte) error {
+	responseError := apiResponseChecker(statusCode, response, "preflight")
+	if responseError == nil {
+		return nil
+	}
+	switch statusCode {
+	case 401:
+		return fmt.Errorf("preflight check: the API rejected the credentials of the provider, check service_account or credentials: %s", responseError)
+	case 403:
+		return fmt.Errorf("preflight check: the service account isn't allowed to list the volumes of project %s in %s. It needs the role %s: %s", project, region, preflightRoles, responseError)
+	}
+	return fmt.Errorf("preflight check listing the volumes of project %s in %s failed: %s", project, region, responseError)
+}
@@ -0,0 +1,26 @@
+package gcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreflightError(t *testing.T) {
+	if err := preflightError("123456", "us-east4", 200, []byte(`[]`)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	for _, c := range []struct {
+		statusCode int
+		response   string
+		expected   string
+	}{
+		{401, `{"code": 401, "message": "invalid token"}`, "check service_account or credentials"},
+		{403, `{"code": 403, "message": "permission denied"}`, "roles/netappcloudvolumes.admin"},
+		{404, `{"code": 404, "message": "region not found"}`, "region not found"},
+	} {
+		err := preflightError("123456", "us-east4", c.statusCode, []byte(c.response))
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("%d: expected an error containing %q, got %v", c.statusCode, c.expected, err)
+		}
+	}
+}
@@ -1,16 +1,20 @@
 package gcp
 
 import (
+	"context"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/restapi"
 )
 
 // Provider is the main method for NetApp GCP Terraform provider
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"project": {
 				Type:         schema.TypeString,
@@ -31,29 +35,282 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("GCP_CREDENTIALS", nil),
 				Description: "The credentials for GCP API operations.",
 			},
+			"token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("GCP_ACCESS_TOKEN", nil),
+				Description: "A token minted outside the provider to authorize API calls with instead of the credentials.",
+			},
+			"impersonate_service_account": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("GOOGLE_IMPERSONATE_SERVICE_ACCOUNT", nil),
+				Description: "The email of a service account to impersonate with the credentials of the provider.",
+			},
+			"api_host": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETAPP_GCP_API_HOST", nil),
+				ValidateFunc: validateURL,
+				Description:  "The endpoint of the API, e.g. a regional or Private Service Connect endpoint, or the base URL of a mock server.",
+			},
+			"audience": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETAPP_GCP_API_AUDIENCE", nil),
+				ValidateFunc: validateURL,
+				Description:  "The audience of the tokens of the API, if the endpoint of api_host expects another one.",
+			},
+			"ca_bundle": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("NETAPP_GCP_CA_BUNDLE", nil),
+				Description: "The path of a PEM file of CA certificates to trust in addition to the system ones, e.g. of a TLS inspecting proxy.",
+			},
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Skip the verification of TLS certificates. For lab use only.",
+			},
+			"read_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("GCP_READ_ONLY", false),
+				Description: "Refuse to perform any API call that would modify resources.",
+			},
+			"validate_network": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Check that the network of a volume exists through the Compute API before creating the volume.",
+			},
+			"failover_hosts": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "API hosts to fail over to, in order, when the API host is unreachable or unavailable.",
+			},
+			"default_storage_class": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(storageClasses, true),
+				Description:  "The storage class of volumes that don't set storage_class.",
+			},
+			"default_zone": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateZone,
+				Description:  "The zone of software volumes that don't set zone.",
+			},
+			"additional_service_levels": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Service levels to accept for volumes in addition to the ones known to the provider. They are passed to the API as is.",
+			},
+			"features": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Switches for optional provider behavior.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"open_export_policy_warning": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Deprecated:  "The argument is ignored. Terraform always warns about allowed_clients containing 0.0.0.0/0, as the warning is shown before the provider is configured. Remove it.",
+							Description: "Ignored. Export rules with allowed_clients containing 0.0.0.0/0 are always warned about.",
+						},
+					},
+				},
+			},
+			"poll_interval_seconds": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "The interval between polls of the API while waiting for a volume to change state. Each wait has its own default.",
+			},
+			"request_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The longest time in seconds of a single API request. 0 disables the timeout.",
+			},
+			"quota_warning_percent": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      80,
+				ValidateFunc: validation.IntBetween(0, 100),
+				Description:  "The API quota usage in percent to log a warning at, when the API reports it. 0 disables the warning.",
+			},
+			"auto_labeling": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Add labels identifying Terraform and the workspace as owner to every volume.",
+			},
+			"max_concurrent_deletes": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      4,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The number of volume deletions running at once in a region. Further deletions wait their turn in order. 0 removes the limit.",
+			},
+			"max_concurrent_jobs": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The number of operations creating, updating or deleting volumes, snapshots and backups running at once. Further operations wait their turn in order. 0, the default, removes the limit.",
+			},
+			"delete_snapshots_on_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Delete the snapshots of every volume before deleting the volume.",
+			},
+			"retry_backoff": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The exponential backoff between retries of API calls failing with a transient error.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"base_seconds": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10,
+							ValidateFunc: validation.IntAtLeast(1),
+							Description:  "The delay before the first retry, doubled for every further retry.",
+						},
+						"cap_seconds": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      60,
+							ValidateFunc: validation.IntAtLeast(1),
+							Description:  "The longest delay between retries.",
+						},
+						"jitter": {
+							Type:         schema.TypeFloat,
+							Optional:     true,
+							Default:      0.5,
+							ValidateFunc: validation.FloatBetween(0, 1),
+							Description:  "The random part of each delay, from 0 for none to 1 for a delay anywhere up to the full delay.",
+						},
+						"max_elapsed_seconds": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: validation.IntAtLeast(0),
+							Description:  "The longest time to retry a call without a timeout of its own. 0 leaves the retries to the number of retries of each error.",
+						},
+					},
+				},
+			},
+			"preflight_region": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateRegion,
+				Description:  "A region to list the volumes of when the provider is configured, to report missing permissions before any resource operation.",
+			},
+			"journal_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("NETAPP_GCP_JOURNAL_PATH", ""),
+				Description: "The path of a file to append a JSON line to for every API call that modifies resources.",
+			},
 		},
 
-		ResourcesMap: map[string]*schema.Resource{
-			"netapp-gcp_volume":           resourceGCPVolume(),
-			"netapp-gcp_active_directory": resourceGCPActiveDirectory(),
-			"netapp-gcp_snapshot":         resourceGCPSnapshot(),
-			"netapp-gcp_volume_backup":    resourceGCPVolumeBackup(),
-		},
+		ResourcesMap: withAliases(withRegions(map[string]*schema.Resource{
+			"netapp-gcp_volume":           withDescriptions("netapp-gcp_volume", resourceGCPVolume()),
+			"netapp-gcp_active_directory": withDescriptions("netapp-gcp_active_directory", resourceGCPActiveDirectory()),
+			"netapp-gcp_snapshot":         withDescriptions("netapp-gcp_snapshot", resourceGCPSnapshot()),
+			"netapp-gcp_volume_backup":    withDescriptions("netapp-gcp_volume_backup", resourceGCPVolumeBackup()),
+			"netapp-gcp_kms_config":       withDescriptions("netapp-gcp_kms_config", resourceGCPKMSConfig()),
+		})),
 
-		DataSourcesMap: map[string]*schema.Resource{
-			"netapp-gcp_volume":           dataSourceGCPVolume(),
-			"netapp-gcp_active_directory": dataSourceGCPActiveDirectory(),
-		},
+		DataSourcesMap: withAliases(withRegions(map[string]*schema.Resource{
+			"netapp-gcp_volume":                    withDescriptions("netapp-gcp_volume", dataSourceGCPVolume()),
+			"netapp-gcp_active_directory":          withDescriptions("netapp-gcp_active_directory", dataSourceGCPActiveDirectory()),
+			"netapp-gcp_snapshot_schedule_preview": withDescriptions("netapp-gcp_snapshot_schedule_preview", dataSourceGCPSnapshotSchedulePreview()),
+			"netapp-gcp_volume_history":            withDescriptions("netapp-gcp_volume_history", dataSourceGCPVolumeHistory()),
+			"netapp-gcp_snapshots":                 withDescriptions("netapp-gcp_snapshots", dataSourceGCPSnapshots()),
+			"netapp-gcp_volumes":                   withDescriptions("netapp-gcp_volumes", dataSourceGCPVolumes()),
+			"netapp-gcp_snapshot_mount":            withDescriptions("netapp-gcp_snapshot_mount", dataSourceGCPSnapshotMount()),
+			"netapp-gcp_filestore_conversion":      withDescriptions("netapp-gcp_filestore_conversion", dataSourceGCPFilestoreConversion()),
+		})),
+	}
+	provider.ConfigureFunc = func(d *schema.ResourceData) (interface{}, error) {
+		return providerConfigure(d, provider.StopContext())
+	}
+	return provider
+}
+
+// aliasPrefixes are prefixes every resource and data source is also registered with, for tooling that
+// doesn't handle the hyphen in netapp-gcp, e.g. netapp_gcp_volume for netapp-gcp_volume.
+var aliasPrefixes = []string{"netappgcp_", "netapp_gcp_"}
 
-		ConfigureFunc: providerConfigure,
+// withAliases registers each resource under its alias names as well
+func withAliases(resources map[string]*schema.Resource) map[string]*schema.Resource {
+	aliased := make(map[string]*schema.Resource, len(resources)*(len(aliasPrefixes)+1))
+	for name, resource := range resources {
+		aliased[name] = resource
+		for _, prefix := range aliasPrefixes {
+			aliased[prefix+strings.TrimPrefix(name, "netapp-gcp_")] = resource
+		}
 	}
+	return aliased
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
+// providerConfigure returns the client of the provider. Its API calls are canceled with the stop context.
+func providerConfigure(d *schema.ResourceData, stopContext context.Context) (interface{}, error) {
 	config := configStuct{
-		Project:        d.Get("project").(string),
-		ServiceAccount: d.Get("service_account").(string),
-		Credentials:    d.Get("credentials").(string),
+		Project:                   d.Get("project").(string),
+		ServiceAccount:            d.Get("service_account").(string),
+		Credentials:               d.Get("credentials").(string),
+		Token:                     d.Get("token").(string),
+		ImpersonateServiceAccount: d.Get("impersonate_service_account").(string),
+		APIHost:                   d.Get("api_host").(string),
+		Audience:                  d.Get("audience").(string),
+		CABundle:                  d.Get("ca_bundle").(string),
+		InsecureSkipVerify:        d.Get("insecure_skip_verify").(bool),
+		ReadOnly:                  d.Get("read_only").(bool),
+		ValidateNetwork:           d.Get("validate_network").(bool),
+		DefaultStorageClass:       d.Get("default_storage_class").(string),
+		DefaultZone:               d.Get("default_zone").(string),
+		JournalPath:               d.Get("journal_path").(string),
+
+		PollInterval:             time.Duration(d.Get("poll_interval_seconds").(int)) * time.Second,
+		RequestTimeout:           time.Duration(d.Get("request_timeout").(int)) * time.Second,
+		QuotaWarningPercent:      d.Get("quota_warning_percent").(int),
+		AutoLabeling:             d.Get("auto_labeling").(bool),
+		MaxConcurrentDeletes:     d.Get("max_concurrent_deletes").(int),
+		MaxConcurrentJobs:        d.Get("max_concurrent_jobs").(int),
+		DeleteSnapshotsOnDestroy: d.Get("delete_snapshots_on_destroy").(bool),
+		StopContext:              stopContext,
+	}
+	if v := d.Get("preflight_region").(string); v != "" {
+		// a zone is validated, so it has a region
+		config.PreflightRegion, _ = normalizeRegion(v)
+	}
+	if v := d.Get("retry_backoff").([]interface{}); len(v) > 0 && v[0] != nil {
+		backoff := v[0].(map[string]interface{})
+		config.Backoff = restapi.Backoff{
+			Base:       time.Duration(backoff["base_seconds"].(int)) * time.Second,
+			Cap:        time.Duration(backoff["cap_seconds"].(int)) * time.Second,
+			Jitter:     backoff["jitter"].(float64),
+			MaxElapsed: time.Duration(backoff["max_elapsed_seconds"].(int)) * time.Second,
+		}
+	}
+	for _, host := range d.Get("failover_hosts").([]interface{}) {
+		config.FailoverHosts = append(config.FailoverHosts, host.(string))
+	}
+	for _, level := range d.Get("additional_service_levels").([]interface{}) {
+		config.AdditionalServiceLevels = append(config.AdditionalServiceLevels, level.(string))
 	}
 
 	return config.clientFun()
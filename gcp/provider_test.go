@@ -1,9 +1,11 @@
 package gcp
 
 import (
-	"testing"
-
+	"context"
 	"os"
+	"strings"
+	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
@@ -18,6 +20,39 @@ func TestProvider(t *testing.T) {
 	}
 }
 
+func TestProviderDescriptions(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	for name, resource := range provider.ResourcesMap {
+		testCheckDescriptions(t, name, "", resource.Schema)
+	}
+	for name, resource := range provider.DataSourcesMap {
+		testCheckDescriptions(t, "data."+name, "", resource.Schema)
+	}
+}
+
+func TestProviderAliases(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	for _, name := range []string{"netappgcp_volume", "netapp_gcp_volume", "netapp_gcp_active_directory"} {
+		if provider.ResourcesMap[name] != provider.ResourcesMap["netapp-gcp_"+strings.TrimPrefix(strings.TrimPrefix(name, "netappgcp_"), "netapp_gcp_")] {
+			t.Errorf("resource %s is not an alias", name)
+		}
+	}
+	if _, ok := provider.DataSourcesMap["netapp_gcp_volume"]; !ok {
+		t.Error("data source netapp_gcp_volume is not registered")
+	}
+}
+
+func testCheckDescriptions(t *testing.T, name string, prefix string, attributes map[string]*schema.Schema) {
+	for key, attribute := range attributes {
+		if attribute.Description == "" {
+			t.Errorf("%s: attribute %s%s has no description", name, prefix, key)
+		}
+		if elem, ok := attribute.Elem.(*schema.Resource); ok {
+			testCheckDescriptions(t, name, prefix+key+".", elem.Schema)
+		}
+	}
+}
+
 func TestProvider_impl(t *testing.T) {
 	var _ terraform.ResourceProvider = Provider()
 }
@@ -39,3 +74,44 @@ func testAccPreCheck(t *testing.T) {
 	}
 
 }
+
+func TestProviderRegions(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	for name, resource := range provider.ResourcesMap {
+		if region, ok := resource.Schema["region"]; ok && region.StateFunc == nil {
+			t.Errorf("resource %s: region doesn't accept a zone", name)
+		}
+	}
+	for name, resource := range provider.DataSourcesMap {
+		if region, ok := resource.Schema["region"]; ok && region.StateFunc == nil {
+			t.Errorf("data source %s: region doesn't accept a zone", name)
+		}
+	}
+}
+
+func TestClientPollInterval(t *testing.T) {
+	client := &Client{}
+	if interval := client.pollInterval(20 * time.Second); interval != 20*time.Second {
+		t.Errorf("expected the default interval of the wait, got %s", interval)
+	}
+	client.PollInterval = 2 * time.Second
+	if interval := client.pollInterval(20 * time.Second); interval != 2*time.Second {
+		t.Errorf("expected poll_interval_seconds, got %s", interval)
+	}
+}
+
+func TestClientStopContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &Client{Host: "http://127.0.0.1:0/", StopContext: ctx}
+	if err := client.sleep(time.Millisecond); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	cancel()
+	start := time.Now()
+	if err := client.sleep(time.Hour); err == nil || time.Since(start) > time.Second {
+		t.Errorf("expected the sleep to be canceled, got %v after %s", err, time.Since(start))
+	}
+	if _, _, err := client.CallAPIMethod("GET", "us-east4/Volumes", nil); err == nil || !strings.Contains(err.Error(), "canceled") {
+		t.Errorf("expected the API call to be canceled, got %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package gcp
+
+import (
+	"log"
+	"sync"
+
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/restapi"
+)
+
+// quotaMonitor warns when the API quota usage reported by the rate limit headers reaches a threshold, so teams
+// learn they're approaching throttling before jobs start failing. It warns once per run, at the first crossing.
+type quotaMonitor struct {
+	// threshold is the percentage of the limit to warn at. The monitor is disabled if it is 0.
+	threshold int
+	mutex     sync.Mutex
+	warned    bool
+}
+
+// observe checks the rate limit of a response and returns whether it warned
+func (m *quotaMonitor) observe(rateLimit restapi.RateLimit) bool {
+	if m.threshold == 0 || rateLimit.Used() < m.threshold {
+		return false
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.warned {
+		return false
+	}
+	m.warned = true
+	log.Printf("[WARN] API quota usage is at %d%% (%d of %d requests remaining, reset: %s). Calls will be throttled when it is used up",
+		rateLimit.Used(), rateLimit.Remaining, rateLimit.Limit, rateLimit.Reset)
+	return true
+}
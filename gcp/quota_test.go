@@ -0,0 +1,25 @@
+package gcp
+
+import (
+	"testing"
+
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/restapi"
+)
+
+func TestQuotaMonitorObserve(t *testing.T) {
+	monitor := quotaMonitor{threshold: 80}
+	if monitor.observe(restapi.RateLimit{Limit: 100, Remaining: 50}) {
+		t.Error("expected no warning below the threshold")
+	}
+	if !monitor.observe(restapi.RateLimit{Limit: 100, Remaining: 20}) {
+		t.Error("expected a warning at the threshold")
+	}
+	if monitor.observe(restapi.RateLimit{Limit: 100, Remaining: 5}) {
+		t.Error("expected a single warning per run")
+	}
+
+	disabled := quotaMonitor{}
+	if disabled.observe(restapi.RateLimit{Limit: 100, Remaining: 0}) {
+		t.Error("expected no warning with the monitor disabled")
+	}
+}
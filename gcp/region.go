@@ -0,0 +1,126 @@
+package gcp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+var (
+	// regionPattern matches a region such as us-central1
+	regionPattern = regexp.MustCompile(`^[a-z]+-[a-z]+[0-9]+$`)
+	// zonePattern matches a zone such as us-central1-a, capturing its region
+	zonePattern = regexp.MustCompile(`^([a-z]+-[a-z]+[0-9]+)-[a-z]$`)
+	// regionPathPattern matches a region that is safe to use as a path segment of an API URL
+	regionPathPattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+)
+
+// invalidRegionError is returned for an API call whose region would make a malformed URL, e.g. an empty region
+type invalidRegionError struct {
+	Region string
+	// Operation is the method and path of the call
+	Operation string
+}
+
+func (e *invalidRegionError) Error() string {
+	return fmt.Sprintf("%s: invalid region %q, expected a region such as us-central1", e.Operation, e.Region)
+}
+
+// checkRegionPath checks the region of the API path of a call, its first segment
+func checkRegionPath(method string, baseURL string) error {
+	region := strings.SplitN(baseURL, "/", 2)[0]
+	if !regionPathPattern.MatchString(region) {
+		return &invalidRegionError{Region: region, Operation: method + " " + baseURL}
+	}
+	return nil
+}
+
+// normalizeRegion returns the region of a region or a zone, e.g. us-central1 for us-central1-a
+func normalizeRegion(value string) (string, error) {
+	if regionPattern.MatchString(value) {
+		return value, nil
+	}
+	if match := zonePattern.FindStringSubmatch(value); match != nil {
+		return match[1], nil
+	}
+	return "", fmt.Errorf("expected a region such as us-central1 or a zone such as us-central1-a, got %q", value)
+}
+
+// validateRegion is a ValidateFunc accepting a region or a zone, whose region is used
+func validateRegion(v interface{}, k string) ([]string, []error) {
+	if _, err := normalizeRegion(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+	return nil, nil
+}
+
+// validateZone is a ValidateFunc accepting a zone. A region is rejected with a hint, since it is a common mistake.
+func validateZone(v interface{}, k string) ([]string, []error) {
+	value := v.(string)
+	if zonePattern.MatchString(value) {
+		return nil, nil
+	}
+	if regionPattern.MatchString(value) {
+		return nil, []error{fmt.Errorf("%s: expected a zone such as %s-a, got the region %q", k, value, value)}
+	}
+	return nil, []error{fmt.Errorf("%s: expected a zone such as us-central1-a, got %q", k, value)}
+}
+
+// regionStateFunc stores the region of a region or a zone, so a zone given as region doesn't cause a diff
+func regionStateFunc(v interface{}) string {
+	region, err := normalizeRegion(v.(string))
+	if err != nil {
+		return v.(string)
+	}
+	return region
+}
+
+// withRegions makes the top level region attribute of every resource accept a zone, from which the region is derived
+func withRegions(resources map[string]*schema.Resource) map[string]*schema.Resource {
+	for _, resource := range resources {
+		if region, ok := resource.Schema["region"]; ok && region.Type == schema.TypeString {
+			region.ValidateFunc = validateRegion
+			region.StateFunc = regionStateFunc
+		}
+	}
+	return resources
+}
+
+// checkZoneInRegion returns an error if the zone is not in the region
+func checkZoneInRegion(zone string, region string) error {
+	if zone == "" {
+		return nil
+	}
+	zoneRegion, err := normalizeRegion(zone)
+	if err != nil {
+		return err
+	}
+	if zoneRegion != region {
+		return fmt.Errorf("zone %s is not in region %s", zone, region)
+	}
+	return nil
+}
+
+// parseRegionID splits an import ID of the form <region>:<id>. A zone is accepted as region.
+func parseRegionID(importID string) (string, string, error) {
+	return splitImportID(importID, ":", "<region>:<id>")
+}
+
+// parseRegionName splits an import ID of the form <region>/<name>. A zone is accepted as region.
+func parseRegionName(importID string) (string, string, error) {
+	return splitImportID(importID, "/", "<region>/<name>")
+}
+
+func splitImportID(importID string, separator string, format string) (string, string, error) {
+	parts := strings.SplitN(importID, separator, 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", fmt.Errorf("expected an import ID of the form %s, got %q", format, importID)
+	}
+	region, err := normalizeRegion(parts[0])
+	if err != nil {
+		return "", "", err
+	}
+	return region, parts[1], nil
+}
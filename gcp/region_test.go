@@ -0,0 +1,88 @@
+package gcp
+
+import "testing"
+
+func TestNormalizeRegion(t *testing.T) {
+	cases := map[string]string{
+		"us-central1":               "us-central1",
+		"us-central1-a":             "us-central1",
+		"northamerica-northeast1-b": "northamerica-northeast1",
+	}
+	for value, expected := range cases {
+		region, err := normalizeRegion(value)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %s", value, err)
+		}
+		if region != expected {
+			t.Errorf("normalizeRegion(%s) = %s, expected %s", value, region, expected)
+		}
+	}
+	for _, value := range []string{"", "central", "us-central1-a-b", "US-CENTRAL1"} {
+		if _, err := normalizeRegion(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
+
+func TestValidateZone(t *testing.T) {
+	if _, errs := validateZone("us-central1-a", "zone"); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if _, errs := validateZone("us-central1", "zone"); len(errs) != 1 || errs[0].Error() != `zone: expected a zone such as us-central1-a, got the region "us-central1"` {
+		t.Errorf("unexpected errors for a region: %v", errs)
+	}
+}
+
+func TestCheckZoneInRegion(t *testing.T) {
+	if err := checkZoneInRegion("us-central1-a", "us-central1"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := checkZoneInRegion("", "us-central1"); err != nil {
+		t.Errorf("unexpected error without a zone: %s", err)
+	}
+	if err := checkZoneInRegion("us-east4-a", "us-central1"); err == nil {
+		t.Error("expected an error for a zone of another region")
+	}
+}
+
+func TestParseRegionID(t *testing.T) {
+	region, id, err := parseRegionID("us-east4-b:0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if region != "us-east4" || id != "0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10" {
+		t.Errorf("unexpected region %s and id %s", region, id)
+	}
+	for _, importID := range []string{"0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10", "us-east4:", "east:0fe4bd4e"} {
+		if _, _, err := parseRegionID(importID); err == nil {
+			t.Errorf("%s: expected an error", importID)
+		}
+	}
+}
+
+func TestParseRegionName(t *testing.T) {
+	region, name, err := parseRegionName("us-east4/data/vol-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if region != "us-east4" || name != "data/vol-1" {
+		t.Errorf("unexpected region %s and name %s", region, name)
+	}
+	if _, _, err := parseRegionName("us-east4:vol-1"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestCheckRegionPath(t *testing.T) {
+	for _, baseURL := range []string{"us-east4/Volumes", "us-east4/Volumes/1234", "europe-west3/Storage/ActiveDirectory"} {
+		if err := checkRegionPath("GET", baseURL); err != nil {
+			t.Errorf("%s: unexpected error: %s", baseURL, err)
+		}
+	}
+	for _, baseURL := range []string{"/Volumes", " /Volumes", "us east4/Volumes", "us-east4?x=1/Volumes", "../Volumes"} {
+		err := checkRegionPath("GET", baseURL)
+		if _, ok := err.(*invalidRegionError); !ok {
+			t.Errorf("%s: expected an invalid region error, got %v", baseURL, err)
+		}
+	}
+}
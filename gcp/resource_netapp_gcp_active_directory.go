@@ -62,11 +62,14 @@ func resourceGCPActiveDirectory() *schema.Resource {
 }
 
 func resourceGCPActiveDirectoryCreate(d *schema.ResourceData, meta interface{}) error {
-	log.Printf("Creating active directory: %#v", d)
+	log.Printf("Creating active directory: %s", d.Get("domain").(string))
 	client := meta.(*Client)
 	// check whether the AD already exists on GCP, if it exist, error out.
 	listActiveDirectory := listActiveDirectoryRequest{}
 	listActiveDirectory.Region = d.Get("region").(string)
+	if err := client.requireFeature(listActiveDirectory.Region, featureActiveDirectory); err != nil {
+		return err
+	}
 	existedAd, err := client.listActiveDirectoryForRegion(listActiveDirectory)
 	if err != nil {
 		log.Print("Error checking current active directory before creating new active directory.")
@@ -150,7 +153,7 @@ func resourceGCPActiveDirectoryRead(d *schema.ResourceData, meta interface{}) er
 }
 
 func resourceGCPActiveDirectoryDelete(d *schema.ResourceData, meta interface{}) error {
-	log.Printf("Deleting active directory: %#v", d)
+	log.Printf("Deleting active directory: %s", d.Id())
 	client := meta.(*Client)
 	activeDirectory := deleteActiveDirectoryRequest{}
 	activeDirectory.Region = d.Get("region").(string)
@@ -165,7 +168,7 @@ func resourceGCPActiveDirectoryDelete(d *schema.ResourceData, meta interface{})
 }
 
 func resourceGCPActiveDirectoryExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	log.Printf("Checking existence of active directory: %#v", d)
+	log.Printf("Checking existence of active directory: %s", d.Id())
 	client := meta.(*Client)
 	activeDirectory := listActiveDirectoryRequest{}
 	activeDirectory.UUID = d.Get("uuid").(string)
@@ -191,7 +194,7 @@ func resourceGCPActiveDirectoryExists(d *schema.ResourceData, meta interface{})
 }
 
 func resourceGCPActiveDirectoryUpdate(d *schema.ResourceData, meta interface{}) error {
-	log.Printf("Checking existence of active directory: %#v", d)
+	log.Printf("Updating active directory: %s", d.Id())
 	client := meta.(*Client)
 	activeDirectory := operateActiveDirectoryRequest{}
 	// all of the following are required for API: update.
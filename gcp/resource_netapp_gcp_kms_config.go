@@ -0,0 +1,151 @@
+package gcp
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceGCPKMSConfig() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceGCPKMSConfigCreate,
+		Read:   resourceGCPKMSConfigRead,
+		Delete: resourceGCPKMSConfigDelete,
+		Exists: resourceGCPKMSConfigExists,
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"key_ring_location": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"key_ring": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"crypto_key": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"key_project_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"network": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"shared_vpc_project_number": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"state_details": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceGCPKMSConfigCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("Creating KMS config: %#v", d)
+	client := meta.(*Client)
+
+	config := kmsConfigRequest{
+		Region:          d.Get("region").(string),
+		KeyRingLocation: d.Get("key_ring_location").(string),
+		KeyRing:         d.Get("key_ring").(string),
+		KeyName:         d.Get("crypto_key").(string),
+		KeyProjectID:    d.Get("key_project_id").(string),
+	}
+	if err := client.requireFeature(config.Region, featureKMS); err != nil {
+		return err
+	}
+	config.Network = client.networkFullPath(volumeRequest{
+		Network:                d.Get("network").(string),
+		SharedVpcProjectNumber: d.Get("shared_vpc_project_number").(string),
+	})
+
+	res, err := client.createKMSConfig(config)
+	if err != nil {
+		log.Print("Error creating KMS config")
+		return err
+	}
+	d.SetId(res.UUID)
+
+	log.Printf("Created KMS config in region: %v", config.Region)
+
+	return resourceGCPKMSConfigRead(d, meta)
+}
+
+func resourceGCPKMSConfigRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	res, err := client.getKMSConfigByID(d.Get("region").(string), d.Id())
+	if err != nil {
+		return err
+	}
+	if res.UUID != d.Id() {
+		return fmt.Errorf("Expected KMS config with id: %v, Response contained KMS config with id: %v", d.Id(), res.UUID)
+	}
+
+	if err := d.Set("key_ring_location", res.KeyRingLocation); err != nil {
+		return fmt.Errorf("Error reading KMS config key_ring_location: %s", err)
+	}
+	if err := d.Set("key_ring", res.KeyRing); err != nil {
+		return fmt.Errorf("Error reading KMS config key_ring: %s", err)
+	}
+	if err := d.Set("crypto_key", res.KeyName); err != nil {
+		return fmt.Errorf("Error reading KMS config crypto_key: %s", err)
+	}
+	if err := d.Set("key_project_id", res.KeyProjectID); err != nil {
+		return fmt.Errorf("Error reading KMS config key_project_id: %s", err)
+	}
+	if err := d.Set("state", res.State); err != nil {
+		return fmt.Errorf("Error reading KMS config state: %s", err)
+	}
+	if err := d.Set("state_details", res.StateDetails); err != nil {
+		return fmt.Errorf("Error reading KMS config state_details: %s", err)
+	}
+
+	return nil
+}
+
+func resourceGCPKMSConfigDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("Deleting KMS config: %#v", d)
+	client := meta.(*Client)
+	if err := client.deleteKMSConfig(d.Get("region").(string), d.Id()); err != nil {
+		return err
+	}
+	d.SetId("")
+
+	return nil
+}
+
+func resourceGCPKMSConfigExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	log.Printf("Checking existence of KMS config: %#v", d)
+	client := meta.(*Client)
+	res, err := client.getKMSConfigByID(d.Get("region").(string), d.Id())
+	if err != nil {
+		return false, err
+	}
+	if res.UUID != d.Id() {
+		d.SetId("")
+		return false, nil
+	}
+
+	return true, nil
+}
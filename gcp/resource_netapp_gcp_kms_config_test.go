@@ -0,0 +1,90 @@
+package gcp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccKMSConfig_basic(t *testing.T) {
+	var kmsConfig kmsConfigResult
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckGCPKMSConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccKMSConfigConfigCreate(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckGCPKMSConfigExists("netapp-gcp_kms_config.terraform-acceptance-test-1", &kmsConfig),
+					resource.TestCheckResourceAttr("netapp-gcp_kms_config.terraform-acceptance-test-1", "region", "us-central1"),
+					resource.TestCheckResourceAttr("netapp-gcp_kms_config.terraform-acceptance-test-1", "key_ring", "cvs-keyring"),
+					resource.TestCheckResourceAttr("netapp-gcp_kms_config.terraform-acceptance-test-1", "crypto_key", "cvs-key"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckGCPKMSConfigDestroy(state *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+
+	for _, rs := range state.RootModule().Resources {
+		if rs.Type != "netapp-gcp_kms_config" {
+			continue
+		}
+		response, err := client.getKMSConfigByID(rs.Primary.Attributes["region"], rs.Primary.ID)
+		if err == nil {
+			if response.UUID != "" {
+				return fmt.Errorf("KMS config (%s) still exists", response.UUID)
+			}
+		}
+	}
+	return nil
+}
+
+func testAccCheckGCPKMSConfigExists(name string, kmsConfig *kmsConfigResult) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No KMS config ID is set")
+		}
+		response, err := client.getKMSConfigByID(rs.Primary.Attributes["region"], rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		if response.UUID != rs.Primary.ID {
+			return fmt.Errorf("Resource ID and KMS config ID do not match")
+		}
+
+		*kmsConfig = response
+
+		return nil
+	}
+}
+
+func testAccKMSConfigConfigCreate() string {
+	return fmt.Sprintf(`
+	resource "netapp-gcp_kms_config" "terraform-acceptance-test-1" {
+		provider = netapp-gcp
+		region = "us-central1"
+		key_ring_location = "us-central1"
+		key_ring = "cvs-keyring"
+		crypto_key = "cvs-key"
+		key_project_id = "cvs-kms-project"
+		network = "cvs-terraform-vpc"
+	  }
+	`)
+}
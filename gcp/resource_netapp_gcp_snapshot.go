@@ -19,6 +19,10 @@ func resourceGCPSnapshot() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -40,6 +44,14 @@ func resourceGCPSnapshot() *schema.Resource {
 				Optional: true,
 				ForceNew: true,
 			},
+			"created": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"used_bytes": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
 		},
 	}
 }
@@ -70,12 +82,14 @@ func resourceGCPSnapshotCreate(d *schema.ResourceData, meta interface{}) error {
 		}
 		if volresult.LifeCycleStateDetails != "Available for use" {
 			if retries < 3 {
-				log.Printf("Volume %s is not ready. Wait for 5 seconds and check again.\n", volume.Name)
-				time.Sleep(5 * time.Second)
+				interval := client.pollInterval(5 * time.Second)
+				log.Printf("Volume %s is not ready. Wait for %s and check again.\n", volume.Name, interval)
+				if err := client.sleep(interval); err != nil {
+					return err
+				}
 				retries++
 			} else {
-				log.Printf("Volume %s is not ready.\n", volume.Name)
-				return err
+				return fmt.Errorf("volume %s is not ready to take a snapshot: %s", volume.Name, volresult.LifeCycleStateDetails)
 			}
 		} else {
 			snapshot.VolumeID = volresult.VolumeID
@@ -83,6 +97,13 @@ func resourceGCPSnapshotCreate(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
+	// snapshot operations of a volume run one at a time, see snapshotOperations. The job slot is taken first, in
+	// the order of a volume deleting its snapshots.
+	releaseJob := client.acquireJob("createSnapshot")
+	defer releaseJob()
+	release := client.snapshotOperations.acquire(snapshot.VolumeID, "createSnapshot")
+	defer release()
+
 	res, err := client.createSnapshot(&snapshot)
 	if err != nil {
 		log.Print("Error creating snapshot")
@@ -90,6 +111,9 @@ func resourceGCPSnapshotCreate(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	d.SetId(res.Name.JobID.SnapshotID)
+	if err := client.waitForSnapshotJob(snapshot.Region, snapshot.VolumeID, d.Id(), []string{"creating", "deleted"}, "available", d.Timeout(schema.TimeoutCreate)); err != nil {
+		return fmt.Errorf("Error waiting for snapshot %s to be created: %s", snapshot.Name, err)
+	}
 	log.Printf("Created snapshot: %v", snapshot.Name)
 
 	return resourceGCPSnapshotRead(d, meta)
@@ -129,6 +153,13 @@ func resourceGCPSnapshotRead(d *schema.ResourceData, meta interface{}) error {
 		return fmt.Errorf("Expected Snapshot ID %v, Response contained Snapshot ID %v", id, res.SnapshotID)
 	}
 
+	if err := d.Set("created", res.Created); err != nil {
+		return fmt.Errorf("Error reading snapshot created: %s", err)
+	}
+	if err := d.Set("used_bytes", int(res.UsedBytes)); err != nil {
+		return fmt.Errorf("Error reading snapshot used_bytes: %s", err)
+	}
+
 	return nil
 }
 
@@ -157,12 +188,17 @@ func resourceGCPSnapshotDelete(d *schema.ResourceData, meta interface{}) error {
 	id := d.Id()
 	snapshot.SnapshotID = id
 
+	releaseJob := client.acquireJob("deleteSnapshot")
+	defer releaseJob()
+	release := client.snapshotOperations.acquire(snapshot.VolumeID, "deleteSnapshot")
+	defer release()
+
 	deleteErr := client.deleteSnapshot(snapshot)
 	if deleteErr != nil {
 		return deleteErr
 	}
 
-	return nil
+	return client.waitForSnapshotJob(snapshot.Region, snapshot.VolumeID, id, []string{"deleting", "available"}, "deleted", d.Timeout(schema.TimeoutDelete))
 }
 
 func resourceGCPSnapshotExists(d *schema.ResourceData, meta interface{}) (bool, error) {
@@ -232,6 +268,9 @@ func resourceGCPSnapshotUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	snapshot.VolumeID = volresult.VolumeID
 
+	release := client.snapshotOperations.acquire(snapshot.VolumeID, "updateSnapshot")
+	defer release()
+
 	err = client.updateSnapshot(snapshot)
 	if err != nil {
 		return err
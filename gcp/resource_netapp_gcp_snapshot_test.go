@@ -64,7 +64,7 @@ func testAccCheckSnapshotDestroy(state *terraform.State) error {
 
 			if err == nil {
 				if response.SnapshotID != "" {
-					return fmt.Errorf("Error snapshot %s still exists in %s", rs.Primary.ID, response)
+					return fmt.Errorf("Error snapshot %s still exists in %s", rs.Primary.ID, retriveSnapshot.Region)
 				}
 			}
 		}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/structure"
 	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/restapi"
 )
@@ -18,15 +19,33 @@ const GiBToBytes = 1024 * 1024 * 1024
 // TiBToGiB converting TiB to GiB
 const TiBToGiB = 1024
 
+// sizeInBytes converts a size in GiB to bytes. Sizes in bytes are int64, so multi-TiB volumes don't overflow
+// on 32-bit platforms.
+func sizeInBytes(gib int) int64 {
+	return int64(gib) * GiBToBytes
+}
+
+// sizeInGiB converts a size in bytes to GiB
+func sizeInGiB(bytes int64) int {
+	return int(bytes / GiBToBytes)
+}
+
 func resourceGCPVolume() *schema.Resource {
-	return &schema.Resource{
-		Create: resourceGCPVolumeCreate,
-		Read:   resourceGCPVolumeRead,
-		Delete: resourceGCPVolumeDelete,
-		Update: resourceGCPVolumeUpdate,
-		Exists: resourceGCPVolumeExists,
+	resource := &schema.Resource{
+		SchemaVersion: 1,
+		Create:        resourceGCPVolumeCreate,
+		Read:          resourceGCPVolumeRead,
+		Delete:        resourceGCPVolumeDelete,
+		Update:        resourceGCPVolumeUpdate,
+		Exists:        resourceGCPVolumeExists,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: resourceGCPVolumeImport,
+		},
+		CustomizeDiff: resourceGCPVolumeCustomizeDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(20 * time.Minute),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -47,22 +66,41 @@ func resourceGCPVolume() *schema.Resource {
 				Type:     schema.TypeList,
 				Required: true,
 				Elem: &schema.Schema{
-					Type: schema.TypeString,
+					Type:             schema.TypeString,
+					ValidateFunc:     validation.StringInSlice([]string{"NFSv3", "NFSv4", "CIFS", "SMB"}, true),
+					DiffSuppressFunc: suppressProtocolTypeDiff,
 				},
 			},
 			"network": {
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressNetworkDiff,
+			},
+			"network_full_path": {
 				Type:     schema.TypeString,
-				Required: true,
+				Computed: true,
+			},
+			"volume_id": {
+				Type:     schema.TypeString,
+				Computed: true,
 			},
 			"size": {
-				Type:     schema.TypeInt,
-				Required: true,
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"size_in_gib"},
+			},
+			"size_in_gib": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"size"},
 			},
 			"service_level": {
-				Type:         schema.TypeString,
-				Optional:     true,
-				Default:      "medium",
-				ValidateFunc: validation.StringInSlice([]string{"standard", "premium", "extreme"}, true),
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          defaultServiceLevel,
+				DiffSuppressFunc: suppressServiceLevelDiff,
 			},
 			"volume_path": {
 				Type:     schema.TypeString,
@@ -72,6 +110,7 @@ func resourceGCPVolume() *schema.Resource {
 			"shared_vpc_project_number": {
 				Type:         schema.TypeString,
 				Optional:     true,
+				Computed:     true,
 				ValidateFunc: validation.StringMatch(regexp.MustCompile("^[0-9]+$"), "shared_vpc_project_number must be a numerical project number"),
 			},
 			"mount_points": {
@@ -213,6 +252,39 @@ func resourceGCPVolume() *schema.Resource {
 					},
 				},
 			},
+			"backup_policy": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"daily_backups_to_keep": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"weekly_backups_to_keep": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"monthly_backups_to_keep": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+					},
+				},
+			},
 			"export_policy": {
 				Type:     schema.TypeSet,
 				Optional: true,
@@ -228,8 +300,14 @@ func resourceGCPVolume() *schema.Resource {
 										Optional: true,
 									},
 									"allowed_clients": {
-										Type:     schema.TypeString,
+										Type:         schema.TypeString,
+										Optional:     true,
+										ValidateFunc: validateAllowedClients,
+									},
+									"allow_vpc": {
+										Type:     schema.TypeBool,
 										Optional: true,
+										Default:  false,
 									},
 									"has_root_access": {
 										Type:     schema.TypeBool,
@@ -296,38 +374,198 @@ func resourceGCPVolume() *schema.Resource {
 					},
 				},
 			},
-			"delete_on_creation_error": {
+			"export_policy_from_volume_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"export_policy"},
+			},
+			"exports_disabled": {
 				Type:     schema.TypeBool,
 				Optional: true,
 				Default:  false,
 			},
-			"zone": {
+			"labels": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"kms_key_ring": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"crypto_key": {
 				Type:     schema.TypeString,
 				Optional: true,
+				ForceNew: true,
+			},
+			"snapshot_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"read_only": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				ForceNew: true,
+			},
+			"refresh_from_snapshot_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"smb_share_settings": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(smbShareSettings, false),
+				},
+			},
+			"snapshot_directory": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+			"smb_share_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"nfs_mount_command": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"smb_unc_path": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"wait_for_state": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "available",
+				ValidateFunc: validation.StringInSlice([]string{"available", "creating", "any"}, false),
+			},
+			"wait_for_snapshot_policy": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"recreate_on_error": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"lifecycle_state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"lifecycle_state_details": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"delete_on_creation_error": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"allow_shrink": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"deletion_protection": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"delete_snapshots_on_destroy": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"extra_request_parameters": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validateExtraRequestParameters,
+				DiffSuppressFunc: structure.SuppressJsonDiff,
+			},
+			"zone": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateZone,
 			},
 			"storage_class": {
 				Type:         schema.TypeString,
 				Optional:     true,
-				ValidateFunc: validation.StringInSlice([]string{"software", "hardware"}, true),
+				ValidateFunc: validation.StringInSlice(storageClasses, true),
 			},
 		},
 	}
+	// version 0 stored the network of volumes read before the normalization of network as the full path
+	resource.StateUpgraders = []schema.StateUpgrader{
+		{
+			Version: 0,
+			Type:    resourceGCPVolumeV0().CoreConfigSchema().ImpliedType(),
+			Upgrade: resourceGCPVolumeStateUpgradeV0,
+		},
+	}
+	return resource
 }
 
-// TranslateServiceLevelState2API to translate service level state based on the setup value due to the API bugs
-// resource value: API call value
-// standard      : low
-// premium       : medium
-// extreme       : extreme
-func TranslateServiceLevelState2API(slevel string) string {
-	var apiValue = slevel
-	if slevel == "standard" {
-		apiValue = "low"
-	} else if slevel == "premium" {
-		apiValue = "medium"
+// smbShareSettings are the settings an SMB share of a CIFS volume supports
+var smbShareSettings = []string{
+	"encrypt_data",
+	"browsable",
+	"non_browsable",
+	"changenotify",
+	"oplocks",
+	"showspecialfiles",
+	"show_previous_versions",
+	"access_based_enumeration",
+	"continuously_available",
+}
+
+// apiProtocolType returns the protocol type in the casing expected by the API, so "nfsv3", "NFSv3" and "NFSV3"
+// are all accepted. SMB is sent as CIFS.
+func apiProtocolType(protocol string) string {
+	switch strings.ToUpper(protocol) {
+	case "NFSV3":
+		return "NFSv3"
+	case "NFSV4":
+		return "NFSv4"
+	case "SMB", "CIFS":
+		return "CIFS"
 	}
+	return protocol
+}
 
-	return apiValue
+// hasProtocolType checks whether the protocol types, in the casing of the API, include the protocol type
+func hasProtocolType(protocolTypes []string, protocolType string) bool {
+	for _, v := range protocolTypes {
+		if v == protocolType {
+			return true
+		}
+	}
+	return false
+}
+
+// suppressProtocolTypeDiff ignores differences in casing and between SMB and CIFS
+func suppressProtocolTypeDiff(k, old, new string, d *schema.ResourceData) bool {
+	return apiProtocolType(old) == apiProtocolType(new)
+}
+
+// suppressNetworkDiff ignores the difference between a network name and its full path, since Read stores the name
+func suppressNetworkDiff(k, old, new string, d *schema.ResourceData) bool {
+	return networkShortName(old) == networkShortName(new)
+}
+
+// suppressServiceLevelDiff ignores the difference between the names of a service level, e.g. premium and medium,
+// since Read stores the name
+func suppressServiceLevelDiff(k, old, new string, d *schema.ResourceData) bool {
+	return strings.EqualFold(canonicalServiceLevel(old), canonicalServiceLevel(new))
 }
 
 func resourceGCPVolumeCreate(d *schema.ResourceData, meta interface{}) error {
@@ -342,24 +580,27 @@ func resourceGCPVolumeCreate(d *schema.ResourceData, meta interface{}) error {
 	volume.Network = d.Get("network").(string)
 	protocols := d.Get("protocol_types")
 	for _, protocol := range protocols.([]interface{}) {
-		if protocol.(string) == "SMB" {
-			volume.ProtocolTypes = append(volume.ProtocolTypes, "CIFS")
-		} else {
-			volume.ProtocolTypes = append(volume.ProtocolTypes, protocol.(string))
-		}
+		volume.ProtocolTypes = append(volume.ProtocolTypes, apiProtocolType(protocol.(string)))
 	}
 	// size in 1 GiB increments, api takes in bytes only
-	volume.Size = d.Get("size").(int) * GiBToBytes
+	volume.Size = sizeInBytes(d.Get("size").(int))
 
 	if v, ok := d.GetOk("service_level"); ok {
 		slevel := v.(string)
-		volume.ServiceLevel = TranslateServiceLevelState2API(slevel)
+		volume.ServiceLevel = serviceLevelToAPI(slevel)
 	}
 
 	if v, ok := d.GetOk("snapshot_policy"); ok {
 		if len(v.([]interface{})) > 0 {
-			policy := v.([]interface{})[0].(map[string]interface{})
-			volume.SnapshotPolicy = expandSnapshotPolicy(policy)
+			policy := expandSnapshotPolicy(v.([]interface{})[0].(map[string]interface{}))
+			volume.SnapshotPolicy = &policy
+		}
+	}
+
+	if v, ok := d.GetOk("backup_policy"); ok {
+		if len(v.([]interface{})) > 0 && v.([]interface{})[0] != nil {
+			policy := expandBackupPolicy(v.([]interface{})[0].(map[string]interface{}))
+			volume.BackupPolicy = &policy
 		}
 	}
 
@@ -367,6 +608,12 @@ func resourceGCPVolumeCreate(d *schema.ResourceData, meta interface{}) error {
 		volume.CreationToken = v.(string)
 	}
 
+	extraParameters, err := expandExtraRequestParameters(d.Get("extra_request_parameters").(string))
+	if err != nil {
+		return err
+	}
+	volume.ExtraParameters = extraParameters
+
 	var volType string
 	dpType := d.Get("type_dp").(bool)
 
@@ -383,10 +630,80 @@ func resourceGCPVolumeCreate(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
+	// copy the export rules of an existing volume in the same region
+	if v, ok := d.GetOk("export_policy_from_volume_id"); ok {
+		sourceVolume, err := client.getVolumeByID(volumeRequest{Region: volume.Region, VolumeID: v.(string)})
+		if err != nil {
+			log.Print("Error reading export policy from source volume")
+			return err
+		}
+		volume.ExportPolicy = sourceVolume.ExportPolicy
+	}
+
 	if v, ok := d.GetOk("shared_vpc_project_number"); ok {
 		volume.SharedVpcProjectNumber = v.(string)
 	}
 
+	// create the volume as a clone of a snapshot of another volume
+	if v, ok := d.GetOk("snapshot_id"); ok {
+		volume.SnapshotID = v.(string)
+	}
+
+	// the export rules of a read-only clone, including copied ones, must not allow writes
+	if d.Get("read_only").(bool) {
+		if len(volume.ExportPolicy.Rules) == 0 {
+			return fmt.Errorf("read_only requires export_policy or export_policy_from_volume_id, so the default export policy doesn't allow writes")
+		}
+		if err := checkReadOnlyExportPolicy(volume.ExportPolicy); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := d.GetOk("labels"); ok {
+		volume.Labels = expandStringList(v.([]interface{}))
+	}
+	if client.AutoLabeling {
+		volume.Labels = withAutoLabels(volume.Labels, autoLabels())
+	}
+
+	if v, ok := d.GetOk("smb_share_settings"); ok {
+		volume.SmbShareSettings = expandStringList(v.([]interface{}))
+	}
+
+	if v, ok := d.GetOkExists("snapshot_directory"); ok {
+		snapshotDirectory := v.(bool)
+		volume.SnapshotDirectory = &snapshotDirectory
+	}
+
+	// a CIFS volume joins the Active Directory of its region
+	if hasProtocolType(volume.ProtocolTypes, "CIFS") {
+		activeDirectory, err := client.listActiveDirectoryForRegion(listActiveDirectoryRequest{Region: volume.Region})
+		if err != nil {
+			return err
+		}
+		if activeDirectory.UUID == "" {
+			return fmt.Errorf("a volume with protocol type CIFS requires an Active Directory connection in region %s, see netapp-gcp_active_directory", volume.Region)
+		}
+	} else if len(volume.SmbShareSettings) > 0 {
+		return fmt.Errorf("smb_share_settings requires protocol type CIFS")
+	}
+
+	// a volume encrypted with a customer-managed key requires the key to be registered in its region
+	keyRing, cryptoKey := d.Get("kms_key_ring").(string), d.Get("crypto_key").(string)
+	if (keyRing == "") != (cryptoKey == "") {
+		return fmt.Errorf("kms_key_ring and crypto_key must be set together")
+	}
+	if keyRing != "" {
+		kmsConfig, err := client.findKMSConfig(volume.Region, keyRing, cryptoKey)
+		if err != nil {
+			return err
+		}
+		if kmsConfig.UUID == "" {
+			return fmt.Errorf("crypto key %s of key ring %s is not registered in region %s, see netapp-gcp_kms_config", cryptoKey, keyRing, volume.Region)
+		}
+		volume.EncryptionType = cloudVolumesKMS
+	}
+
 	if v, ok := d.GetOk("zone"); ok {
 		volume.Zone = v.(string)
 	}
@@ -395,99 +712,351 @@ func resourceGCPVolumeCreate(d *schema.ResourceData, meta interface{}) error {
 		volume.StorageClass = v.(string)
 	}
 
+	// fall back to the provider defaults; the zone only applies to software volumes
+	if volume.StorageClass == "" {
+		volume.StorageClass = client.DefaultStorageClass
+	}
+	if volume.Zone == "" && strings.EqualFold(volume.StorageClass, "software") {
+		volume.Zone = client.DefaultZone
+	}
+
+	if err := client.expandVPCExportRules(&volume.ExportPolicy, client.networkFullPath(volume)); err != nil {
+		return err
+	}
+	if d.Get("exports_disabled").(bool) {
+		volume.ExportPolicy = disabledExportPolicy()
+	}
+
+	if err := checkZoneInRegion(volume.Zone, volume.Region); err != nil {
+		return err
+	}
+
 	// If storage class is 'software', zone is mandatory
 	if volume.StorageClass == "software" && volume.Zone == "" {
 		log.Print("Error creating volume")
-		return fmt.Errorf("If storage_class is software, zone is mandatory")
+		return fmt.Errorf("If storage_class is software, zone is mandatory. Set zone or the provider default_zone")
 	}
 
+	// the job slot is held until the volume is available, including recreations of a volume in error state
+	release := client.acquireJob("createVolume")
+	defer release()
+	// the create timeout starts when the job can run, not while it waits for a job slot
+	volume.Deadline = time.Now().Add(d.Timeout(schema.TimeoutCreate))
+
 	var res createVolumeResult
-	var err error
 	res, err = client.createVolume(&volume, volType)
 	if err != nil {
 		log.Print("Error creating volume")
 		return err
 	}
 
+	// pipelines handing off to other tooling don't wait for the volume after the creation job is submitted
+	waitForState := d.Get("wait_for_state").(string)
+	if waitForState == "any" {
+		log.Printf("Created volume %s without waiting for it", res.Name.JobID.VolID)
+		d.SetId(res.Name.JobID.VolID)
+		return nil
+	}
+
 	var volumeRes volumeResult
-	time.Sleep(5 * time.Second)
-	volume.Network = d.Get("network").(string)
+	if err := client.sleep(client.pollInterval(5 * time.Second)); err != nil {
+		return err
+	}
 	volumeRes, err = validateVolumeExistsAfterCreate(client, volume, res.Name.JobID.VolID, volType)
 	if err != nil {
 		return err
 	}
 	d.SetId(volumeRes.VolumeID)
 	if volumeRes.LifeCycleState == "available" {
+		return resourceGCPVolumeCreated(d, meta, volume)
+	}
+	if waitForState == "creating" {
 		return resourceGCPVolumeRead(d, meta)
 	}
-	volumeRes, err = waitForVolumeCreationComplete(client, volumeRes)
+	volumeRes, err = waitForVolumeCreationComplete(client, volumeRes, volume.Deadline)
 	if err != nil {
 		return err
 	}
 	// if volume's state is error, delete the volume and retry for twice. If the operation still fails, return error.
 	if volumeRes.LifeCycleState == "error" {
 		retries := 2
-		for retries > 0 && volumeRes.LifeCycleState == "error" {
-			deleteErr := resourceGCPVolumeDelete(d, meta)
+		// recreating the volume won't help when the quota of the region is exhausted
+		if isQuotaError(volumeRes.LifeCycleStateDetails) {
+			retries = 0
+		}
+		for attempt := 1; retries > 0 && volumeRes.LifeCycleState == "error"; attempt++ {
+			deleteErr := deleteGCPVolume(d, meta)
 			if deleteErr != nil {
 				return fmt.Errorf("failed to delete volume in error state after creation. %s", deleteErr.Error())
 			}
-			volume.Network = d.Get("network").(string)
 			res, err = client.createVolume(&volume, volType)
 			if err != nil {
 				return err
 			}
-			time.Sleep(5 * time.Second)
-			volume.Network = d.Get("network").(string)
+			if err := client.sleep(client.pollInterval(5 * time.Second)); err != nil {
+				return err
+			}
 			volumeRes, err = validateVolumeExistsAfterCreate(client, volume, res.Name.JobID.VolID, volType)
 			if err != nil {
 				return err
 			}
 			d.SetId(volumeRes.VolumeID)
-			volumeRes, err = waitForVolumeCreationComplete(client, volumeRes)
+			volumeRes, err = waitForVolumeCreationComplete(client, volumeRes, volume.Deadline)
 			if err != nil {
 				return err
 			}
 			if volumeRes.LifeCycleState == "available" {
-				return resourceGCPVolumeRead(d, meta)
+				return resourceGCPVolumeCreated(d, meta, volume)
+			}
+			if err := client.backoff(attempt); err != nil {
+				return err
 			}
-			timeSleep := time.Duration(nextRandomInt(5, 10)) * time.Second
-			time.Sleep(timeSleep)
 			retries--
 		}
 		if d.Get("delete_on_creation_error").(bool) {
-			deleteErr := resourceGCPVolumeDelete(d, meta)
+			// the volume never became available, so deletion_protection doesn't apply
+			deleteErr := deleteGCPVolume(d, meta)
 			if deleteErr != nil {
 				return fmt.Errorf("failed to delete volume in error state after creation. %s", deleteErr.Error())
 			}
-			return fmt.Errorf("%v. Volume in error state is deleted", volumeRes.LifeCycleStateDetails)
+			d.SetId("")
+			return fmt.Errorf("volume %s with id: %s is in error state after creation: %v. Volume in error state is deleted", volumeRes.Name, volumeRes.VolumeID, volumeRes.LifeCycleStateDetails)
+		}
+		if isQuotaError(volumeRes.LifeCycleStateDetails) {
+			return quotaExceededError(volume.Region, volumeRes.LifeCycleStateDetails)
+		}
+		return fmt.Errorf("volume %s with id: %s is in error state after creation: %v", volumeRes.Name, volumeRes.VolumeID, volumeRes.LifeCycleStateDetails)
+	}
+	return resourceGCPVolumeCreated(d, meta, volume)
+}
+
+// resourceGCPVolumeCreated reads a volume that became available after its creation. With wait_for_snapshot_policy,
+// it waits for an enabled snapshot policy to be applied first, as the policy can lag the volume and miss the first
+// scheduled snapshot. A policy that isn't applied in time fails the creation, and the volume is tainted.
+func resourceGCPVolumeCreated(d *schema.ResourceData, meta interface{}, volume volumeRequest) error {
+	if d.Get("wait_for_snapshot_policy").(bool) && volume.SnapshotPolicy != nil && volume.SnapshotPolicy.Enabled {
+		client := meta.(*Client)
+		schedules := configuredSchedules(d.Get("snapshot_policy").([]interface{})[0].(map[string]interface{}))
+		if err := client.waitForSnapshotPolicy(volume.Region, d.Id(), *volume.SnapshotPolicy, schedules, volume.Deadline); err != nil {
+			return fmt.Errorf("%s, the first scheduled snapshot of volume %s may be missed", err, volume.Name)
 		}
-		return fmt.Errorf("%v", volumeRes.LifeCycleStateDetails)
 	}
 	return resourceGCPVolumeRead(d, meta)
 }
 
-// Wait up to 15 minutes for volume creation to complete.
-func waitForVolumeCreationComplete(client *Client, volumeRes volumeResult) (volumeResult, error) {
-	waitSeconds := 900	// first volume creation can take 11 minutes
-	threshold := 900 -60	// when to warn
-	elapsed := time.Duration(0)
-	var err error
-	for waitSeconds > 0 && volumeRes.LifeCycleState == "creating" {
-		timeSleep := time.Duration(nextRandomInt(20, 30))
-		time.Sleep(timeSleep * time.Second)
-		elapsed = elapsed + timeSleep
-		volumeRes, err = client.getVolumeByID(volumeRequest{Region: volumeRes.Region, VolumeID: volumeRes.VolumeID})
-		if err != nil {
-			return volumeResult{}, err
+// Wait until the deadline from the create timeout for volume creation to complete. The first volume creation can
+// take 11 minutes. A volume in error state is returned, so it can be recreated.
+func waitForVolumeCreationComplete(client *Client, volumeRes volumeResult, deadline time.Time) (volumeResult, error) {
+	return client.waitForVolumeState(volumeWait{
+		region:   volumeRes.Region,
+		volumeID: volumeRes.VolumeID,
+		pending:  []string{"creating"},
+		target:   []string{"available", "error"},
+		interval: time.Duration(nextRandomInt(20, 30)) * time.Second,
+		deadline: deadline,
+	})
+}
+
+// Wait until the deadline from the update timeout for an in-place operation such as an update or a revert to complete.
+// If jobID is set, the progress of the job is logged while waiting, so a long restore doesn't look hung.
+func waitForVolumeAvailable(client *Client, region string, volumeID string, jobID string, deadline time.Time) (volumeResult, error) {
+	return client.waitForVolumeState(volumeWait{
+		region:   region,
+		volumeID: volumeID,
+		pending:  volumeTransitionalStates,
+		target:   []string{"available"},
+		jobID:    jobID,
+		interval: 20 * time.Second,
+		deadline: deadline,
+	})
+}
+
+// logJobProgress logs the state of a job, with its completion percentage if the API reports one.
+// Failing to look up the job doesn't fail the operation it belongs to.
+func logJobProgress(client *Client, region string, volumeID string, jobID string) {
+	job, err := client.getJob(region, jobID)
+	if err != nil {
+		log.Printf("[DEBUG] Unable to get progress of job %s: %s", jobID, err)
+		return
+	}
+	if job.Progress > 0 {
+		log.Printf("[INFO] %s of volume %s is %s: %d%% complete", job.Action, volumeID, job.State, job.Progress)
+	} else {
+		log.Printf("[INFO] %s of volume %s is %s", job.Action, volumeID, job.State)
+	}
+}
+
+// updateVolumeSizeThenExportPolicy applies an update changing both the size and the export policy as two
+// sequential updates, verifying each one. The backend occasionally applies a combined update only partially,
+// leaving the export rules stale.
+func updateVolumeSizeThenExportPolicy(client *Client, volume volumeRequest) error {
+	sizeUpdate := volume
+	sizeUpdate.ExportPolicy = exportPolicy{}
+	if err := client.updateVolume(sizeUpdate); err != nil {
+		return err
+	}
+	res, err := waitForVolumeAvailable(client, volume.Region, volume.VolumeID, "", volume.Deadline)
+	if err != nil {
+		return err
+	}
+	if res.Size != volume.Size {
+		return fmt.Errorf("size of volume %s is %d GiB after update, expected %d GiB", volume.VolumeID, sizeInGiB(res.Size), sizeInGiB(volume.Size))
+	}
+
+	exportPolicyUpdate := volumeRequest{
+		Region:       volume.Region,
+		VolumeID:     volume.VolumeID,
+		Name:         volume.Name,
+		Size:         volume.Size,
+		ExportPolicy: volume.ExportPolicy,
+	}
+	if err := client.updateVolume(exportPolicyUpdate); err != nil {
+		return err
+	}
+	res, err = waitForVolumeAvailable(client, volume.Region, volume.VolumeID, "", volume.Deadline)
+	if err != nil {
+		return err
+	}
+	if !exportRulesApplied(volume.ExportPolicy, res.ExportPolicy) {
+		return fmt.Errorf("export policy of volume %s was not applied", volume.VolumeID)
+	}
+	return nil
+}
+
+// exportRulesApplied checks that the clients and access of the export rules returned by the API match the
+// requested rules. Whitespace and casing differences are ignored.
+func exportRulesApplied(requested exportPolicy, applied exportPolicy) bool {
+	if len(requested.Rules) != len(applied.Rules) {
+		return false
+	}
+	normalize := func(value string) string {
+		return strings.ToLower(strings.Join(strings.Fields(value), ""))
+	}
+	for i := range requested.Rules {
+		if normalize(requested.Rules[i].AllowedClients) != normalize(applied.Rules[i].AllowedClients) ||
+			normalize(requested.Rules[i].Access) != normalize(applied.Rules[i].Access) {
+			return false
+		}
+	}
+	return true
+}
+
+// resourceGCPVolumeCustomizeDiff validates the service level against the capability table and the provider
+// additional_service_levels, checks the export rules of read-only clones, and plans the replacement of a volume in
+// error state if recreate_on_error is set
+func resourceGCPVolumeCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	var additionalServiceLevels []string
+	if client, ok := meta.(*Client); ok {
+		additionalServiceLevels = client.AdditionalServiceLevels
+	}
+	if err := validateServiceLevel(d.Get("service_level").(string), additionalServiceLevels); err != nil {
+		return err
+	}
+	if err := customizeVolumeSize(d); err != nil {
+		return err
+	}
+
+	if d.Get("read_only").(bool) {
+		if d.NewValueKnown("snapshot_id") && d.Get("snapshot_id").(string) == "" {
+			return fmt.Errorf("read_only requires snapshot_id, only clones can be read-only")
 		}
-		if waitSeconds < threshold {
-			threshold = threshold - 60
-			log.Printf("Volume creation still in progress after %d seconds.\n", elapsed)
+		if v, ok := d.GetOk("export_policy"); ok {
+			if err := checkReadOnlyExportPolicy(expandExportPolicy(v.(*schema.Set))); err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.Id() == "" || !d.Get("recreate_on_error").(bool) || d.Get("lifecycle_state").(string) != "error" {
+		return nil
+	}
+	log.Printf("[WARN] Volume %s is in error state, planning replacement: %s", d.Id(), d.Get("lifecycle_state_details").(string))
+	if err := d.SetNewComputed("lifecycle_state"); err != nil {
+		return err
+	}
+	return d.ForceNew("lifecycle_state")
+}
+
+// customizeVolumeSize plans size and size_in_gib with the same value, taken from the one that is configured or
+// changed, so CRUD functions only use size. The size is checked against the range of the service level.
+func customizeVolumeSize(d *schema.ResourceDiff) error {
+	key := ""
+	switch {
+	case d.Id() == "":
+		if _, ok := d.GetOk("size_in_gib"); ok || d.HasChange("size_in_gib") {
+			key = "size_in_gib"
+		} else if _, ok := d.GetOk("size"); ok || d.HasChange("size") {
+			key = "size"
+		} else {
+			return fmt.Errorf("one of size or size_in_gib must be set")
+		}
+	case d.HasChange("size_in_gib"):
+		key = "size_in_gib"
+	case d.HasChange("size"):
+		key = "size"
+	case d.HasChange("service_level"):
+		return validateVolumeSize(d.Get("size").(int), d.Get("service_level").(string))
+	default:
+		return nil
+	}
+	// Both keys are set, size first: the SDK clears the diff of every key prefixed with the key being set
+	if !d.NewValueKnown(key) {
+		if err := d.SetNewComputed("size"); err != nil {
+			return err
+		}
+		return d.SetNewComputed("size_in_gib")
+	}
+	size := d.Get(key).(int)
+	if err := validateVolumeSize(size, d.Get("service_level").(string)); err != nil {
+		return err
+	}
+	if old, _ := d.GetChange("size"); d.Id() != "" && size < old.(int) && !d.Get("allow_shrink").(bool) {
+		return fmt.Errorf("size of volume %s would shrink from %d to %d GiB. Shrinking a volume below its used capacity fails at apply time and risks data loss, set allow_shrink = true to shrink it", d.Id(), old.(int), size)
+	}
+	if err := d.SetNew("size", size); err != nil {
+		return err
+	}
+	return d.SetNew("size_in_gib", size)
+}
+
+// keepAllowVPC carries allow_vpc of the configured export rules over to the rules read from the API. The API only
+// returns the expanded IP ranges, so allowed_clients of these rules is kept as configured. Rules are matched by position.
+func keepAllowVPC(flattened interface{}, configured *schema.Set) {
+	var configuredRules []interface{}
+	for _, v := range configured.List() {
+		configuredRules = v.(map[string]interface{})["rule"].([]interface{})
+	}
+	for i, rule := range flattened.([]map[string]interface{})[0]["rule"].([]map[string]interface{}) {
+		rule["allow_vpc"] = false
+		if i >= len(configuredRules) {
+			continue
+		}
+		configuredRule := configuredRules[i].(map[string]interface{})
+		if configuredRule["allow_vpc"].(bool) {
+			rule["allow_vpc"] = true
+			rule["allowed_clients"] = configuredRule["allowed_clients"]
+		}
+	}
+}
+
+// keepOmittedNFSVersions carries the configured nfsv3 and nfsv4 blocks of the export rules over to the rules read
+// from the API whose response omitted them, so a missing block doesn't show as a change. Rules are matched by position.
+func keepOmittedNFSVersions(flattened interface{}, configured *schema.Set) {
+	var configuredRules []interface{}
+	for _, v := range configured.List() {
+		configuredRules = v.(map[string]interface{})["rule"].([]interface{})
+	}
+	for i, rule := range flattened.([]map[string]interface{})[0]["rule"].([]map[string]interface{}) {
+		if i >= len(configuredRules) {
+			continue
+		}
+		configuredRule := configuredRules[i].(map[string]interface{})
+		for _, key := range []string{"nfsv3", "nfsv4"} {
+			if len(rule[key].([]map[string]interface{})) == 0 {
+				rule[key] = configuredRule[key].(*schema.Set).List()
+			}
 		}
-		waitSeconds = waitSeconds - int(timeSleep)
 	}
-	return volumeRes, nil
 }
 
 // A bug might be presented in the API. A volume creation request is acknowledged(volume ID is returned), but get volume by ID doesn't find any result.
@@ -495,12 +1064,12 @@ func waitForVolumeCreationComplete(client *Client, volumeRes volumeResult) (volu
 func validateVolumeExistsAfterCreate(client *Client, volume volumeRequest, volumeID string, volType string) (volumeResult, error) {
 	volumeRes, err := client.getVolumeByID(volumeRequest{Region: volume.Region, VolumeID: volumeID})
 	var res createVolumeResult
-	network := volume.Network
 	retries := 3
 	if err != nil {
 		for err != nil && err.Error() == "code: 404, message: Error describing volume - Volume not found" && retries > 0 {
-			time.Sleep(20 * time.Second)
-			volume.Network = network
+			if err := client.sleep(client.pollInterval(20 * time.Second)); err != nil {
+				return volumeResult{}, err
+			}
 			res, err = client.createVolume(&volume, volType)
 			if err != nil {
 				return volumeResult{}, err
@@ -532,21 +1101,31 @@ func resourceGCPVolumeRead(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	waitSeconds := 300
-	for waitSeconds > 0 && (res.LifeCycleState == "creating" || res.LifeCycleState == "deleting" || res.LifeCycleState == "updating") {
-		time.Sleep(20)
+	wait := 300 * time.Second
+	// volumes created without waiting for them are not waited for when refreshed either
+	if waitForState := d.Get("wait_for_state").(string); waitForState == "creating" || waitForState == "any" {
+		wait = 0
+	}
+	interval := client.pollInterval(10 * time.Second)
+	for wait > 0 && (res.LifeCycleState == "creating" || res.LifeCycleState == "deleting" || res.LifeCycleState == "updating") {
+		if err := client.sleep(interval); err != nil {
+			return err
+		}
 		res, err = client.getVolumeByID(volumeRequest{Region: volume.Region, VolumeID: id})
 		if err != nil {
 			return err
 		}
-		waitSeconds = waitSeconds - 10
+		wait = wait - interval
 	}
 
 	if res.VolumeID != id {
 		return fmt.Errorf("Expected Volume ID %v, Response contained Volume ID %v", id, res.VolumeID)
 	}
 
-	if res.LifeCycleState == "error" {
+	if res.LifeCycleState == "error" && d.Get("recreate_on_error").(bool) {
+		log.Printf("[WARN] Volume with name: %v and id: %v is in error state and will be replaced. LifeCycleStateDetails: %v",
+			res.Name, res.VolumeID, res.LifeCycleStateDetails)
+	} else if res.LifeCycleState == "error" {
 		return fmt.Errorf("Volume with name: %v and id: %v is in error state. Please manually delete the volume, make sure the config is correct and run terraform apply agian. LifeCycleStateDetails: %v",
 			res.Name, res.VolumeID, res.LifeCycleStateDetails)
 	} else if res.LifeCycleState == "disabled" {
@@ -557,26 +1136,28 @@ func resourceGCPVolumeRead(d *schema.ResourceData, meta interface{}) error {
 		return nil
 	}
 
-	if err := d.Set("size", res.Size/GiBToBytes); err != nil {
+	if err := d.Set("lifecycle_state", res.LifeCycleState); err != nil {
+		return fmt.Errorf("Error reading volume lifecycle_state: %s", err)
+	}
+	if err := d.Set("lifecycle_state_details", res.LifeCycleStateDetails); err != nil {
+		return fmt.Errorf("Error reading volume lifecycle_state_details: %s", err)
+	}
+	if err := d.Set("name", res.Name); err != nil {
+		return fmt.Errorf("Error reading volume name: %s", err)
+	}
+	if err := d.Set("type_dp", res.TypeDP); err != nil {
+		return fmt.Errorf("Error reading volume type_dp: %s", err)
+	}
+	if err := d.Set("size", sizeInGiB(res.Size)); err != nil {
 		return fmt.Errorf("Error reading volume size: %s", err)
 	}
-
-	log.Printf("**** API response service level is %s", res.ServiceLevel)
-	/*
-		There is a bug on API. Translate the response with right value
-		API Respose:  Real Value
-		basic      :  standard
-		standard   :  premium
-		extreme    :  extreme
-	*/
-	var slevel = res.ServiceLevel
-
-	if res.ServiceLevel == "basic" {
-		slevel = "standard"
-	} else if res.ServiceLevel == "standard" {
-		slevel = "premium"
+	if err := d.Set("size_in_gib", sizeInGiB(res.Size)); err != nil {
+		return fmt.Errorf("Error reading volume size_in_gib: %s", err)
 	}
 
+	// the API doesn't return the service level it was given, see serviceLevelCapabilities
+	slevel := serviceLevelFromAPI(res.ServiceLevel)
+
 	if err := d.Set("service_level", slevel); err != nil {
 		return fmt.Errorf("Error reading volume service_level: %s", err)
 	}
@@ -585,20 +1166,27 @@ func resourceGCPVolumeRead(d *schema.ResourceData, meta interface{}) error {
 			res.ProtocolTypes[i] = "SMB"
 		}
 	}
-	if err := d.Set("protocol_types", res.ProtocolTypes); err != nil {
+	if err := d.Set("protocol_types", sortProtocolTypes(res.ProtocolTypes, d.Get("protocol_types").([]interface{}))); err != nil {
 		return fmt.Errorf("Error reading volume protocol_types: %s", err)
 	}
 	if err := d.Set("volume_path", res.CreationToken); err != nil {
 		return fmt.Errorf("Error reading volume path or Creation Token: %s", err)
 	}
-	network := res.Network
-	index := strings.Index(network, "networks/")
-	if index > -1 {
-		network = network[index+len("networks/"):]
-	}
-	if err := d.Set("network", network); err != nil {
+	if err := d.Set("network", networkShortName(res.Network)); err != nil {
 		return fmt.Errorf("Error reading volume network: %s", err)
 	}
+	if err := d.Set("network_full_path", res.Network); err != nil {
+		return fmt.Errorf("Error reading volume network_full_path: %s", err)
+	}
+	// a network in another project is a shared VPC, e.g. of an imported volume
+	if project := networkProject(res.Network); project != "" && project != client.GetProjectID() {
+		if err := d.Set("shared_vpc_project_number", project); err != nil {
+			return fmt.Errorf("Error reading volume shared_vpc_project_number: %s", err)
+		}
+	}
+	if err := d.Set("volume_id", res.VolumeID); err != nil {
+		return fmt.Errorf("Error reading volume volume_id: %s", err)
+	}
 	if err := d.Set("region", res.Region); err != nil {
 		return fmt.Errorf("Error reading volume region: %s", err)
 	}
@@ -607,7 +1195,22 @@ func resourceGCPVolumeRead(d *schema.ResourceData, meta interface{}) error {
 	if err := d.Set("snapshot_policy", snapshotPolicy); err != nil {
 		return fmt.Errorf("Error reading volume snapshot_policy: %s", err)
 	}
-	if len(res.ExportPolicy.Rules) > 0 {
+	if err := d.Set("backup_policy", flattenBackupPolicy(res.BackupPolicy)); err != nil {
+		return fmt.Errorf("Error reading volume backup_policy: %s", err)
+	}
+	// exports stay disabled as long as the volume has no export rules
+	exportsDisabled := d.Get("exports_disabled").(bool) && len(res.ExportPolicy.Rules) == 0
+	if err := d.Set("exports_disabled", exportsDisabled); err != nil {
+		return fmt.Errorf("Error reading volume exports_disabled: %s", err)
+	}
+	// export rules inherited from another volume are not tracked in export_policy
+	if _, ok := d.GetOk("export_policy_from_volume_id"); ok {
+		log.Print("export_policy_from_volume_id is set, skip reading export_policy")
+	} else if exportsDisabled {
+		log.Print("exports_disabled is set, keep the export_policy to restore")
+	} else if len(res.ExportPolicy.Rules) > 0 {
+		keepAllowVPC(exportPolicy, d.Get("export_policy").(*schema.Set))
+		keepOmittedNFSVersions(exportPolicy, d.Get("export_policy").(*schema.Set))
 		if err := d.Set("export_policy", exportPolicy); err != nil {
 			return fmt.Errorf("Error reading volume export_policy: %s", err)
 		}
@@ -617,10 +1220,33 @@ func resourceGCPVolumeRead(d *schema.ResourceData, meta interface{}) error {
 			return fmt.Errorf("Error reading volume export_policy: %s", err)
 		}
 	}
+	sortMountPoints(res.MountPoints)
 	mountPoints := flattenMountPoints(res.MountPoints)
 	if err := d.Set("mount_points", mountPoints); err != nil {
 		return fmt.Errorf("Error reading volume mount_points: %s", err)
 	}
+	labels := res.Labels
+	if client.AutoLabeling {
+		labels = stripAutoLabels(labels)
+	}
+	if err := d.Set("labels", labels); err != nil {
+		return fmt.Errorf("Error reading volume labels: %s", err)
+	}
+	if err := d.Set("smb_share_settings", res.SmbShareSettings); err != nil {
+		return fmt.Errorf("Error reading volume smb_share_settings: %s", err)
+	}
+	if err := d.Set("snapshot_directory", res.SnapshotDirectory); err != nil {
+		return fmt.Errorf("Error reading volume snapshot_directory: %s", err)
+	}
+	if err := d.Set("smb_share_name", smbShareName(res.MountPoints)); err != nil {
+		return fmt.Errorf("Error reading volume smb_share_name: %s", err)
+	}
+	if err := d.Set("nfs_mount_command", nfsMountCommand(res.MountPoints)); err != nil {
+		return fmt.Errorf("Error reading volume nfs_mount_command: %s", err)
+	}
+	if err := d.Set("smb_unc_path", smbUNCPath(res.MountPoints)); err != nil {
+		return fmt.Errorf("Error reading volume smb_unc_path: %s", err)
+	}
 	if _, ok := d.GetOk("zone"); ok {
 		if err := d.Set("zone", res.Zone); err != nil {
 			return fmt.Errorf("Error reading volume zone: %s", err)
@@ -635,7 +1261,93 @@ func resourceGCPVolumeRead(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
+// resourceGCPVolumeImport imports a volume by an ID of the form <region>:<volume id>, since volumes are looked up per
+// region, or <region>/<name> where name is the volume path or the name of the volume
+func resourceGCPVolumeImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	log.Printf("Importing volume: %s", d.Id())
+	client := meta.(*Client)
+
+	var res volumeResult
+	var region, id string
+	var err error
+	if strings.Contains(d.Id(), ":") {
+		region, id, err = parseRegionID(d.Id())
+		if err != nil {
+			return nil, err
+		}
+		res, err = client.getVolumeByID(volumeRequest{Region: region, VolumeID: id})
+		if err != nil {
+			return nil, err
+		}
+		if res.VolumeID != id {
+			return nil, fmt.Errorf("volume with id: %s not found in region %s", id, region)
+		}
+	} else {
+		var name string
+		region, name, err = parseRegionName(d.Id())
+		if err != nil {
+			return nil, fmt.Errorf("expected an import ID of the form <region>:<id> or <region>/<name>, got %q", d.Id())
+		}
+		// the volume path is unique in the region, names need not be
+		res, err = client.getVolumeByNameOrCreationToken(volumeRequest{Region: region, CreationToken: name})
+		if err != nil {
+			res, err = client.getVolumeByNameOrCreationToken(volumeRequest{Region: region, Name: name})
+			if err != nil {
+				return nil, fmt.Errorf("no volume with volume path or unique name %s found in region %s: %s", name, region, err)
+			}
+		}
+		id = res.VolumeID
+	}
+	d.SetId(id)
+	if err := d.Set("region", region); err != nil {
+		return nil, fmt.Errorf("Error importing volume region: %s", err)
+	}
+	// Read only refreshes zone and storage_class if they are configured
+	if err := d.Set("zone", res.Zone); err != nil {
+		return nil, fmt.Errorf("Error importing volume zone: %s", err)
+	}
+	if err := d.Set("storage_class", res.StorageClass); err != nil {
+		return nil, fmt.Errorf("Error importing volume storage_class: %s", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceGCPVolumeDelete(d *schema.ResourceData, meta interface{}) error {
+	if d.Get("deletion_protection").(bool) {
+		return fmt.Errorf("cannot delete volume %s with id: %s, deletion_protection is set. Set deletion_protection = false and apply before deleting the volume", d.Get("name").(string), d.Id())
+	}
+	client := meta.(*Client)
+	release := client.acquireJob("deleteVolume")
+	defer release()
+	region := d.Get("region").(string)
+	// the deletion job runs until the volume is gone, so the queue is left after the wait
+	releaseDelete := client.deletes.acquire(region, "deleteVolume")
+	defer releaseDelete()
+	// the delete timeout starts when the volume's turn comes, not while it waits behind the other deletions, and
+	// covers the deletion of its snapshots
+	deadline := time.Now().Add(d.Timeout(schema.TimeoutDelete))
+	if client.DeleteSnapshotsOnDestroy || d.Get("delete_snapshots_on_destroy").(bool) {
+		if err := client.deleteVolumeSnapshots(region, d.Id(), deadline); err != nil {
+			return err
+		}
+	}
+	return deleteGCPVolumeUntil(d, meta, deadline)
+}
+
+// deleteGCPVolume deletes the volume and waits for it to be gone, regardless of deletion_protection
+func deleteGCPVolume(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	region := d.Get("region").(string)
+	release := client.deletes.acquire(region, "deleteVolume")
+	defer release()
+	// the delete timeout starts when the volume's turn comes
+	return deleteGCPVolumeUntil(d, meta, time.Now().Add(d.Timeout(schema.TimeoutDelete)))
+}
+
+// deleteGCPVolumeUntil deletes the volume and waits for it to be gone until the deadline. The caller holds the slot
+// of the volume in the deletes queue of its region.
+func deleteGCPVolumeUntil(d *schema.ResourceData, meta interface{}, deadline time.Time) error {
 	log.Printf("Deleting volume: %#v", d)
 
 	volume := volumeRequest{}
@@ -645,7 +1357,11 @@ func resourceGCPVolumeDelete(d *schema.ResourceData, meta interface{}) error {
 
 	id := d.Id()
 	volume.VolumeID = id
+	volume.Deadline = deadline
 
+	if err := client.waitForVolumeJobs(volume.Region, id, volume.Deadline); err != nil {
+		return err
+	}
 	deleteErr := client.deleteVolume(volume)
 	if deleteErr != nil {
 		return deleteErr
@@ -658,27 +1374,27 @@ func resourceGCPVolumeDelete(d *schema.ResourceData, meta interface{}) error {
 	if getVolume.LifeCycleState == "deleted" {
 		return nil
 	} else if getVolume.LifeCycleState == "deleting" {
-		waitTime := 300
-		for waitTime > 0 {
-			time.Sleep(20 * time.Second)
-			waitTime = waitTime - 20
-			getVolume, err = client.getVolumeByID(volume)
-			if err != nil {
-				return err
-			}
-			if getVolume.LifeCycleState == "deleted" {
-				return nil
-			}
-			if getVolume.LifeCycleState == "error" {
-				break
-			}
+		getVolume, err = client.waitForVolumeState(volumeWait{
+			region:   volume.Region,
+			volumeID: id,
+			pending:  []string{"deleting"},
+			target:   []string{"deleted", "error"},
+			interval: 20 * time.Second,
+			deadline: volume.Deadline,
+		})
+		if err != nil {
+			return err
+		}
+		if getVolume.LifeCycleState == "deleted" {
+			return nil
 		}
 		// if volume is in error state when deleting, retry.
 	}
 	if getVolume.LifeCycleState == "error" {
-		retries := 3
-		for getVolume.LifeCycleState == "error" && retries > 0 {
-			time.Sleep(time.Duration(nextRandomInt(5, 20)) * time.Second)
+		for attempt := 1; getVolume.LifeCycleState == "error" && attempt <= 3; attempt++ {
+			if err := client.backoff(attempt); err != nil {
+				return err
+			}
 			deleteErr := client.deleteVolume(volume)
 			if deleteErr != nil {
 				return deleteErr
@@ -729,13 +1445,16 @@ func resourceGCPVolumeExists(d *schema.ResourceData, meta interface{}) (bool, er
 func resourceGCPVolumeUpdate(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("Updating volume: %#v\n", d)
 	makechange := 0
+	// changes of the name and labels only update metadata, the other changes spawn a job of the backend
+	spawnsJob := false
 	client := meta.(*Client)
 	volume := volumeRequest{}
 	volume.VolumeID = d.Id()
 	volume.Region = d.Get("region").(string)
 	volume.Name = d.Get("name").(string)
+	volume.Deadline = time.Now().Add(d.Timeout(schema.TimeoutUpdate))
 	// size is always required.
-	volume.Size = d.Get("size").(int) * GiBToBytes
+	volume.Size = sizeInBytes(d.Get("size").(int))
 
 	if d.HasChange("name") {
 		makechange = 1
@@ -743,20 +1462,89 @@ func resourceGCPVolumeUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	if d.HasChange("size") {
 		makechange = 1
+		spawnsJob = true
+	}
+
+	// extra parameters are sent with every update, new API fields may be required in full
+	extraParameters, err := expandExtraRequestParameters(d.Get("extra_request_parameters").(string))
+	if err != nil {
+		return err
+	}
+	volume.ExtraParameters = extraParameters
+	if d.HasChange("extra_request_parameters") {
+		makechange = 1
+		spawnsJob = true
 	}
 
 	if d.HasChange("snapshot_policy") {
 		if len(d.Get("snapshot_policy").([]interface{})) > 0 {
-			policy := d.Get("snapshot_policy").([]interface{})[0].(map[string]interface{})
-			volume.SnapshotPolicy = expandSnapshotPolicy(policy)
+			policy := expandSnapshotPolicy(d.Get("snapshot_policy").([]interface{})[0].(map[string]interface{}))
+			volume.SnapshotPolicy = &policy
+			makechange = 1
+			spawnsJob = true
+		}
+	}
+
+	if d.HasChange("backup_policy") {
+		if v := d.Get("backup_policy").([]interface{}); len(v) > 0 && v[0] != nil {
+			policy := expandBackupPolicy(v[0].(map[string]interface{}))
+			volume.BackupPolicy = &policy
 			makechange = 1
+			spawnsJob = true
+		}
+	}
+
+	exportPolicyChanged := d.HasChange("export_policy") || d.HasChange("exports_disabled") || d.HasChange("export_policy_from_volume_id")
+	if exportPolicyChanged {
+		_, copied := d.GetOk("export_policy_from_volume_id")
+		switch {
+		case d.Get("exports_disabled").(bool):
+			volume.ExportPolicy = disabledExportPolicy()
+		case copied && (d.HasChange("exports_disabled") || d.HasChange("export_policy_from_volume_id")):
+			// copy the export rules again, from the volume set now, or to restore them after exports_disabled
+			sourceVolume, err := client.getVolumeByID(volumeRequest{Region: volume.Region, VolumeID: d.Get("export_policy_from_volume_id").(string)})
+			if err != nil {
+				log.Print("Error reading export policy from source volume")
+				return err
+			}
+			// the rules of the source volume may allow writes since the clone was created
+			if d.Get("read_only").(bool) {
+				if err := checkReadOnlyExportPolicy(sourceVolume.ExportPolicy); err != nil {
+					return fmt.Errorf("cannot copy the export policy of volume %s from volume %s: %s", d.Id(), d.Get("export_policy_from_volume_id").(string), err)
+				}
+			}
+			volume.ExportPolicy = sourceVolume.ExportPolicy
+		default:
+			policy := d.Get("export_policy").(*schema.Set)
+			volume.ExportPolicy = expandExportPolicy(policy)
+			network := volumeRequest{Network: d.Get("network").(string), SharedVpcProjectNumber: d.Get("shared_vpc_project_number").(string)}
+			if err := client.expandVPCExportRules(&volume.ExportPolicy, client.networkFullPath(network)); err != nil {
+				return err
+			}
+		}
+		makechange = 1
+		spawnsJob = true
+	}
+
+	if d.HasChange("labels") {
+		volume.Labels = expandStringList(d.Get("labels").([]interface{}))
+		if client.AutoLabeling {
+			volume.Labels = withAutoLabels(volume.Labels, autoLabels())
 		}
+		makechange = 1
+	}
+
+	if d.HasChange("smb_share_settings") {
+		volume.SmbShareSettings = expandStringList(d.Get("smb_share_settings").([]interface{}))
+		makechange = 1
+		spawnsJob = true
 	}
 
-	if d.HasChange("export_policy") {
-		policy := d.Get("export_policy").(*schema.Set)
-		volume.ExportPolicy = expandExportPolicy(policy)
+	if d.HasChange("snapshot_directory") {
+		snapshotDirectory := d.Get("snapshot_directory").(bool)
+		volume.SnapshotDirectory = &snapshotDirectory
 		makechange = 1
+		spawnsJob = true
 	}
 
 	if d.HasChange("service_level") {
@@ -765,11 +1553,25 @@ func resourceGCPVolumeUpdate(d *schema.ResourceData, meta interface{}) error {
 		oslevel := o.(string)
 
 		log.Printf("Updating volume: service_level old=%v new=%v\n", oslevel, slevel)
-		volume.ServiceLevel = TranslateServiceLevelState2API(slevel)
+		volume.ServiceLevel = serviceLevelToAPI(slevel)
 		makechange = 1
+		spawnsJob = true
+	}
+
+	revert := d.HasChange("refresh_from_snapshot_id") && d.Get("refresh_from_snapshot_id").(string) != ""
+	if spawnsJob || revert {
+		release := client.acquireJob("updateVolume")
+		defer release()
+		// the update timeout starts when the job can run, not while it waits for a job slot
+		volume.Deadline = time.Now().Add(d.Timeout(schema.TimeoutUpdate))
 	}
 
-	if makechange == 1 {
+	if makechange == 1 && d.HasChange("size") && exportPolicyChanged {
+		log.Println("Make change on volume in two steps: size first, then export policy")
+		if err := updateVolumeSizeThenExportPolicy(client, volume); err != nil {
+			return err
+		}
+	} else if makechange == 1 {
 		log.Println("Make change on volume")
 		err := client.updateVolume(volume)
 		if err != nil {
@@ -779,5 +1581,20 @@ func resourceGCPVolumeUpdate(d *schema.ResourceData, meta interface{}) error {
 		log.Println("NOT updateVolume")
 	}
 
+	// refresh the volume data from a snapshot when the trigger attribute changes
+	if d.HasChange("refresh_from_snapshot_id") {
+		if v, ok := d.GetOk("refresh_from_snapshot_id"); ok {
+			log.Printf("Reverting volume %s to snapshot %s", volume.VolumeID, v.(string))
+			jobID, err := client.revertVolume(revertVolumeRequest{Region: volume.Region, VolumeID: volume.VolumeID, SnapshotID: v.(string)})
+			if err != nil {
+				return err
+			}
+			_, err = waitForVolumeAvailable(client, volume.Region, volume.VolumeID, jobID, volume.Deadline)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	return resourceGCPVolumeRead(d, meta)
 }
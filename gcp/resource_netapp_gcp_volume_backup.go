@@ -54,6 +54,10 @@ func resourceGCPVolumeBackupCreate(d *schema.ResourceData, meta interface{}) err
 	volumeBackup.Name = d.Get("name").(string)
 	volumeBackup.Region = d.Get("region").(string)
 
+	if err := client.requireFeature(volumeBackup.Region, featureBackups); err != nil {
+		return err
+	}
+
 	volume := volumeRequest{}
 	volume.Region = volumeBackup.Region
 
@@ -70,8 +74,9 @@ func resourceGCPVolumeBackupCreate(d *schema.ResourceData, meta interface{}) err
 		}
 		if volresult.LifeCycleStateDetails != "Available for use" {
 			if retries < 30 {
-				log.Printf("Volume %s is not ready. Wait for 10 seconds and check again.\n", volume.Name)
-				time.Sleep(10 * time.Second)
+				interval := client.pollInterval(10 * time.Second)
+				log.Printf("Volume %s is not ready. Wait for %s and check again.\n", volume.Name, interval)
+				time.Sleep(interval)
 				retries++
 			} else {
 				log.Printf("Volume %s is not ready.\n", volume.Name)
@@ -83,6 +88,9 @@ func resourceGCPVolumeBackupCreate(d *schema.ResourceData, meta interface{}) err
 		}
 	}
 
+	release := client.acquireJob("createVolumeBackup")
+	defer release()
+
 	res, err := client.createVolumeBackup(&volumeBackup)
 	if err != nil {
 		log.Print("Error creating VolumeBackup")
@@ -157,6 +165,9 @@ func resourceGCPVolumeBackupDelete(d *schema.ResourceData, meta interface{}) err
 	id := d.Id()
 	volumeBackup.VolumeBackupID = id
 
+	release := client.acquireJob("deleteVolumeBackup")
+	defer release()
+
 	deleteErr := client.deleteVolumeBackup(volumeBackup)
 	if deleteErr != nil {
 		return deleteErr
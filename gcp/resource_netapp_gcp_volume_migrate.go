@@ -0,0 +1,409 @@
+package gcp
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// resourceGCPVolumeV0 is the schema of version 0 of the volume state, frozen so the state of older versions keeps
+// decoding as the volume schema changes. Only the types of the attributes matter, so validations are left out.
+func resourceGCPVolumeV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"type_dp": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"protocol_types": {
+				Type:     schema.TypeList,
+				Required: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"network": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"network_full_path": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"size": {
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+			"service_level": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "medium",
+			},
+			"volume_path": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"shared_vpc_project_number": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"mount_points": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"export": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+						},
+						"server": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+						},
+						"protocol_type": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"snapshot_policy": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Computed: true,
+						},
+						"daily_schedule": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"hour": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+									"minute": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+									"snapshots_to_keep": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+								},
+							},
+						},
+						"hourly_schedule": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"minute": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+									"snapshots_to_keep": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+								},
+							},
+						},
+						"monthly_schedule": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"days_of_month": {
+										Type:     schema.TypeString,
+										Optional: true,
+										Default:  "1",
+									},
+									"hour": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+									"minute": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+									"snapshots_to_keep": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+								},
+							},
+						},
+						"weekly_schedule": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"day": {
+										Type:     schema.TypeString,
+										Optional: true,
+										Default:  "Sunday",
+									},
+									"hour": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+									"minute": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+									"snapshots_to_keep": {
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  0,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"backup_policy": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"daily_backups_to_keep": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  0,
+						},
+						"weekly_backups_to_keep": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  0,
+						},
+						"monthly_backups_to_keep": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  0,
+						},
+					},
+				},
+			},
+			"export_policy": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"rule": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"access": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"allowed_clients": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"allow_vpc": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+									},
+									"has_root_access": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  true,
+									},
+									"kerberos5_readonly": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+									},
+									"kerberos5_readwrite": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+									},
+									"kerberos5i_readonly": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+									},
+									"kerberos5i_readwrite": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+									},
+									"kerberos5p_readonly": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+									},
+									"kerberos5p_readwrite": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+									},
+									"nfsv3": {
+										Type:     schema.TypeSet,
+										Optional: true,
+										Elem: &schema.Resource{
+											Schema: map[string]*schema.Schema{
+												"checked": {
+													Type:     schema.TypeBool,
+													Optional: true,
+												},
+											},
+										},
+									},
+									"nfsv4": {
+										Type:     schema.TypeSet,
+										Optional: true,
+										Elem: &schema.Resource{
+											Schema: map[string]*schema.Schema{
+												"checked": {
+													Type:     schema.TypeBool,
+													Optional: true,
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"export_policy_from_volume_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"labels": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"kms_key_ring": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"crypto_key": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"snapshot_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"read_only": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"refresh_from_snapshot_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"smb_share_settings": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"snapshot_directory": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+			"smb_share_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"recreate_on_error": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"lifecycle_state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"lifecycle_state_details": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"delete_on_creation_error": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"zone": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"storage_class": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+// resourceGCPVolumeStateUpgradeV0 rewrites a network stored as the full network path to the network name, so
+// volumes don't plan a replacement after the upgrade
+func resourceGCPVolumeStateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	if network, ok := rawState["network"].(string); ok {
+		if shortName := networkShortName(network); shortName != network {
+			log.Printf("Upgrading volume network %s to %s", network, shortName)
+			rawState["network"] = shortName
+		}
+	}
+	return rawState, nil
+}
@@ -69,6 +69,14 @@ func TestAccVolume_basic(t *testing.T) {
 					testCheckResourceAttr("netapp-gcp_volume.terraform-acceptance-test-1", "snapshot_policy.0.monthly_schedule.0.days_of_month", "15"),
 				),
 			},
+			{
+				ResourceName:      "netapp-gcp_volume.terraform-acceptance-test-1",
+				ImportState:       true,
+				ImportStateIdFunc: testAccVolumeImportStateID("netapp-gcp_volume.terraform-acceptance-test-1"),
+				ImportStateVerify: true,
+				// arguments that are only in the configuration
+				ImportStateVerifyIgnore: []string{"recreate_on_error", "delete_on_creation_error", "allow_shrink", "deletion_protection", "delete_snapshots_on_destroy", "extra_request_parameters", "wait_for_state", "wait_for_snapshot_policy"},
+			},
 			// remove temporarily since us-west2 is not working.
 			// {
 			// 	Config: testAccVolumeConfigCreateSMB(),
@@ -88,6 +96,16 @@ func TestAccVolume_basic(t *testing.T) {
 	})
 }
 
+func testAccVolumeImportStateID(name string) resource.ImportStateIdFunc {
+	return func(state *terraform.State) (string, error) {
+		rs, ok := state.RootModule().Resources[name]
+		if !ok {
+			return "", fmt.Errorf("Not found: %s", name)
+		}
+		return fmt.Sprintf("%s:%s", rs.Primary.Attributes["region"], rs.Primary.ID), nil
+	}
+}
+
 func testAccCheckGCPVolumeDestroy(state *terraform.State) error {
 	client := testAccProvider.Meta().(*Client)
 
@@ -1,18 +1,18 @@
 package gcp
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
-	"github.com/fatih/structs"
+	"github.com/hashicorp/terraform/helper/resource"
 )
 
 // createSnapshotRequest the users input for creating a Snapshot
 type createSnapshotRequest struct {
-	Name     string `structs:"name"`
-	Region   string `structs:"region"`
-	VolumeID string `structs:"volumeId"`
+	Name     string `json:"name"`
+	Region   string `json:"region"`
+	VolumeID string `json:"volumeId"`
 }
 
 // createSnapshotResult the api rsponse for creating a Snapshot
@@ -32,30 +32,33 @@ type listSnapshotIDResult struct {
 
 // deleteSnapshotRequest the user input for deleteing a Snapshot
 type deleteSnapshotRequest struct {
-	SnapshotID string `structs:"snapshotId"`
-	Region     string `structs:"region"`
-	VolumeID   string `structs:"volumeId"`
+	SnapshotID string `json:"snapshotId"`
+	Region     string `json:"region"`
+	VolumeID   string `json:"volumeId"`
 }
 
 // listSnapshotResult lists the volume for given Snapshot ID
 type listSnapshotResult struct {
 	SnapshotID     string `json:"snapshotId"`
+	Name           string `json:"name"`
+	Created        string `json:"created"`
+	UsedBytes      int64  `json:"usedBytes"`
 	LifeCycleState string `json:"lifeCycleState"`
 }
 
 // listSnapshotRequest requests the volume for given Snapshot ID and region
 type listSnapshotRequest struct {
-	SnapshotID string `structs:"snapshotId"`
-	Region     string `structs:"region"`
-	VolumeID   string `structs:"volumeId"`
+	SnapshotID string `json:"snapshotId"`
+	Region     string `json:"region"`
+	VolumeID   string `json:"volumeId"`
 }
 
 // updateSnapshotRequest request update name of a snapshot for given Snapshot ID and name
 type updateSnapshotRequest struct {
-	Name       string `structs:"name"`
-	Region     string `structs:"region"`
-	VolumeID   string `structs:"volumeId"`
-	SnapshotID string `structs:"snapshotId"`
+	Name       string `json:"name"`
+	Region     string `json:"region"`
+	VolumeID   string `json:"volumeId"`
+	SnapshotID string `json:"snapshotId"`
 }
 
 func (c *Client) getSnapshotByID(snapshot listSnapshotRequest) (listSnapshotResult, error) {
@@ -74,8 +77,7 @@ func (c *Client) getSnapshotByID(snapshot listSnapshotRequest) (listSnapshotResu
 	}
 
 	var result listSnapshotResult
-	if err := json.Unmarshal(response, &result); err != nil {
-		log.Print("Failed to unmarshall response from ListVolumes")
+	if err := decodeResponse(response, &result, statusCode, baseURL, "getSnapshotByID"); err != nil {
 		return listSnapshotResult{}, err
 	}
 	if result.LifeCycleState == "deleted" || result.LifeCycleState == "deleting" {
@@ -85,12 +87,40 @@ func (c *Client) getSnapshotByID(snapshot listSnapshotRequest) (listSnapshotResu
 	return result, nil
 }
 
+// listSnapshotsForVolume returns the snapshots of a volume, ignoring deleted snapshots
+func (c *Client) listSnapshotsForVolume(region string, volumeID string) ([]listSnapshotResult, error) {
+	baseURL := fmt.Sprintf("%s/Volumes/%s/Snapshots", region, volumeID)
+
+	statusCode, response, err := c.CallAPIMethod("GET", baseURL, nil)
+	if err != nil {
+		log.Print("listSnapshotsForVolume request failed")
+		return nil, err
+	}
+
+	responseError := apiResponseChecker(statusCode, response, "listSnapshotsForVolume")
+	if responseError != nil {
+		return nil, responseError
+	}
+
+	var result []listSnapshotResult
+	if err := decodeResponse(response, &result, statusCode, baseURL, "listSnapshotsForVolume"); err != nil {
+		return nil, err
+	}
+	snapshots := make([]listSnapshotResult, 0, len(result))
+	for _, snapshot := range result {
+		if snapshot.LifeCycleState != "deleted" && snapshot.LifeCycleState != "deleting" {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+
+	return snapshots, nil
+}
+
 func (c *Client) createSnapshot(request *createSnapshotRequest) (createSnapshotResult, error) {
 
-	params := structs.Map(request)
+	params := request
 
 	baseURL := fmt.Sprintf("%s/Volumes/%s/Snapshots", request.Region, request.VolumeID)
-	log.Printf("Parameters: %v", params)
 
 	statusCode, response, err := c.CallAPIMethod("POST", baseURL, params)
 	if err != nil {
@@ -104,8 +134,7 @@ func (c *Client) createSnapshot(request *createSnapshotRequest) (createSnapshotR
 	}
 
 	var result createSnapshotResult
-	if err := json.Unmarshal(response, &result); err != nil {
-		log.Print("Failed to unmarshall response from CreateSnapshot")
+	if err := decodeResponse(response, &result, statusCode, baseURL, "CreateSnapshot"); err != nil {
 		return createSnapshotResult{}, err
 	}
 
@@ -131,7 +160,7 @@ func (c *Client) deleteSnapshot(request deleteSnapshotRequest) error {
 
 func (c *Client) updateSnapshot(request updateSnapshotRequest) error {
 
-	params := structs.Map(request)
+	params := request
 	baseURL := fmt.Sprintf("%s/Volumes/%s/Snapshots/%s", request.Region, request.VolumeID, request.SnapshotID)
 	statusCode, response, err := c.CallAPIMethod("PUT", baseURL, params)
 	if err != nil {
@@ -147,3 +176,82 @@ func (c *Client) updateSnapshot(request updateSnapshotRequest) error {
 	return nil
 
 }
+
+// deleteVolumeSnapshots deletes all snapshots of the volume, e.g. the ones made by its snapshot policy, and waits
+// until they are gone, so the volume can be deleted. The snapshots are deleted one at a time in the snapshotOperations
+// queue of the volume, until the deadline.
+func (c *Client) deleteVolumeSnapshots(region string, volumeID string, deadline time.Time) error {
+	snapshots, err := c.listSnapshotsForVolume(region, volumeID)
+	if err != nil {
+		return err
+	}
+	for i, snapshot := range snapshots {
+		log.Printf("[INFO] Deleting snapshot %d of %d of volume %s: %s", i+1, len(snapshots), volumeID, snapshot.Name)
+		if err := c.deleteVolumeSnapshot(region, volumeID, snapshot, deadline); err != nil {
+			return fmt.Errorf("Error deleting snapshot %s of volume %s: %s", snapshot.Name, volumeID, err)
+		}
+	}
+	return nil
+}
+
+// deleteVolumeSnapshot deletes a snapshot of the volume and waits for it to be gone within the snapshotOperations
+// queue of the volume
+func (c *Client) deleteVolumeSnapshot(region string, volumeID string, snapshot listSnapshotResult, deadline time.Time) error {
+	c.initOnce.Do(c.init)
+	release := c.snapshotOperations.acquire(volumeID, "deleteSnapshot")
+	defer release()
+	timeout := time.Until(deadline)
+	if timeout <= 0 {
+		return fmt.Errorf("timed out waiting for the snapshots of volume %s to be deleted", volumeID)
+	}
+	if err := c.deleteSnapshot(deleteSnapshotRequest{Region: region, VolumeID: volumeID, SnapshotID: snapshot.SnapshotID}); err != nil {
+		return err
+	}
+	return c.waitForSnapshotJob(region, volumeID, snapshot.SnapshotID, []string{"deleting", "available"}, "deleted", timeout)
+}
+
+// snapshotState returns the lifecycle state of a snapshot, deleted if the snapshot is not found
+func (c *Client) snapshotState(region string, volumeID string, snapshotID string) (string, error) {
+	baseURL := fmt.Sprintf("%s/Volumes/%s/Snapshots/%s", region, volumeID, snapshotID)
+	statusCode, response, err := c.CallAPIMethod("GET", baseURL, nil)
+	if err != nil {
+		return "", err
+	}
+	if responseError := apiResponseChecker(statusCode, response, "snapshotState"); responseError != nil {
+		if statusCode == 404 || responseError.Name == "xUnknown" {
+			return "deleted", nil
+		}
+		return "", responseError
+	}
+	var result listSnapshotResult
+	if err := decodeResponse(response, &result, statusCode, baseURL, "snapshotState"); err != nil {
+		return "", err
+	}
+	return result.LifeCycleState, nil
+}
+
+// waitForSnapshotJob waits for the job of a snapshot to finish, until the snapshot leaves the pending states, for at
+// most the timeout. The backend fails snapshot operations of a volume while another one runs. The pending states
+// include the state before the job starts, e.g. deleted while a created snapshot isn't found yet.
+func (c *Client) waitForSnapshotJob(region string, volumeID string, snapshotID string, pending []string, target string, timeout time.Duration) error {
+	stateConf := &resource.StateChangeConf{
+		// the state is empty until the job starts
+		Pending: append([]string{""}, pending...),
+		Target:  []string{target},
+		Refresh: func() (interface{}, string, error) {
+			state, err := c.snapshotState(region, volumeID, snapshotID)
+			if err != nil {
+				return nil, "", err
+			}
+			if state == "error" {
+				return nil, state, fmt.Errorf("snapshot %s of volume %s is in error state", snapshotID, volumeID)
+			}
+			log.Printf("[DEBUG] Snapshot %s of volume %s is %s", snapshotID, volumeID, state)
+			return state, state, nil
+		},
+		Timeout:      timeout,
+		PollInterval: c.pollInterval(5 * time.Second),
+	}
+	_, err := stateConf.WaitForState()
+	return err
+}
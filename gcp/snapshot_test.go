@@ -0,0 +1,80 @@
+package gcp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestWaitForSnapshotJob(t *testing.T) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/us-east4/Volumes/vol1/Snapshots/snap1":
+			// the snapshot isn't found until the job starts
+			poll := atomic.AddInt32(&polls, 1)
+			if poll == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"code": 404, "message": "Not found"}`)
+				return
+			}
+			state := "creating"
+			if poll > 2 {
+				state = "available"
+			}
+			fmt.Fprintf(w, `{"snapshotId": "snap1", "lifeCycleState": "%s"}`, state)
+		case "/us-east4/Volumes/vol1/Snapshots/snap2":
+			fmt.Fprint(w, `{"snapshotId": "snap2", "lifeCycleState": "error"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"code": 404, "message": "Not found"}`)
+		}
+	}))
+	defer server.Close()
+	client := &Client{Host: server.URL + "/", Token: "opaque-access-token", PollInterval: time.Millisecond}
+
+	if err := client.waitForSnapshotJob("us-east4", "vol1", "snap1", []string{"creating", "deleted"}, "available", time.Minute); err != nil || atomic.LoadInt32(&polls) != 3 {
+		t.Errorf("expected the snapshot to become available after 3 polls, got %v after %d polls", err, polls)
+	}
+	if err := client.waitForSnapshotJob("us-east4", "vol1", "snap2", []string{"creating", "deleted"}, "available", time.Minute); err == nil {
+		t.Error("expected an error for a snapshot in error state")
+	}
+	if err := client.waitForSnapshotJob("us-east4", "vol1", "snap3", []string{"deleting", "available"}, "deleted", time.Minute); err != nil {
+		t.Errorf("expected a missing snapshot to be deleted, got %v", err)
+	}
+}
+
+func TestSnapshotCreateVolumeNotReady(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/us-east4/Volumes" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"volumeId": "v1", "name": "vol1", "region": "us-east4", "lifeCycleState": "creating", "lifeCycleStateDetails": "Creation in progress"}]`)
+	}))
+	defer server.Close()
+	client := &Client{Host: server.URL + "/", Token: "opaque-access-token", PollInterval: time.Millisecond}
+
+	resource := resourceGCPSnapshot()
+	d := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		"name":        "snap1",
+		"region":      "us-east4",
+		"volume_name": "vol1",
+	})
+	err := resource.Create(d, client)
+	if err == nil || !strings.Contains(err.Error(), "not ready") || d.Id() != "" {
+		t.Errorf("expected an error for a volume that isn't ready, got %v, id %q", err, d.Id())
+	}
+	if calls != 4 {
+		t.Errorf("expected the volume to be checked 4 times, got %d", calls)
+	}
+}
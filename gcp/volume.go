@@ -1,36 +1,112 @@
 package gcp
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"time"
 
-	"github.com/fatih/structs"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/restapi"
 )
 
+// contextDeadlineExceededErrorMessage is an example of the error of a backend timeout, naming the internal URL
+// of the service, which differs between deployments
 const contextDeadlineExceededErrorMessage = "Post http://cloud-volumes-service.sde.svc.cluster.local/v2/Volumes: context deadline exceeded"
 const spawnJobCreationErrorMessage = "Error creating volume - Cannot spawn additional jobs. Please wait for the ongoing jobs to finish and try again"
 const spawnJobDeletionErrorMessage = "Error deleting volume - Cannot spawn additional jobs. Please wait for the ongoing jobs to finish and try again"
 
+// retryRules are the transient errors of the API, retried for every call. Calls with a deadline, e.g. volume
+// creation and deletion bounded by the resource timeouts, are retried until the deadline instead of the retries.
+var retryRules = []restapi.RetryRule{
+	{
+		Name:       "the job limit",
+		StatusCode: 500,
+		Matches:    isJobLimitError,
+		Retries:    10,
+	},
+	{
+		Name:       "a backend timeout",
+		StatusCode: 500,
+		Methods:    []string{"POST"},
+		Matches:    isBackendTimeoutError,
+		Retries:    5,
+	},
+}
+
+// cloudVolumesKMS is the encryption type of volumes encrypted with a customer-managed Cloud KMS key
+const cloudVolumesKMS = "CloudVolumesKMS"
+
 // volumeRequest the users input for creating,requesting,updateing a Volume
 // exportPolicy can't set to omitempty because it could be deleted during update.
 type volumeRequest struct {
-	Name                   string         `structs:"name,omitempty"`
-	Region                 string         `structs:"region,omitempty"`
-	CreationToken          string         `structs:"creationToken,omitempty"`
-	ProtocolTypes          []string       `structs:"protocolTypes,omitempty"`
-	Network                string         `structs:"network,omitempty"`
-	Size                   int            `structs:"quotaInBytes,omitempty"`
-	ServiceLevel           string         `structs:"serviceLevel,omitempty"`
-	SnapshotPolicy         snapshotPolicy `structs:"snapshotPolicy,omitempty"`
-	ExportPolicy           exportPolicy   `structs:"exportPolicy"`
-	VolumeID               string         `structs:"volumeId,omitempty"`
-	Zone                   string         `structs:"zone,omitempty"`
-	StorageClass           string         `structs:"storageClass,omitempty"`
-	SharedVpcProjectNumber string
+	Name                   string          `json:"name,omitempty"`
+	Region                 string          `json:"region,omitempty"`
+	CreationToken          string          `json:"creationToken,omitempty"`
+	ProtocolTypes          []string        `json:"protocolTypes,omitempty"`
+	Network                string          `json:"network,omitempty"`
+	Size                   int64           `json:"quotaInBytes,omitempty"`
+	ServiceLevel           string          `json:"serviceLevel,omitempty"`
+	SnapshotPolicy         *snapshotPolicy `json:"snapshotPolicy,omitempty"`
+	BackupPolicy           *backupPolicy   `json:"backupPolicy,omitempty"`
+	ExportPolicy           exportPolicy    `json:"exportPolicy"`
+	VolumeID               string          `json:"volumeId,omitempty"`
+	Zone                   string          `json:"zone,omitempty"`
+	StorageClass           string          `json:"storageClass,omitempty"`
+	Labels                 []string        `json:"labels,omitempty"`
+	SmbShareSettings       []string        `json:"smbShareSettings,omitempty"`
+	SnapshotDirectory      *bool           `json:"snapshotDirectory,omitempty"`
+	EncryptionType         string          `json:"encryptionType,omitempty"`
+	SnapshotID             string          `json:"snapshotId,omitempty"`
+	SharedVpcProjectNumber string          `json:"-"`
+	// Deadline bounds the retries of createVolume and deleteVolume for transient errors, from the resource timeouts
+	Deadline time.Time `json:"-"`
+	// ExtraParameters are merged into the JSON body of the request, overriding the fields above
+	ExtraParameters map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON encodes the request with its extra parameters
+func (r volumeRequest) MarshalJSON() ([]byte, error) {
+	type plainVolumeRequest volumeRequest
+	body, err := json.Marshal(plainVolumeRequest(r))
+	if err != nil || len(r.ExtraParameters) == 0 {
+		return body, err
+	}
+	merged := make(map[string]interface{})
+	if err := json.Unmarshal(body, &merged); err != nil {
+		return nil, err
+	}
+	for key, value := range r.ExtraParameters {
+		merged[key] = value
+	}
+	return json.Marshal(merged)
+}
+
+// expandExtraRequestParameters decodes the extra_request_parameters of a volume, a JSON object
+func expandExtraRequestParameters(value string) (map[string]interface{}, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var parameters map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &parameters); err != nil {
+		return nil, fmt.Errorf("expected extra_request_parameters to be a JSON object: %s", err)
+	}
+	return parameters, nil
+}
+
+// validateExtraRequestParameters checks that extra_request_parameters is a JSON object, and warns that its fields
+// aren't read back. The warning is shown in the output of plan and apply.
+func validateExtraRequestParameters(v interface{}, k string) ([]string, []error) {
+	parameters, err := expandExtraRequestParameters(v.(string))
+	if err != nil {
+		return nil, []error{err}
+	}
+	if len(parameters) == 0 {
+		return nil, nil
+	}
+	return []string{fmt.Sprintf("%s are sent to the API as is. Terraform doesn't read them back, so changes made to these fields outside Terraform aren't detected", k)}, nil
 }
 
 // volumeRequest retrieves the volume attributes from API and convert to struct
@@ -40,9 +116,10 @@ type volumeResult struct {
 	CreationToken         string         `json:"creationToken,omitempty"`
 	ProtocolTypes         []string       `json:"protocolTypes,omitempty"`
 	Network               string         `json:"network,omitempty"`
-	Size                  int            `json:"quotaInBytes,omitempty"`
+	Size                  int64          `json:"quotaInBytes,omitempty"`
 	ServiceLevel          string         `json:"serviceLevel,omitempty"`
 	SnapshotPolicy        snapshotPolicy `json:"snapshotPolicy,omitempty"`
+	BackupPolicy          backupPolicy   `json:"backupPolicy,omitempty"`
 	ExportPolicy          exportPolicy   `json:"exportPolicy,omitempty"`
 	VolumeID              string         `json:"volumeId,omitempty"`
 	LifeCycleState        string         `json:"lifeCycleState"`
@@ -51,6 +128,10 @@ type volumeResult struct {
 	Zone                  string         `json:"zone,omitempty"`
 	StorageClass          string         `json:"storageClass,omitempty"`
 	TypeDP                bool           `json:"isDataProtection,omitempty"`
+	Labels                []string       `json:"labels,omitempty"`
+	SmbShareSettings      []string       `json:"smbShareSettings,omitempty"`
+	SnapshotDirectory     bool           `json:"snapshotDirectory"`
+	EncryptionType        string         `json:"encryptionType,omitempty"`
 }
 
 // createVolumeResult the api response for creating a volume
@@ -71,73 +152,135 @@ type listVolumeIDResult struct {
 }
 
 type snapshotPolicy struct {
-	Enabled         bool            `structs:"enabled"`
-	DailySchedule   dailySchedule   `structs:"dailySchedule"`
-	HourlySchedule  hourlySchedule  `structs:"hourlySchedule"`
-	MonthlySchedule monthlySchedule `structs:"monthlySchedule"`
-	WeeklySchedule  weeklySchedule  `structs:"weeklySchedule"`
+	Enabled         bool            `json:"enabled"`
+	DailySchedule   dailySchedule   `json:"dailySchedule"`
+	HourlySchedule  hourlySchedule  `json:"hourlySchedule"`
+	MonthlySchedule monthlySchedule `json:"monthlySchedule"`
+	WeeklySchedule  weeklySchedule  `json:"weeklySchedule"`
+}
+
+type backupPolicy struct {
+	Enabled              bool `json:"enabled"`
+	DailyBackupsToKeep   int  `json:"dailyBackupsToKeep"`
+	WeeklyBackupsToKeep  int  `json:"weeklyBackupsToKeep"`
+	MonthlyBackupsToKeep int  `json:"monthlyBackupsToKeep"`
 }
 
 type dailySchedule struct {
-	Hour            int `structs:"hour"`
-	Minute          int `structs:"minute"`
-	SnapshotsToKeep int `structs:"snapshotsToKeep"`
+	Hour            int `json:"hour"`
+	Minute          int `json:"minute"`
+	SnapshotsToKeep int `json:"snapshotsToKeep"`
 }
 
 type hourlySchedule struct {
-	Minute          int `structs:"minute"`
-	SnapshotsToKeep int `structs:"snapshotsToKeep"`
+	Minute          int `json:"minute"`
+	SnapshotsToKeep int `json:"snapshotsToKeep"`
 }
 
 type monthlySchedule struct {
-	DaysOfMonth     string `structs:"daysOfMonth"`
-	Hour            int    `structs:"hour"`
-	Minute          int    `structs:"minute"`
-	SnapshotsToKeep int    `structs:"snapshotsToKeep"`
+	DaysOfMonth     string `json:"daysOfMonth"`
+	Hour            int    `json:"hour"`
+	Minute          int    `json:"minute"`
+	SnapshotsToKeep int    `json:"snapshotsToKeep"`
 }
 
 type weeklySchedule struct {
-	Day             string `structs:"day"`
-	Hour            int    `structs:"hour"`
-	Minute          int    `structs:"minute"`
-	SnapshotsToKeep int    `structs:"snapshotsToKeep"`
+	Day             string `json:"day"`
+	Hour            int    `json:"hour"`
+	Minute          int    `json:"minute"`
+	SnapshotsToKeep int    `json:"snapshotsToKeep"`
+}
+
+// revertVolumeRequest the user input for reverting a volume to a snapshot
+type revertVolumeRequest struct {
+	Region     string `json:"region"`
+	VolumeID   string `json:"volumeId"`
+	SnapshotID string `json:"snapshotId"`
 }
 
-type apiResponseCodeMessage struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+// updateVolumeResult the api response for updating a volume
+type updateVolumeResult struct {
+	Code                  int         `json:"code"`
+	Message               string      `json:"message"`
+	VolumeID              string      `json:"volumeId"`
+	LifeCycleState        string      `json:"lifeCycleState"`
+	LifeCycleStateDetails string      `json:"lifeCycleStateDetails"`
+	Jobs                  []volumeJob `json:"jobs"`
+}
+
+// volumeJob a backend job attached to a volume
+type volumeJob struct {
+	JobID        string `json:"jobId"`
+	VolumeID     string `json:"volumeId"`
+	Action       string `json:"action"`
+	State        string `json:"state"`
+	StateDetails string `json:"stateDetails"`
+	Progress     int    `json:"progress"`
+	Created      string `json:"created"`
 }
 
 type exportPolicyRule struct {
-	Access              string `structs:"access"`
-	AllowedClients      string `structs:"allowedClients"`
-	HasRootAccess       bool   `structs:"hasRootAccess"`
-	Kerberos5ReadOnly   bool   `structs:"kerberos5ReadOnly"`
-	Kerberos5ReadWrite  bool   `structs:"kerberos5ReadWrite"`
-	Kerberos5iReadOnly  bool   `structs:"kerberos5iReadOnly"`
-	Kerberos5iReadWrite bool   `structs:"kerberos5iReadWrite"`
-	Kerberos5pReadOnly  bool   `structs:"kerberos5pReadOnly"`
-	Kerberos5pReadWrite bool   `structs:"kerberos5pReadWrite"`
-	Nfsv3               nfs    `structs:"nfsv3"`
-	Nfsv4               nfs    `structs:"nfsv4"`
+	Access              string `json:"access"`
+	AllowedClients      string `json:"allowedClients"`
+	HasRootAccess       bool   `json:"hasRootAccess"`
+	Kerberos5ReadOnly   bool   `json:"kerberos5ReadOnly"`
+	Kerberos5ReadWrite  bool   `json:"kerberos5ReadWrite"`
+	Kerberos5iReadOnly  bool   `json:"kerberos5iReadOnly"`
+	Kerberos5iReadWrite bool   `json:"kerberos5iReadWrite"`
+	Kerberos5pReadOnly  bool   `json:"kerberos5pReadOnly"`
+	Kerberos5pReadWrite bool   `json:"kerberos5pReadWrite"`
+	Nfsv3               nfs    `json:"nfsv3"`
+	Nfsv4               nfs    `json:"nfsv4"`
+	AllowVpc            bool   `json:"-"`
 }
 
 type exportPolicy struct {
-	Rules []exportPolicyRule `structs:"rules"`
+	Rules []exportPolicyRule `json:"rules"`
+}
+
+// disabledExportPolicy is the export policy of a volume with exports_disabled: no rules, so no NFS client can mount
+// the volume. The rules are sent as an empty list, as an update without rules keeps the rules of the volume.
+func disabledExportPolicy() exportPolicy {
+	return exportPolicy{Rules: []exportPolicyRule{}}
 }
 
 type nfs struct {
-	Checked bool `structs:"checked"`
+	Checked bool `json:"checked"`
+	// present is set if the block is in the API response. Some responses omit the nfsv3 and nfsv4 blocks of rules.
+	present bool
+}
+
+// UnmarshalJSON decodes the block and marks it present, it isn't called for a missing block
+func (n *nfs) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	type plainNFS nfs
+	var decoded plainNFS
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*n = nfs(decoded)
+	n.present = true
+	return nil
+}
+
+// flattenNFS returns the nfsv3 or nfsv4 block of a rule, or no block if the API response omitted it
+func flattenNFS(v nfs) []map[string]interface{} {
+	if !v.present {
+		return []map[string]interface{}{}
+	}
+	return []map[string]interface{}{{"checked": v.Checked}}
 }
 
 type simpleExportPolicyRule struct {
-	SimpleExportPolicyRule exportPolicyRule `structs:"SimpleExportPolicyRule"`
+	SimpleExportPolicyRule exportPolicyRule `json:"SimpleExportPolicyRule"`
 }
 
 type mountPoints struct {
-	Export       string `structs:"export"`
-	Server       string `structs:"server"`
-	ProtocolType string `structs:"protocolType"`
+	Export       string `json:"export"`
+	Server       string `json:"server"`
+	ProtocolType string `json:"protocolType"`
 }
 
 func (c *Client) getVolumeByID(volume volumeRequest) (volumeResult, error) {
@@ -154,8 +297,7 @@ func (c *Client) getVolumeByID(volume volumeRequest) (volumeResult, error) {
 	}
 
 	var result volumeResult
-	if err := json.Unmarshal(response, &result); err != nil {
-		log.Print("Failed to unmarshall response from getVolumeByID")
+	if err := decodeResponse(response, &result, statusCode, baseURL, "getVolumeByID"); err != nil {
 		return volumeResult{}, err
 	}
 	return result, nil
@@ -177,8 +319,7 @@ func (c *Client) getVolumeByRegion(region string) ([]volumeResult, error) {
 		return volumes, responseError
 	}
 
-	if err := json.Unmarshal(response, &volumes); err != nil {
-		log.Print("Failed to unmarshall response from getVolumeByRegion")
+	if err := decodeResponse(response, &volumes, statusCode, baseURL, "getVolumeByRegion"); err != nil {
 		return volumes, err
 	}
 	return volumes, nil
@@ -204,14 +345,19 @@ func (c *Client) getVolumeByNameOrCreationToken(volume volumeRequest) (volumeRes
 	}
 
 	var result []volumeResult
-	if err := json.Unmarshal(response, &result); err != nil {
-		log.Print("Failed to unmarshall response from getVolumeByNameOrCreationToken")
+	if err := decodeResponse(response, &result, statusCode, baseURL, "getVolumeByNameOrCreationToken"); err != nil {
 		return volumeResult{}, err
 	}
 
+	return findVolumeByNameOrCreationToken(result, volume)
+}
+
+// findVolumeByNameOrCreationToken returns the volume of a list matching the creation token and the name of the
+// request, or the only volume with the name if the request has no creation token
+func findVolumeByNameOrCreationToken(volumes []volumeResult, volume volumeRequest) (volumeResult, error) {
 	var count = 0
 	var resultVolume volumeResult
-	for _, eachVolume := range result {
+	for _, eachVolume := range volumes {
 		if volume.CreationToken != "" && eachVolume.CreationToken == volume.CreationToken {
 			if volume.Name != "" && eachVolume.Name == volume.Name {
 				return eachVolume, nil
@@ -238,6 +384,11 @@ func (c *Client) getVolumeByNameOrCreationToken(volume volumeRequest) (volumeRes
 
 func (c *Client) createVolume(request *volumeRequest, volType string) (createVolumeResult, error) {
 
+	network, err := c.resolveNetwork(*request)
+	if err != nil {
+		return createVolumeResult{}, err
+	}
+
 	if request.CreationToken == "" {
 		creationToken, err := c.createVolumeCreationToken(*request)
 		if err != nil {
@@ -247,96 +398,23 @@ func (c *Client) createVolume(request *volumeRequest, volType string) (createVol
 		request.CreationToken = creationToken.CreationToken
 	}
 
-	var projectID string
-	if request.SharedVpcProjectNumber != "" {
-		projectID = request.SharedVpcProjectNumber
-	} else {
-		projectID = c.GetProjectID()
-	}
-	request.Network = fmt.Sprintf("projects/%s/global/networks/%s", projectID, request.Network)
-
-	params := structs.Map(request)
+	params := *request
+	params.Network = network
 
 	baseURL := fmt.Sprintf("%s/%s", request.Region, volType)
-	log.Printf("Parameters: %v", params)
-	statusCode, response, err := c.CallAPIMethod("POST", baseURL, params)
+	statusCode, response, err := c.callAPIMethodUntil("POST", baseURL, params, request.Deadline)
 	if err != nil {
 		return createVolumeResult{}, err
 	}
-	responseError := apiResponseChecker(statusCode, response, "createVolume")
-	if responseError != nil {
-		var responseErrorContent apiErrorResponse
-		responseContent := bytes.NewBuffer(response).String()
-		if err := json.Unmarshal(response, &responseErrorContent); err != nil {
-			return createVolumeResult{}, fmt.Errorf(responseContent)
-		}
-		if responseErrorContent.Code == 500 {
-			if responseErrorContent.Message == spawnJobCreationErrorMessage {
-				retries := 10
-				for retries > 0 {
-					var spawnJobResponseErrorContent apiErrorResponse
-					time.Sleep(time.Duration(nextRandomInt(30, 50)) * time.Second)
-					statusCode, response, err = c.CallAPIMethod("POST", baseURL, params)
-					if err != nil {
-						return createVolumeResult{}, err
-					}
-					responseError = apiResponseChecker(statusCode, response, "createVolume")
-					responseContent = bytes.NewBuffer(response).String()
-					if err := json.Unmarshal(response, &spawnJobResponseErrorContent); err != nil {
-						return createVolumeResult{}, fmt.Errorf(responseContent)
-					}
-					if spawnJobResponseErrorContent.Code == 0 {
-						var result createVolumeResult
-						if err := json.Unmarshal(response, &result); err != nil {
-							log.Print("Failed to unmarshall response from createVolume")
-							return createVolumeResult{}, fmt.Errorf(bytes.NewBuffer(response).String())
-						}
-						return result, nil
-					}
-					if spawnJobResponseErrorContent.Code != 500 {
-						return createVolumeResult{}, responseError
-					}
-					retries--
-				}
-			} else if responseErrorContent.Message == contextDeadlineExceededErrorMessage {
-				retries := 5
-				for retries > 0 {
-					var contextDeadlineResponseErrorContent apiErrorResponse
-					time.Sleep(time.Duration(nextRandomInt(5, 10)) * time.Second)
-					statusCode, response, err = c.CallAPIMethod("POST", baseURL, params)
-					if err != nil {
-						return createVolumeResult{}, err
-					}
-					responseError = apiResponseChecker(statusCode, response, "createVolume")
-					responseContent = bytes.NewBuffer(response).String()
-					if err := json.Unmarshal(response, &contextDeadlineResponseErrorContent); err != nil {
-						return createVolumeResult{}, fmt.Errorf(responseContent)
-					}
-					if contextDeadlineResponseErrorContent.Code == 0 {
-						var result createVolumeResult
-						if err := json.Unmarshal(response, &result); err != nil {
-							log.Print("Failed to unmarshall response from createVolume")
-							return createVolumeResult{}, fmt.Errorf(bytes.NewBuffer(response).String())
-						}
-						return result, nil
-					}
-					if contextDeadlineResponseErrorContent.Code != 500 {
-						return createVolumeResult{}, responseError
-					}
-					retries--
-				}
-			} else {
-				return createVolumeResult{}, responseError
-			}
-		}
-		if responseErrorContent.Code >= 300 || responseErrorContent.Code < 200 {
-			return createVolumeResult{}, responseError
+	if responseError := apiResponseChecker(statusCode, response, "createVolume"); responseError != nil {
+		if responseError.Code == 500 && isQuotaError(responseError.Message) {
+			return createVolumeResult{}, quotaExceededError(request.Region, responseError.Message)
 		}
+		return createVolumeResult{}, responseError
 	}
 
 	var result createVolumeResult
-	if err := json.Unmarshal(response, &result); err != nil {
-		log.Print("Failed to unmarshall response from createVolume")
+	if err := decodeResponse(response, &result, statusCode, baseURL, "createVolume"); err != nil {
 		return createVolumeResult{}, err
 	}
 
@@ -346,71 +424,35 @@ func (c *Client) createVolume(request *volumeRequest, volType string) (createVol
 func (c *Client) deleteVolume(request volumeRequest) error {
 
 	baseURL := fmt.Sprintf("%s/Volumes/%s", request.Region, request.VolumeID)
-	statusCode, response, err := c.CallAPIMethod("DELETE", baseURL, nil)
+	statusCode, response, err := c.callAPIMethodUntil("DELETE", baseURL, nil, request.Deadline)
 	if err != nil {
 		log.Print("DeleteVolume request failed")
 		return err
 	}
 
-	responseError := apiResponseChecker(statusCode, response, "deleteVolume")
-	if responseError != nil {
-		var responseErrorContent apiErrorResponse
-		responseContent := bytes.NewBuffer(response).String()
-		if err := json.Unmarshal(response, &responseErrorContent); err != nil {
-			return fmt.Errorf(responseContent)
-		}
-		if responseErrorContent.Code == 500 {
-			if responseErrorContent.Message == spawnJobDeletionErrorMessage {
-				retries := 10
-				for retries > 0 {
-					var deleteJobResponseErrorContent apiErrorResponse
-					time.Sleep(time.Duration(nextRandomInt(30, 50)) * time.Second)
-					statusCode, response, err = c.CallAPIMethod("DELETE", baseURL, nil)
-					if err != nil {
-						return err
-					}
-					responseError = apiResponseChecker(statusCode, response, "deleteVolume")
-					responseContent = bytes.NewBuffer(response).String()
-					if err := json.Unmarshal(response, &deleteJobResponseErrorContent); err != nil {
-						return fmt.Errorf(responseContent)
-					}
-					if deleteJobResponseErrorContent.Code == 0 {
-						var result createVolumeResult
-						if err := json.Unmarshal(response, &result); err != nil {
-							log.Print("Failed to unmarshall response from createVolume")
-							return fmt.Errorf(bytes.NewBuffer(response).String())
-						}
-						return nil
-					}
-					if deleteJobResponseErrorContent.Code != 500 {
-						return responseError
-					}
-					retries--
-				}
-
-			} else {
-				return responseError
-			}
-		}
-		if responseErrorContent.Code >= 300 || responseErrorContent.Code < 200 {
-			return responseError
-		}
+	if responseError := apiResponseChecker(statusCode, response, "deleteVolume"); responseError != nil {
+		return responseError
 	}
 
-	var result apiErrorResponse
-	if err := json.Unmarshal(response, &result); err != nil {
-		log.Print("Failed to unmarshall response from deleteVolume")
-		return fmt.Errorf(bytes.NewBuffer(response).String())
+	var result restapi.ResponseError
+	if err := decodeResponse(response, &result, statusCode, baseURL, "deleteVolume"); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 func (c *Client) createVolumeCreationToken(request volumeRequest) (volumeResult, error) {
-	params := structs.Map(request)
+	// send the project, network and labels of the volume so the backend can attribute the call
+	params := map[string]interface{}{
+		"project": c.GetProjectID(),
+		"name":    request.Name,
+		"region":  request.Region,
+		"network": c.networkFullPath(request),
+		"labels":  request.Labels,
+	}
 
 	baseURL := fmt.Sprintf("%s/VolumeCreationToken", request.Region)
-	log.Printf("Parameters: %v", params)
 	statusCode, response, err := c.CallAPIMethod("GET", baseURL, params)
 	if err != nil {
 		log.Print("CreationToken request failed")
@@ -423,15 +465,17 @@ func (c *Client) createVolumeCreationToken(request volumeRequest) (volumeResult,
 	}
 
 	var result volumeResult
-	if err := json.Unmarshal(response, &result); err != nil {
-		log.Print("Failed to unmarshall response from createVolumeCreationToken")
+	if err := decodeResponse(response, &result, statusCode, baseURL, "createVolumeCreationToken"); err != nil {
 		return volumeResult{}, err
 	}
 	return result, nil
 }
 
 func (c *Client) updateVolume(request volumeRequest) error {
-	params := structs.Map(request)
+	params := request
+	if request.Network != "" {
+		params.Network = c.networkFullPath(request)
+	}
 
 	baseURL := fmt.Sprintf("%s/Volumes/%s", request.Region, request.VolumeID)
 
@@ -446,18 +490,120 @@ func (c *Client) updateVolume(request volumeRequest) error {
 		return responseError
 	}
 
-	var result apiResponseCodeMessage
-	if err := json.Unmarshal(response, &result); err != nil {
-		log.Print("Failed to unmarshall response from updateVolume")
+	return checkUpdateVolumeResponse(response, statusCode, baseURL)
+}
+
+// checkUpdateVolumeResponse inspects the envelope returned by an update. The API may return an informational
+// message together with a successful status, so only a failed code, an error lifecycle state or a failed job
+// is treated as an error.
+func checkUpdateVolumeResponse(response []byte, statusCode int, baseURL string) error {
+	var result updateVolumeResult
+	if err := decodeResponse(response, &result, statusCode, baseURL, "updateVolume"); err != nil {
 		return err
 	}
-	if (result.Code != 0 && result.Code != 200) || (result.Message != "") {
-		return fmt.Errorf("code: %d, message: %s", result.Code, result.Message)
+	if result.Code != 0 && (result.Code >= 300 || result.Code < 200) {
+		return &restapi.ResponseError{StatusCode: statusCode, Code: result.Code, Message: result.Message, Operation: "updateVolume"}
+	}
+	if result.LifeCycleState == "error" {
+		return fmt.Errorf("volume %s is in error state after update: %s", result.VolumeID, result.LifeCycleStateDetails)
+	}
+	for _, job := range result.Jobs {
+		if job.State == "error" || job.State == "failed" {
+			return fmt.Errorf("update job %s for volume %s failed: %s", job.JobID, result.VolumeID, job.StateDetails)
+		}
+	}
+	if result.Message != "" {
+		log.Printf("updateVolume returned message: %s", result.Message)
 	}
 
 	return nil
 }
 
+// revertVolume reverts a volume in place to one of its snapshots, and returns the ID of the revert job if the API reports one
+func (c *Client) revertVolume(request revertVolumeRequest) (string, error) {
+	baseURL := fmt.Sprintf("%s/Volumes/%s/Revert", request.Region, request.VolumeID)
+	statusCode, response, err := c.CallAPIMethod("POST", baseURL, request)
+	if err != nil {
+		log.Print("revertVolume request failed")
+		return "", err
+	}
+
+	responseError := apiResponseChecker(statusCode, response, "revertVolume")
+	if responseError != nil {
+		return "", responseError
+	}
+
+	var result updateVolumeResult
+	if err := decodeResponse(response, &result, statusCode, baseURL, "revertVolume"); err != nil {
+		return "", err
+	}
+	if len(result.Jobs) == 0 {
+		return "", nil
+	}
+	return result.Jobs[0].JobID, nil
+}
+
+// getJob returns a backend job by ID
+// jobParams returns the context sent with the jobs calls so the backend can attribute them. Jobs aren't labelled
+// and a job list spans the volumes of the region, so only the project is sent.
+func (c *Client) jobParams() map[string]interface{} {
+	return map[string]interface{}{"project": c.GetProjectID()}
+}
+
+func (c *Client) getJob(region string, jobID string) (volumeJob, error) {
+
+	baseURL := fmt.Sprintf("%s/Jobs/%s", region, jobID)
+
+	statusCode, response, err := c.CallAPIMethod("GET", baseURL, c.jobParams())
+	if err != nil {
+		log.Print("getJob request failed")
+		return volumeJob{}, err
+	}
+
+	responseError := apiResponseChecker(statusCode, response, "getJob")
+	if responseError != nil {
+		return volumeJob{}, responseError
+	}
+
+	var result volumeJob
+	if err := decodeResponse(response, &result, statusCode, baseURL, "getJob"); err != nil {
+		return volumeJob{}, err
+	}
+	return result, nil
+}
+
+// listJobsForVolume returns the backend jobs of a volume, most recent first
+func (c *Client) listJobsForVolume(region string, volumeID string) ([]volumeJob, error) {
+
+	baseURL := fmt.Sprintf("%s/Jobs", region)
+
+	statusCode, response, err := c.CallAPIMethod("GET", baseURL, c.jobParams())
+	if err != nil {
+		log.Print("listJobsForVolume request failed")
+		return nil, err
+	}
+
+	responseError := apiResponseChecker(statusCode, response, "listJobsForVolume")
+	if responseError != nil {
+		return nil, responseError
+	}
+
+	var jobs []volumeJob
+	if err := decodeResponse(response, &jobs, statusCode, baseURL, "listJobsForVolume"); err != nil {
+		return nil, err
+	}
+
+	result := []volumeJob{}
+	for _, job := range jobs {
+		if job.VolumeID == volumeID {
+			result = append(result, job)
+		}
+	}
+	// created is an RFC 3339 timestamp in UTC, which sorts as a string
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Created > result[j].Created })
+	return result, nil
+}
+
 // SetProjectID for the client to use for requests to the GCP API
 func (c *Client) SetProjectID(project string) {
 	c.Project = project
@@ -468,6 +614,36 @@ func (c *Client) GetProjectID() string {
 	return c.Project
 }
 
+// networkFullPath returns the network path expected by the API. The network may be given as a short name or
+// as a full path, so a path that is already prefixed is returned unchanged.
+func (c *Client) networkFullPath(request volumeRequest) string {
+	if strings.HasPrefix(request.Network, "projects/") {
+		return request.Network
+	}
+	projectID := c.GetProjectID()
+	if request.SharedVpcProjectNumber != "" {
+		projectID = request.SharedVpcProjectNumber
+	}
+	return fmt.Sprintf("projects/%s/global/networks/%s", projectID, request.Network)
+}
+
+// networkShortName returns the network name of a full network path as returned by the API
+func networkShortName(network string) string {
+	index := strings.Index(network, "networks/")
+	if index > -1 {
+		return network[index+len("networks/"):]
+	}
+	return network
+}
+
+// networkProject returns the project number of a full network path as returned by the API, or "" for a short name
+func networkProject(network string) string {
+	if !strings.HasPrefix(network, "projects/") {
+		return ""
+	}
+	return strings.SplitN(strings.TrimPrefix(network, "projects/"), "/", 2)[0]
+}
+
 // expandSnapshotPolicy converts map to snapshotPolicy struct
 func expandSnapshotPolicy(data map[string]interface{}) snapshotPolicy {
 	snapshotPolicy := snapshotPolicy{}
@@ -553,18 +729,8 @@ func flattenExportPolicy(v exportPolicy) interface{} {
 		ruleMap["kerberos5i_readwrite"] = exportPolicyRule.Kerberos5iReadWrite
 		ruleMap["kerberos5p_readonly"] = exportPolicyRule.Kerberos5pReadOnly
 		ruleMap["kerberos5p_readwrite"] = exportPolicyRule.Kerberos5pReadWrite
-		nfsv3Config := make(map[string]interface{})
-		nfsv4Config := make(map[string]interface{})
-		nfsv3Config["checked"] = exportPolicyRule.Nfsv3.Checked
-		nfsv4Config["checked"] = exportPolicyRule.Nfsv4.Checked
-		nfsv3 := make([]map[string]interface{}, 1)
-		nfsv4 := make([]map[string]interface{}, 1)
-		nfsv3[0] = make(map[string]interface{})
-		nfsv4[0] = make(map[string]interface{})
-		nfsv3[0] = nfsv3Config
-		nfsv4[0] = nfsv4Config
-		ruleMap["nfsv3"] = nfsv3
-		ruleMap["nfsv4"] = nfsv4
+		ruleMap["nfsv3"] = flattenNFS(exportPolicyRule.Nfsv3)
+		ruleMap["nfsv4"] = flattenNFS(exportPolicyRule.Nfsv4)
 		rules = append(rules, ruleMap)
 	}
 	result := make([]map[string]interface{}, 1)
@@ -593,6 +759,9 @@ func expandExportPolicy(set *schema.Set) exportPolicy {
 			exportPolicyRule.Kerberos5iReadWrite = ruleConfig["kerberos5i_readwrite"].(bool)
 			exportPolicyRule.Kerberos5pReadOnly = ruleConfig["kerberos5p_readonly"].(bool)
 			exportPolicyRule.Kerberos5pReadWrite = ruleConfig["kerberos5p_readwrite"].(bool)
+			if allowVpc, ok := ruleConfig["allow_vpc"]; ok {
+				exportPolicyRule.AllowVpc = allowVpc.(bool)
+			}
 			nfsv3Set := ruleConfig["nfsv3"].(*schema.Set)
 			nfsv4Set := ruleConfig["nfsv4"].(*schema.Set)
 			for _, y := range nfsv3Set.List() {
@@ -610,6 +779,66 @@ func expandExportPolicy(set *schema.Set) exportPolicy {
 	return exportPolicyObj
 }
 
+// allowsAnyClient reports whether the allowed_clients of an export rule contain 0.0.0.0/0
+func allowsAnyClient(allowedClients string) bool {
+	for _, client := range strings.Split(allowedClients, ",") {
+		if strings.TrimSpace(client) == "0.0.0.0/0" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAllowedClients warns about allowed_clients containing 0.0.0.0/0, which gives any IPv4 client the access
+// of the rule. The warning is shown in the output of plan and apply. Validation runs before the provider is
+// configured and sees a single attribute, so the warning is shown for every access and can't be switched off.
+func validateAllowedClients(v interface{}, k string) ([]string, []error) {
+	if allowsAnyClient(v.(string)) {
+		return []string{fmt.Sprintf("%s %q gives any IPv4 client the access of the export rule. Restrict it unless the volume is meant to be open", k, v.(string))}, nil
+	}
+	return nil, nil
+}
+
+// checkReadOnlyExportPolicy returns an error for the first export rule allowing writes
+func checkReadOnlyExportPolicy(policy exportPolicy) error {
+	for i, rule := range policy.Rules {
+		if rule.Access == "ReadWrite" || rule.Kerberos5ReadWrite || rule.Kerberos5iReadWrite || rule.Kerberos5pReadWrite {
+			return fmt.Errorf("export policy rule %d allows writes, but the volume is read_only. Use access ReadOnly or None and no kerberos read write access", i+1)
+		}
+	}
+	return nil
+}
+
+// expandBackupPolicy converts map to backupPolicy struct
+func expandBackupPolicy(data map[string]interface{}) backupPolicy {
+	backupPolicy := backupPolicy{}
+	if v, ok := data["enabled"]; ok {
+		backupPolicy.Enabled = v.(bool)
+	}
+	if v, ok := data["daily_backups_to_keep"]; ok {
+		backupPolicy.DailyBackupsToKeep = v.(int)
+	}
+	if v, ok := data["weekly_backups_to_keep"]; ok {
+		backupPolicy.WeeklyBackupsToKeep = v.(int)
+	}
+	if v, ok := data["monthly_backups_to_keep"]; ok {
+		backupPolicy.MonthlyBackupsToKeep = v.(int)
+	}
+	return backupPolicy
+}
+
+// flattenBackupPolicy converts backupPolicy struct to []map[string]interface{}
+func flattenBackupPolicy(v backupPolicy) interface{} {
+	flattened := make([]map[string]interface{}, 1)
+	bp := make(map[string]interface{})
+	bp["enabled"] = v.Enabled
+	bp["daily_backups_to_keep"] = v.DailyBackupsToKeep
+	bp["weekly_backups_to_keep"] = v.WeeklyBackupsToKeep
+	bp["monthly_backups_to_keep"] = v.MonthlyBackupsToKeep
+	flattened[0] = bp
+	return flattened
+}
+
 // flattenSnapshotPolicy converts snapshotPolicy struct to []map[string]interface{}
 func flattenSnapshotPolicy(v snapshotPolicy) interface{} {
 	flattened := make([]map[string]interface{}, 1)
@@ -644,6 +873,97 @@ func flattenSnapshotPolicy(v snapshotPolicy) interface{} {
 	return flattened
 }
 
+// expandStringList converts a list such as labels to []string. The result is never nil so that removing all
+// items is sent to the API on update.
+func expandStringList(v []interface{}) []string {
+	items := make([]string, 0, len(v))
+	for _, item := range v {
+		items = append(items, item.(string))
+	}
+	return items
+}
+
+// smbShareName returns the name of the SMB share of the volume from its CIFS mount point,
+// e.g. share-name for \\cvs-1234.example.com\share-name
+func smbShareName(v []mountPoints) string {
+	for _, mountpoint := range v {
+		if mountpoint.ProtocolType != "CIFS" {
+			continue
+		}
+		export := strings.TrimRight(mountpoint.Export, "\\/")
+		return export[strings.LastIndexAny(export, "\\/")+1:]
+	}
+	return ""
+}
+
+// smbUNCPath returns the UNC path clients open the SMB share of the volume with, e.g. \\cvs-1234.example.com\share-name
+func smbUNCPath(v []mountPoints) string {
+	for _, mountpoint := range v {
+		if mountpoint.ProtocolType != "CIFS" {
+			continue
+		}
+		export := strings.TrimRight(strings.Replace(mountpoint.Export, "/", `\`, -1), `\`)
+		if strings.HasPrefix(export, `\\`) {
+			return export
+		}
+		return fmt.Sprintf(`\\%s\%s`, mountpoint.Server, strings.TrimLeft(export, `\`))
+	}
+	return ""
+}
+
+// nfsMountCommand returns a command mounting the NFS export of the volume on a directory of /mnt named after the
+// export. NFSv3 is used if the volume supports both NFS versions.
+func nfsMountCommand(v []mountPoints) string {
+	for _, version := range []struct{ protocolType, option string }{{"NFSv3", "3"}, {"NFSv4", "4.1"}} {
+		for _, mountpoint := range v {
+			if mountpoint.ProtocolType != version.protocolType {
+				continue
+			}
+			return fmt.Sprintf("mount -t nfs -o rw,hard,rsize=65536,wsize=65536,vers=%s,tcp %s:%s /mnt/%s",
+				version.option, mountpoint.Server, mountpoint.Export, strings.Trim(mountpoint.Export, "/"))
+		}
+	}
+	return ""
+}
+
+// sortMountPoints orders the mount points the API returns in varying order by protocol type, export and server, so
+// the state and the attributes derived from the first mount point of a protocol are stable across refreshes
+func sortMountPoints(v []mountPoints) {
+	sort.SliceStable(v, func(i, j int) bool {
+		if v[i].ProtocolType != v[j].ProtocolType {
+			return v[i].ProtocolType < v[j].ProtocolType
+		}
+		if v[i].Export != v[j].Export {
+			return v[i].Export < v[j].Export
+		}
+		return v[i].Server < v[j].Server
+	})
+}
+
+// sortProtocolTypes orders the protocol types the API returns in varying order: the configured protocol types first,
+// in the order of the configuration, then the others sorted, e.g. all of them for an imported volume
+func sortProtocolTypes(protocolTypes []string, configured []interface{}) []string {
+	rank := make(map[string]int, len(configured))
+	for i, protocol := range configured {
+		if _, ok := rank[apiProtocolType(protocol.(string))]; !ok {
+			rank[apiProtocolType(protocol.(string))] = i
+		}
+	}
+	sorted := append([]string{}, protocolTypes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ri, iConfigured := rank[apiProtocolType(sorted[i])]
+		rj, jConfigured := rank[apiProtocolType(sorted[j])]
+		switch {
+		case iConfigured && jConfigured:
+			return ri < rj
+		case iConfigured != jConfigured:
+			return iConfigured
+		}
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+
 func flattenMountPoints(v []mountPoints) interface{} {
 	mps := make([]map[string]interface{}, 0, len(v))
 	for _, mountpoint := range v {
@@ -1,18 +1,15 @@
 package gcp
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
-
-	"github.com/fatih/structs"
 )
 
 // createVolumeBackupRequest the users input for creating a VolumeBackup
 type createVolumeBackupRequest struct {
-	Name     string `structs:"name"`
-	Region   string `structs:"region"`
-	VolumeID string `structs:"volumeId"`
+	Name     string `json:"name"`
+	Region   string `json:"region"`
+	VolumeID string `json:"volumeId"`
 }
 
 // createVolumeBackupResult the api response for creating a VolumeBackup
@@ -32,9 +29,9 @@ type listVolumeBackupIDResult struct {
 
 // deleteVolumeBackupRequest the user input for deleteing a VolumeBackup
 type deleteVolumeBackupRequest struct {
-	VolumeBackupID string `structs:"backupId"`
-	Region         string `structs:"region"`
-	VolumeID       string `structs:"volumeId"`
+	VolumeBackupID string `json:"backupId"`
+	Region         string `json:"region"`
+	VolumeID       string `json:"volumeId"`
 }
 
 // listVolumeBackupResult lists the volume for given VolumeBackup ID
@@ -45,17 +42,17 @@ type listVolumeBackupResult struct {
 
 // listVolumeBackupRequest requests the volume for given VolumeBackup ID and region
 type listVolumeBackupRequest struct {
-	VolumeBackupID string `structs:"backupId"`
-	Region         string `structs:"region"`
-	VolumeID       string `structs:"volumeId"`
+	VolumeBackupID string `json:"backupId"`
+	Region         string `json:"region"`
+	VolumeID       string `json:"volumeId"`
 }
 
 // updateVolumeBackupRequest request update name of a VolumeBackup for given VolumeBackup ID and name
 type updateVolumeBackupRequest struct {
-	Name           string `structs:"name"`
-	Region         string `structs:"region"`
-	VolumeID       string `structs:"volumeId"`
-	VolumeBackupID string `structs:"backupId"`
+	Name           string `json:"name"`
+	Region         string `json:"region"`
+	VolumeID       string `json:"volumeId"`
+	VolumeBackupID string `json:"backupId"`
 }
 
 func (c *Client) getVolumeBackupByID(VolumeBackup listVolumeBackupRequest) (listVolumeBackupResult, error) {
@@ -74,8 +71,7 @@ func (c *Client) getVolumeBackupByID(VolumeBackup listVolumeBackupRequest) (list
 	}
 
 	var result listVolumeBackupResult
-	if err := json.Unmarshal(response, &result); err != nil {
-		log.Print("Failed to unmarshall response from ListVolumes")
+	if err := decodeResponse(response, &result, statusCode, baseURL, "getVolumeBackupByID"); err != nil {
 		return listVolumeBackupResult{}, err
 	}
 	if result.LifeCycleState == "deleted" || result.LifeCycleState == "deleting" {
@@ -87,10 +83,9 @@ func (c *Client) getVolumeBackupByID(VolumeBackup listVolumeBackupRequest) (list
 
 func (c *Client) createVolumeBackup(request *createVolumeBackupRequest) (createVolumeBackupResult, error) {
 
-	params := structs.Map(request)
+	params := request
 
 	baseURL := fmt.Sprintf("%s/Volumes/%s/Backups", request.Region, request.VolumeID)
-	log.Printf("Parameters: %v", params)
 
 	statusCode, response, err := c.CallAPIMethod("POST", baseURL, params)
 	if err != nil {
@@ -104,8 +99,7 @@ func (c *Client) createVolumeBackup(request *createVolumeBackupRequest) (createV
 	}
 
 	var result createVolumeBackupResult
-	if err := json.Unmarshal(response, &result); err != nil {
-		log.Print("Failed to unmarshall response from CreateVolumeBackup")
+	if err := decodeResponse(response, &result, statusCode, baseURL, "CreateVolumeBackup"); err != nil {
 		return createVolumeBackupResult{}, err
 	}
 
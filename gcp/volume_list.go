@@ -0,0 +1,75 @@
+package gcp
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// volumeListCache shares the volume list of a region between the data sources of a run, so many data sources
+// reading volumes of the same region make a single list call. Concurrent reads wait for the call in progress. The
+// list of a region is dropped by any change made to the region through the client, and failed calls aren't kept.
+type volumeListCache struct {
+	mutex sync.Mutex
+	lists map[string]*volumeList
+}
+
+// volumeList is a list call of the volumes of a region, done is closed when it returns
+type volumeList struct {
+	done    chan struct{}
+	volumes []volumeResult
+	err     error
+}
+
+// listVolumesShared returns the volumes of the region, listed once for all data sources. The volumes are shared and
+// must not be modified.
+func (c *Client) listVolumesShared(region string) ([]volumeResult, error) {
+	c.volumeLists.mutex.Lock()
+	if c.volumeLists.lists == nil {
+		c.volumeLists.lists = make(map[string]*volumeList)
+	}
+	if list, ok := c.volumeLists.lists[region]; ok {
+		c.volumeLists.mutex.Unlock()
+		<-list.done
+		return list.volumes, list.err
+	}
+	list := &volumeList{done: make(chan struct{})}
+	c.volumeLists.lists[region] = list
+	c.volumeLists.mutex.Unlock()
+
+	list.volumes, list.err = c.getVolumeByRegion(region)
+	if list.err != nil {
+		c.volumeLists.drop(region, list)
+	}
+	close(list.done)
+	return list.volumes, list.err
+}
+
+// getSharedVolumeByNameOrCreationToken is getVolumeByNameOrCreationToken on the shared volume list of the region
+func (c *Client) getSharedVolumeByNameOrCreationToken(volume volumeRequest) (volumeResult, error) {
+	if volume.Name == "" && volume.CreationToken == "" {
+		return volumeResult{}, fmt.Errorf("Either CreationToken or volume name or both are required")
+	}
+	volumes, err := c.listVolumesShared(volume.Region)
+	if err != nil {
+		return volumeResult{}, err
+	}
+	return findVolumeByNameOrCreationToken(volumes, volume)
+}
+
+// invalidate drops the volume list of the region of a call changing resources, its first path segment
+func (v *volumeListCache) invalidate(method string, baseURL string) {
+	if method == "GET" {
+		return
+	}
+	v.drop(strings.SplitN(baseURL, "/", 2)[0], nil)
+}
+
+// drop removes the volume list of the region, only if it is still the given list when list isn't nil
+func (v *volumeListCache) drop(region string, list *volumeList) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if list == nil || v.lists[region] == list {
+		delete(v.lists, region)
+	}
+}
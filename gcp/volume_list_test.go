@@ -0,0 +1,52 @@
+package gcp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestListVolumesShared(t *testing.T) {
+	var lists int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "GET" && r.URL.Path == "/us-east4/Volumes" {
+			atomic.AddInt32(&lists, 1)
+			// keep the call in progress while the other data sources ask for the list
+			time.Sleep(50 * time.Millisecond)
+			fmt.Fprint(w, `[{"volumeId": "1", "name": "vol1", "creationToken": "vol1-path"}, {"volumeId": "2", "name": "vol2", "creationToken": "vol2-path"}]`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+	client := &Client{Host: server.URL + "/", Token: "opaque-access-token"}
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"vol1", "vol2", "vol1", "vol2", "vol1"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			volume, err := client.getSharedVolumeByNameOrCreationToken(volumeRequest{Region: "us-east4", Name: name})
+			if err != nil || volume.Name != name {
+				t.Errorf("expected volume %s, got %+v, %v", name, volume, err)
+			}
+		}(name)
+	}
+	wg.Wait()
+	if _, err := client.listVolumesShared("us-east4"); err != nil || atomic.LoadInt32(&lists) != 1 {
+		t.Errorf("expected a single list call, got %d, %v", lists, err)
+	}
+
+	// a change to the region lists the volumes again
+	if _, _, err := client.CallAPIMethod("DELETE", "us-east4/Volumes/1", nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := client.listVolumesShared("us-east4"); err != nil || atomic.LoadInt32(&lists) != 2 {
+		t.Errorf("expected a second list call after a change, got %d, %v", lists, err)
+	}
+}
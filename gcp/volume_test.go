@@ -0,0 +1,708 @@
+package gcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// Response bodies captured from the PUT /Volumes/{volumeId} endpoint.
+const updateVolumeResponseUpdating = `{
+	"created": "2020-10-21T18:10:21.000Z",
+	"creationToken": "terraform-acceptance-test-path",
+	"jobs": [{"action": "update", "created": "2020-10-22T09:12:03.000Z", "jobId": "7b2f0a35-8c1b-4d5e-bc0a-3c9d7f0e6a11", "state": "ongoing", "stateDetails": "Job is in progress"}],
+	"lifeCycleState": "updating",
+	"lifeCycleStateDetails": "Update in progress",
+	"name": "terraform-acceptance-test-1",
+	"quotaInBytes": 2199023255552,
+	"region": "us-east4",
+	"serviceLevel": "standard",
+	"volumeId": "0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10"
+}`
+
+const updateVolumeResponseInformational = `{
+	"code": 200,
+	"message": "Volume update accepted, snapshot policy changes take effect at the next schedule",
+	"lifeCycleState": "available",
+	"lifeCycleStateDetails": "Available for use",
+	"volumeId": "0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10"
+}`
+
+const updateVolumeResponseError = `{
+	"code": 400,
+	"message": "Error updating volume - Requested quota is smaller than used capacity"
+}`
+
+const updateVolumeResponseErrorState = `{
+	"lifeCycleState": "error",
+	"lifeCycleStateDetails": "Error updating volume - Internal error",
+	"volumeId": "0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10"
+}`
+
+const updateVolumeResponseFailedJob = `{
+	"jobs": [{"action": "update", "jobId": "7b2f0a35-8c1b-4d5e-bc0a-3c9d7f0e6a11", "state": "error", "stateDetails": "Export policy rule is invalid"}],
+	"lifeCycleState": "available",
+	"lifeCycleStateDetails": "Available for use",
+	"volumeId": "0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10"
+}`
+
+func TestCheckUpdateVolumeResponse(t *testing.T) {
+	cases := []struct {
+		name      string
+		response  string
+		expectErr bool
+	}{
+		{"updating", updateVolumeResponseUpdating, false},
+		{"informational message", updateVolumeResponseInformational, false},
+		{"error code", updateVolumeResponseError, true},
+		{"error lifecycle state", updateVolumeResponseErrorState, true},
+		{"failed job", updateVolumeResponseFailedJob, true},
+		{"malformed body", `<html>Bad Gateway</html>`, true},
+	}
+
+	for _, tc := range cases {
+		err := checkUpdateVolumeResponse([]byte(tc.response), 200, "us-east4/Volumes/0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10")
+		if tc.expectErr && err == nil {
+			t.Errorf("%s: expected error, got nil", tc.name)
+		}
+		if !tc.expectErr && err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+		}
+	}
+}
+
+func TestNetworkFullPath(t *testing.T) {
+	client := &Client{Project: "123456789"}
+	cases := []struct {
+		request  volumeRequest
+		expected string
+	}{
+		{volumeRequest{Network: "cvs-vpc"}, "projects/123456789/global/networks/cvs-vpc"},
+		{volumeRequest{Network: "cvs-vpc", SharedVpcProjectNumber: "987654321"}, "projects/987654321/global/networks/cvs-vpc"},
+		{volumeRequest{Network: "projects/987654321/global/networks/cvs-vpc"}, "projects/987654321/global/networks/cvs-vpc"},
+	}
+	for _, tc := range cases {
+		if path := client.networkFullPath(tc.request); path != tc.expected {
+			t.Errorf("expected %s, got %s", tc.expected, path)
+		}
+		if name := networkShortName(client.networkFullPath(tc.request)); name != "cvs-vpc" {
+			t.Errorf("expected cvs-vpc, got %s", name)
+		}
+	}
+}
+
+func TestNetworkProject(t *testing.T) {
+	for network, expected := range map[string]string{
+		"projects/987654321/global/networks/cvs-vpc": "987654321",
+		"cvs-vpc": "",
+	} {
+		if project := networkProject(network); project != expected {
+			t.Errorf("%s: expected %q, got %q", network, expected, project)
+		}
+	}
+}
+
+func TestAPIProtocolType(t *testing.T) {
+	cases := map[string]string{
+		"nfsv3": "NFSv3",
+		"NFSv3": "NFSv3",
+		"NFSV3": "NFSv3",
+		"nfsv4": "NFSv4",
+		"smb":   "CIFS",
+		"SMB":   "CIFS",
+		"cifs":  "CIFS",
+	}
+	for input, expected := range cases {
+		if result := apiProtocolType(input); result != expected {
+			t.Errorf("apiProtocolType(%q) = %q, expected %q", input, result, expected)
+		}
+	}
+}
+
+func TestExpandVPCExportRules(t *testing.T) {
+	path := "projects/123456789/global/networks/cvs-vpc"
+	client := &Client{Project: "123456789"}
+	client.networks.ranges = map[string][]string{path: {"10.128.0.0/20", "10.132.0.0/20"}}
+
+	policy := exportPolicy{Rules: []exportPolicyRule{
+		{AllowedClients: "192.168.1.10", AllowVpc: true},
+		{AllowedClients: "10.10.13.1"},
+		{AllowVpc: true},
+	}}
+	if err := client.expandVPCExportRules(&policy, path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{"192.168.1.10,10.128.0.0/20,10.132.0.0/20", "10.10.13.1", "10.128.0.0/20,10.132.0.0/20"}
+	for i, rule := range policy.Rules {
+		if rule.AllowedClients != expected[i] {
+			t.Errorf("rule %d: expected %s, got %s", i+1, expected[i], rule.AllowedClients)
+		}
+	}
+}
+
+func TestExportRulesApplied(t *testing.T) {
+	requested := exportPolicy{Rules: []exportPolicyRule{
+		{Access: "ReadWrite", AllowedClients: "10.0.0.0/8, 192.168.1.10"},
+		{Access: "ReadOnly", AllowedClients: "0.0.0.0/0"},
+	}}
+	applied := exportPolicy{Rules: []exportPolicyRule{
+		{Access: "readwrite", AllowedClients: "10.0.0.0/8,192.168.1.10", HasRootAccess: true},
+		{Access: "ReadOnly", AllowedClients: "0.0.0.0/0"},
+	}}
+	if !exportRulesApplied(requested, applied) {
+		t.Error("expected rules to match")
+	}
+	if exportRulesApplied(requested, exportPolicy{Rules: applied.Rules[:1]}) {
+		t.Error("expected a missing rule not to match")
+	}
+	applied.Rules[1].AllowedClients = "10.10.13.1"
+	if exportRulesApplied(requested, applied) {
+		t.Error("expected stale allowed clients not to match")
+	}
+}
+
+func TestVolumeSizeAbove2GiB(t *testing.T) {
+	cases := []struct {
+		gib   int
+		bytes int64
+	}{
+		{1024, 1099511627776},
+		{102400, 109951162777600},
+		{102401, 109952236519424},
+	}
+	for _, tc := range cases {
+		if bytes := sizeInBytes(tc.gib); bytes != tc.bytes {
+			t.Errorf("sizeInBytes(%d) = %d, expected %d", tc.gib, bytes, tc.bytes)
+		}
+		if gib := sizeInGiB(tc.bytes); gib != tc.gib {
+			t.Errorf("sizeInGiB(%d) = %d, expected %d", tc.bytes, gib, tc.gib)
+		}
+
+		params := requestBody(t, volumeRequest{Size: sizeInBytes(tc.gib)})
+		if params["quotaInBytes"] != json.Number(fmt.Sprint(tc.bytes)) {
+			t.Errorf("quotaInBytes request parameter = %v, expected %d", params["quotaInBytes"], tc.bytes)
+		}
+
+		var result volumeResult
+		body, _ := json.Marshal(map[string]interface{}{"quotaInBytes": tc.bytes})
+		if err := json.Unmarshal(body, &result); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if result.Size != tc.bytes {
+			t.Errorf("quotaInBytes response = %d, expected %d", result.Size, tc.bytes)
+		}
+	}
+}
+
+func TestSMBShareName(t *testing.T) {
+	mountPoints := []mountPoints{
+		{Export: "/cvs-share", Server: "10.0.0.2", ProtocolType: "NFSv3"},
+		{Export: `\\cvs-1234.example.com\cvs-share`, Server: "10.0.0.2", ProtocolType: "CIFS"},
+	}
+	if name := smbShareName(mountPoints); name != "cvs-share" {
+		t.Errorf("expected cvs-share, got %s", name)
+	}
+	if name := smbShareName(mountPoints[:1]); name != "" {
+		t.Errorf("expected no share name for an NFS volume, got %s", name)
+	}
+}
+
+func TestSortMountPoints(t *testing.T) {
+	points := []mountPoints{
+		{Export: "/vol1", Server: "10.0.0.5", ProtocolType: "NFSv4"},
+		{Export: `\\cvs-1234.example.com\vol1`, Server: "cvs-1234.example.com", ProtocolType: "CIFS"},
+		{Export: "/vol1", Server: "10.0.0.4", ProtocolType: "NFSv3"},
+		{Export: "/vol1", Server: "10.0.0.3", ProtocolType: "NFSv3"},
+	}
+	sortMountPoints(points)
+	expected := []string{"CIFS cvs-1234.example.com", "NFSv3 10.0.0.3", "NFSv3 10.0.0.4", "NFSv4 10.0.0.5"}
+	for i, point := range points {
+		if got := point.ProtocolType + " " + point.Server; got != expected[i] {
+			t.Errorf("mount point %d: expected %s, got %s", i, expected[i], got)
+		}
+	}
+}
+
+func TestSortProtocolTypes(t *testing.T) {
+	cases := []struct {
+		protocolTypes []string
+		configured    []interface{}
+		expected      string
+	}{
+		{[]string{"NFSv4", "NFSv3"}, nil, "NFSv3,NFSv4"},
+		{[]string{"NFSv3", "NFSv4"}, []interface{}{"NFSv4", "NFSv3"}, "NFSv4,NFSv3"},
+		{[]string{"SMB", "NFSv3"}, []interface{}{"nfsv3", "CIFS"}, "NFSv3,SMB"},
+		{[]string{"NFSv4", "SMB", "NFSv3"}, []interface{}{"SMB"}, "SMB,NFSv3,NFSv4"},
+	}
+	for _, c := range cases {
+		if got := strings.Join(sortProtocolTypes(c.protocolTypes, c.configured), ","); got != c.expected {
+			t.Errorf("sortProtocolTypes(%v, %v) = %s, expected %s", c.protocolTypes, c.configured, got, c.expected)
+		}
+	}
+}
+
+func TestMountConvenienceAttributes(t *testing.T) {
+	mounts := []mountPoints{
+		{Export: "/cvs-share", Server: "10.0.0.2", ProtocolType: "NFSv4"},
+		{Export: "/cvs-share", Server: "10.0.0.2", ProtocolType: "NFSv3"},
+		{Export: `\\cvs-1234.example.com\cvs-share`, Server: "10.0.0.2", ProtocolType: "CIFS"},
+	}
+	if command := nfsMountCommand(mounts); command != "mount -t nfs -o rw,hard,rsize=65536,wsize=65536,vers=3,tcp 10.0.0.2:/cvs-share /mnt/cvs-share" {
+		t.Errorf("unexpected NFS mount command: %s", command)
+	}
+	if command := nfsMountCommand(mounts[:1]); !strings.Contains(command, "vers=4.1,") {
+		t.Errorf("expected an NFSv4.1 mount command, got %s", command)
+	}
+	if path := smbUNCPath(mounts); path != `\\cvs-1234.example.com\cvs-share` {
+		t.Errorf("unexpected UNC path: %s", path)
+	}
+	if path := smbUNCPath([]mountPoints{{Export: "cvs-share", Server: "10.0.0.2", ProtocolType: "CIFS"}}); path != `\\10.0.0.2\cvs-share` {
+		t.Errorf("unexpected UNC path: %s", path)
+	}
+	if path := smbUNCPath(mounts[:2]); path != "" {
+		t.Errorf("expected no UNC path for an NFS volume, got %s", path)
+	}
+}
+
+func TestVolumeRecreateOnError(t *testing.T) {
+	for _, recreate := range []bool{true, false} {
+		state := &terraform.InstanceState{
+			ID: "0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10",
+			Attributes: map[string]string{
+				"lifecycle_state":   "error",
+				"recreate_on_error": fmt.Sprint(recreate),
+				"read_only":         "false",
+			},
+		}
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":              "vol1",
+			"region":            "us-east4",
+			"network":           "cvs-vpc",
+			"size":              1024,
+			"protocol_types":    []interface{}{"NFSv3"},
+			"recreate_on_error": recreate,
+		})
+		diff, err := resourceGCPVolume().Diff(state, config, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if diff.RequiresNew() != recreate {
+			t.Errorf("recreate_on_error = %v: expected replacement %v, got %v", recreate, recreate, diff.RequiresNew())
+		}
+	}
+}
+
+func TestBackupPolicy(t *testing.T) {
+	policy := expandBackupPolicy(map[string]interface{}{
+		"enabled":                 true,
+		"daily_backups_to_keep":   7,
+		"weekly_backups_to_keep":  4,
+		"monthly_backups_to_keep": 12,
+	})
+	params := requestBody(t, volumeRequest{BackupPolicy: &policy})
+	expected := map[string]interface{}{
+		"enabled":              true,
+		"dailyBackupsToKeep":   json.Number("7"),
+		"weeklyBackupsToKeep":  json.Number("4"),
+		"monthlyBackupsToKeep": json.Number("12"),
+	}
+	if fmt.Sprint(params["backupPolicy"]) != fmt.Sprint(expected) {
+		t.Errorf("backupPolicy request parameter = %v, expected %v", params["backupPolicy"], expected)
+	}
+
+	var result volumeResult
+	body, _ := json.Marshal(map[string]interface{}{"backupPolicy": expected})
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	flattened := flattenBackupPolicy(result.BackupPolicy).([]map[string]interface{})
+	if expanded := expandBackupPolicy(flattened[0]); expanded != policy {
+		t.Errorf("backupPolicy round trip = %+v, expected %+v", expanded, policy)
+	}
+}
+
+// requestBody returns the JSON body sent for a request, with numbers kept as json.Number
+func requestBody(t *testing.T, request interface{}) map[string]interface{} {
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var params map[string]interface{}
+	if err := decoder.Decode(&params); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return params
+}
+
+func TestVolumeRequestBody(t *testing.T) {
+	disabled := snapshotPolicy{}
+	rule := exportPolicyRule{Access: "ReadWrite", AllowedClients: "10.0.0.0/8", AllowVpc: true}
+	params := requestBody(t, volumeRequest{
+		Name:                   "vol1",
+		SnapshotPolicy:         &disabled,
+		ExportPolicy:           exportPolicy{Rules: []exportPolicyRule{rule}},
+		SharedVpcProjectNumber: "987654321",
+	})
+	if _, ok := params["snapshotPolicy"]; !ok {
+		t.Error("expected a disabled snapshotPolicy to be sent")
+	}
+	for _, key := range []string{"backupPolicy", "snapshotDirectory", "region", "SharedVpcProjectNumber"} {
+		if _, ok := params[key]; ok {
+			t.Errorf("expected %s to be omitted, got %v", key, params[key])
+		}
+	}
+	rules := params["exportPolicy"].(map[string]interface{})["rules"].([]interface{})
+	if _, ok := rules[0].(map[string]interface{})["AllowVpc"]; ok {
+		t.Error("expected allow_vpc not to be sent in the export rule")
+	}
+
+	// the export policy is always sent, so an update can remove all rules
+	params = requestBody(t, volumeRequest{Name: "vol1"})
+	if _, ok := params["exportPolicy"]; !ok {
+		t.Error("expected exportPolicy to be sent without rules")
+	}
+}
+
+func TestVolumeRequestBodyClone(t *testing.T) {
+	params := requestBody(t, volumeRequest{Name: "vol1", SnapshotID: "d2e5f5a8-7f3b-4c2a-9c1e-2b7a1f0e6d44"})
+	if params["snapshotId"] != "d2e5f5a8-7f3b-4c2a-9c1e-2b7a1f0e6d44" {
+		t.Errorf("snapshotId request parameter = %v, expected the snapshot ID", params["snapshotId"])
+	}
+	params = requestBody(t, volumeRequest{Name: "vol1"})
+	if _, ok := params["snapshotId"]; ok {
+		t.Error("expected snapshotId to be omitted when not cloning")
+	}
+}
+
+func TestValidateAllowedClients(t *testing.T) {
+	warnings, errs := validateAllowedClients("10.0.0.0/8, 0.0.0.0/0", "allowed_clients")
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"10.0.0.0/8, 0.0.0.0/0"`) || len(errs) != 0 {
+		t.Errorf("expected a warning naming the clients, got %v, %v", warnings, errs)
+	}
+	if warnings, errs := validateAllowedClients("10.0.0.0/8", "allowed_clients"); len(warnings) != 0 || len(errs) != 0 {
+		t.Errorf("expected no warning, got %v, %v", warnings, errs)
+	}
+}
+
+func TestCheckReadOnlyExportPolicy(t *testing.T) {
+	readOnly := exportPolicy{Rules: []exportPolicyRule{
+		{Access: "ReadOnly", AllowedClients: "10.0.0.0/8"},
+		{Access: "None", AllowedClients: "0.0.0.0/0"},
+	}}
+	if err := checkReadOnlyExportPolicy(readOnly); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	for _, rule := range []exportPolicyRule{
+		{Access: "ReadWrite", AllowedClients: "10.0.0.0/8"},
+		{Access: "ReadOnly", AllowedClients: "10.0.0.0/8", Kerberos5pReadWrite: true},
+	} {
+		policy := exportPolicy{Rules: append(readOnly.Rules, rule)}
+		if err := checkReadOnlyExportPolicy(policy); err == nil || !strings.Contains(err.Error(), "rule 3") {
+			t.Errorf("expected an error for rule 3, got %v", err)
+		}
+	}
+}
+
+func TestResourceGCPVolumeStateUpgradeV0(t *testing.T) {
+	for network, expected := range map[string]string{
+		"projects/123456/global/networks/cvs-vpc": "cvs-vpc",
+		"cvs-vpc": "cvs-vpc",
+	} {
+		state, err := resourceGCPVolumeStateUpgradeV0(map[string]interface{}{"network": network, "name": "vol"}, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if state["network"] != expected || state["name"] != "vol" {
+			t.Errorf("%s: unexpected upgraded state %v", network, state)
+		}
+	}
+}
+
+func TestResourceGCPVolumeV0(t *testing.T) {
+	// the type of version 0 doesn't change with the volume schema
+	v0 := resourceGCPVolumeV0().CoreConfigSchema().ImpliedType()
+	if !v0.HasAttribute("network") || !v0.HasAttribute("id") {
+		t.Error("expected the attributes of version 0")
+	}
+	if v0.HasAttribute("wait_for_snapshot_policy") || v0.HasAttribute("timeouts") {
+		t.Error("expected no attributes added after version 0")
+	}
+}
+
+func TestSuppressNetworkDiff(t *testing.T) {
+	if !suppressNetworkDiff("network", "cvs-vpc", "projects/123456/global/networks/cvs-vpc", nil) {
+		t.Error("expected the network name and full path to match")
+	}
+	if suppressNetworkDiff("network", "cvs-vpc", "other-vpc", nil) {
+		t.Error("expected different networks to differ")
+	}
+}
+
+func TestVolumeComputedAttributesStable(t *testing.T) {
+	resource := resourceGCPVolume()
+	config := map[string]interface{}{
+		"name":           "vol",
+		"region":         "us-east4",
+		"protocol_types": []interface{}{"NFSv3"},
+		"network":        "projects/123456/global/networks/cvs-vpc",
+		"size":           1024,
+	}
+	d := schema.TestResourceDataRaw(t, resource.Schema, config)
+	d.SetId("0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10")
+	// the attributes as stored by Read
+	for key, value := range map[string]interface{}{
+		"network":            "cvs-vpc",
+		"network_full_path":  "projects/123456/global/networks/cvs-vpc",
+		"volume_id":          "0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10",
+		"volume_path":        "vol-path",
+		"lifecycle_state":    "available",
+		"mount_points":       []interface{}{},
+		"snapshot_policy":    []interface{}{},
+		"backup_policy":      []interface{}{},
+		"smb_share_settings": []interface{}{},
+	} {
+		if err := d.Set(key, value); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	diff, err := resource.Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Errorf("expected no changes after a refresh, got %v", diff.Attributes)
+	}
+}
+
+func TestVolumeSizeInGiB(t *testing.T) {
+	resource := resourceGCPVolume()
+	for _, key := range []string{"size", "size_in_gib"} {
+		config := map[string]interface{}{
+			"name":           "vol",
+			"region":         "us-east4",
+			"protocol_types": []interface{}{"NFSv3"},
+			"network":        "cvs-vpc",
+			key:              2048,
+		}
+		diff, err := resource.Diff(nil, terraform.NewResourceConfigRaw(config), nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", key, err)
+		}
+		for _, attribute := range []string{"size", "size_in_gib"} {
+			if diff.Attributes[attribute] == nil || diff.Attributes[attribute].New != "2048" {
+				t.Errorf("%s: expected %s to be planned as 2048, got %v", key, attribute, diff.Attributes[attribute])
+			}
+		}
+	}
+}
+
+func TestVolumeShrink(t *testing.T) {
+	resource := resourceGCPVolume()
+	config := map[string]interface{}{
+		"name":           "vol",
+		"region":         "us-east4",
+		"protocol_types": []interface{}{"NFSv3"},
+		"network":        "cvs-vpc",
+		"size":           2048,
+	}
+	d := schema.TestResourceDataRaw(t, resource.Schema, config)
+	d.SetId("0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10")
+	if err := d.Set("size_in_gib", 2048); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	config["size"] = 4096
+	if _, err := resource.Diff(d.State(), terraform.NewResourceConfigRaw(config), nil); err != nil {
+		t.Errorf("unexpected error growing the volume: %s", err)
+	}
+	config["size"] = 1024
+	if _, err := resource.Diff(d.State(), terraform.NewResourceConfigRaw(config), nil); err == nil {
+		t.Error("expected an error shrinking the volume")
+	}
+	config["allow_shrink"] = true
+	diff, err := resource.Diff(d.State(), terraform.NewResourceConfigRaw(config), nil)
+	if err != nil {
+		t.Fatalf("unexpected error shrinking the volume with allow_shrink: %s", err)
+	}
+	if diff.Attributes["size"] == nil || diff.Attributes["size"].New != "1024" {
+		t.Errorf("expected size to be planned as 1024, got %v", diff.Attributes["size"])
+	}
+}
+
+func TestVolumeDeletionProtection(t *testing.T) {
+	resource := resourceGCPVolume()
+	d := resource.TestResourceData()
+	d.SetId("0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10")
+	if err := d.Set("deletion_protection", true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// the client is never used, the deletion is refused first
+	if err := resource.Delete(d, &Client{ReadOnly: true}); err == nil || !strings.Contains(err.Error(), "deletion_protection") {
+		t.Errorf("expected a deletion_protection error, got %v", err)
+	}
+}
+
+func TestVolumeRequestExtraParameters(t *testing.T) {
+	extra, err := expandExtraRequestParameters(`{"newApiField": "value", "serviceLevel": "extreme"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	body, err := json.Marshal(volumeRequest{Name: "vol", ServiceLevel: "medium", Deadline: time.Now(), ExtraParameters: extra})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if decoded["name"] != "vol" || decoded["newApiField"] != "value" || decoded["serviceLevel"] != "extreme" {
+		t.Errorf("unexpected body %s", body)
+	}
+
+	// without extra parameters the body is unchanged
+	body, err = json.Marshal(volumeRequest{Name: "vol"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(body) != `{"name":"vol","exportPolicy":{"rules":null}}` {
+		t.Errorf("unexpected body %s", body)
+	}
+
+	for _, value := range []string{`["not", "an", "object"]`, `{"unterminated": `} {
+		if _, errs := validateExtraRequestParameters(value, "extra_request_parameters"); len(errs) == 0 {
+			t.Errorf("expected an error for %s", value)
+		}
+	}
+	if warnings, errs := validateExtraRequestParameters(`{"newApiField": "value"}`, "extra_request_parameters"); len(warnings) != 1 || len(errs) != 0 {
+		t.Errorf("expected a warning about the fields not being read back, got %v, %v", warnings, errs)
+	}
+	if warnings, errs := validateExtraRequestParameters(`{}`, "extra_request_parameters"); len(warnings) != 0 || len(errs) != 0 {
+		t.Errorf("expected no warning without fields, got %v, %v", warnings, errs)
+	}
+}
+
+// Response bodies captured from the GET /Volumes/{volumeId} endpoint, reduced to the export policy. The nfsv3 and
+// nfsv4 blocks of a rule are omitted by some responses.
+const getVolumeResponseExportPolicy = `{
+	"exportPolicy": {"rules": [
+		{"access": "ReadWrite", "allowedClients": "10.0.0.0/24", "hasRootAccess": true, "nfsv3": {"checked": true}, "nfsv4": {"checked": false}}
+	]},
+	"lifeCycleState": "available",
+	"volumeId": "0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10"
+}`
+
+const getVolumeResponseExportPolicyOmittedNFS = `{
+	"exportPolicy": {"rules": [
+		{"access": "ReadWrite", "allowedClients": "10.0.0.0/24", "hasRootAccess": true, "nfsv3": {"checked": true}},
+		{"access": "ReadOnly", "allowedClients": "10.0.1.0/24", "hasRootAccess": false},
+		{"access": "ReadOnly", "allowedClients": "10.0.2.0/24", "hasRootAccess": false, "nfsv3": null}
+	]},
+	"lifeCycleState": "available",
+	"volumeId": "0fe4bd4e-1f2a-4a8e-9b87-5e7c2ad4ab10"
+}`
+
+func TestFlattenExportPolicyOmittedNFS(t *testing.T) {
+	var complete volumeResult
+	if err := json.Unmarshal([]byte(getVolumeResponseExportPolicy), &complete); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rule := flattenExportPolicy(complete.ExportPolicy).([]map[string]interface{})[0]["rule"].([]map[string]interface{})[0]
+	if fmt.Sprint(rule["nfsv3"]) != "[map[checked:true]]" || fmt.Sprint(rule["nfsv4"]) != "[map[checked:false]]" {
+		t.Errorf("expected the nfsv3 and nfsv4 blocks of the response, got %v and %v", rule["nfsv3"], rule["nfsv4"])
+	}
+
+	var omitted volumeResult
+	if err := json.Unmarshal([]byte(getVolumeResponseExportPolicyOmittedNFS), &omitted); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	flattened := flattenExportPolicy(omitted.ExportPolicy)
+	rules := flattened.([]map[string]interface{})[0]["rule"].([]map[string]interface{})
+	expected := []struct{ nfsv3, nfsv4 string }{
+		{"[map[checked:true]]", "[]"},
+		{"[]", "[]"},
+		{"[]", "[]"},
+	}
+	for i, rule := range rules {
+		if fmt.Sprint(rule["nfsv3"]) != expected[i].nfsv3 || fmt.Sprint(rule["nfsv4"]) != expected[i].nfsv4 {
+			t.Errorf("rule %d: expected no block for omitted ones, got %v and %v", i, rule["nfsv3"], rule["nfsv4"])
+		}
+	}
+
+	// the configured blocks are kept for the omitted ones
+	d := schema.TestResourceDataRaw(t, resourceGCPVolume().Schema, map[string]interface{}{
+		"export_policy": []interface{}{map[string]interface{}{
+			"rule": []interface{}{
+				map[string]interface{}{"access": "ReadWrite", "allowed_clients": "10.0.0.0/24", "nfsv4": []interface{}{map[string]interface{}{"checked": false}}},
+				map[string]interface{}{"access": "ReadOnly", "allowed_clients": "10.0.1.0/24", "nfsv3": []interface{}{map[string]interface{}{"checked": true}}},
+			},
+		}},
+	})
+	keepOmittedNFSVersions(flattened, d.Get("export_policy").(*schema.Set))
+	if fmt.Sprint(rules[0]["nfsv3"]) != "[map[checked:true]]" || fmt.Sprint(rules[0]["nfsv4"]) != "[map[checked:false]]" {
+		t.Errorf("rule 0: expected the response nfsv3 and the configured nfsv4, got %v and %v", rules[0]["nfsv3"], rules[0]["nfsv4"])
+	}
+	if fmt.Sprint(rules[1]["nfsv3"]) != "[map[checked:true]]" || fmt.Sprint(rules[1]["nfsv4"]) != "[]" {
+		t.Errorf("rule 1: expected the configured blocks, got %v and %v", rules[1]["nfsv3"], rules[1]["nfsv4"])
+	}
+	if fmt.Sprint(rules[2]["nfsv3"]) != "[]" {
+		t.Errorf("rule 2: expected no block for a rule that isn't configured, got %v", rules[2]["nfsv3"])
+	}
+	if err := d.Set("export_policy", flattened); err != nil {
+		t.Errorf("unexpected error setting the export policy: %s", err)
+	}
+}
+
+func TestVolumeExportsDisabled(t *testing.T) {
+	if body, _ := json.Marshal(disabledExportPolicy()); string(body) != `{"rules":[]}` {
+		t.Errorf("expected the rules to be sent as an empty list, got %s", body)
+	}
+
+	rules := `[]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"volumeId": "vol1", "region": "us-east4", "lifeCycleState": "available", "exportPolicy": {"rules": %s}}`, rules)
+	}))
+	defer server.Close()
+	client := &Client{Host: server.URL + "/", Token: "opaque-access-token"}
+
+	resource := resourceGCPVolume()
+	d := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		"name":             "vol1",
+		"region":           "us-east4",
+		"protocol_types":   []interface{}{"NFSv3"},
+		"network":          "default",
+		"exports_disabled": true,
+		"export_policy": []interface{}{map[string]interface{}{
+			"rule": []interface{}{map[string]interface{}{"allowed_clients": "10.0.0.0/8", "access": "ReadWrite"}},
+		}},
+	})
+	d.SetId("vol1")
+
+	// the export rules to restore are kept while the volume has none
+	if err := resource.Read(d, client); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !d.Get("exports_disabled").(bool) || d.Get("export_policy").(*schema.Set).Len() != 1 {
+		t.Errorf("expected exports to stay disabled with the export policy kept, got %v, %v", d.Get("exports_disabled"), d.Get("export_policy"))
+	}
+
+	// rules added outside Terraform enable the exports
+	rules = `[{"allowedClients": "0.0.0.0/0", "access": "ReadWrite"}]`
+	if err := resource.Read(d, client); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d.Get("exports_disabled").(bool) {
+		t.Error("expected exports to be enabled by the rules of the volume")
+	}
+}
@@ -0,0 +1,211 @@
+package gcp
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/netapp/terraform-provider-netapp-gcp/gcp/cvs/restapi"
+)
+
+// volumeTransitionalStates are the lifecycle states of a volume while a job runs on it, before it becomes available
+// again, e.g. restoring while it is reverted to a snapshot
+var volumeTransitionalStates = []string{"creating", "updating", "restoring", "enabling", "disabling"}
+
+// volumeWait describes a wait for a volume to reach a lifecycle state after a job was submitted for it
+type volumeWait struct {
+	region   string
+	volumeID string
+	// pending are the states of the volume while the job runs
+	pending []string
+	// target are the states ending the wait. The error state fails the wait unless it is a target.
+	target []string
+	// jobID is the job whose progress is logged at every poll, if set
+	jobID string
+	// interval is the default interval between polls, overridden by the provider poll_interval_seconds
+	interval time.Duration
+	deadline time.Time
+}
+
+// volumeWaitState returns the state of the volume for the wait, or an error if the state is terminal
+// and not a target of the wait
+func volumeWaitState(res volumeResult, target []string) (string, error) {
+	state := res.LifeCycleState
+	for _, targetState := range target {
+		if state == targetState {
+			return state, nil
+		}
+	}
+	if state == "error" || state == "disabled" {
+		return state, fmt.Errorf("volume with id: %s is in %s state: %s", res.VolumeID, state, res.LifeCycleStateDetails)
+	}
+	return state, nil
+}
+
+// waitForVolumeState polls the volume until it reaches a target state of the wait. A volume that is not
+// found is reported in the deleted state.
+func (c *Client) waitForVolumeState(wait volumeWait) (volumeResult, error) {
+	timeout := time.Until(wait.deadline)
+	if timeout <= 0 {
+		return volumeResult{}, fmt.Errorf("timed out waiting for volume with id: %s to become %v", wait.volumeID, wait.target)
+	}
+	stateConf := &resource.StateChangeConf{
+		Pending: wait.pending,
+		Target:  wait.target,
+		Refresh: func() (interface{}, string, error) {
+			if wait.jobID != "" {
+				logJobProgress(c, wait.region, wait.volumeID, wait.jobID)
+			}
+			res, err := c.getVolumeByID(volumeRequest{Region: wait.region, VolumeID: wait.volumeID})
+			if err != nil {
+				if err, ok := err.(*restapi.ResponseError); ok && err.Name == "xUnknown" {
+					return volumeResult{VolumeID: wait.volumeID, LifeCycleState: "deleted"}, "deleted", nil
+				}
+				return nil, "", err
+			}
+			state, err := volumeWaitState(res, wait.target)
+			log.Printf("[DEBUG] Volume %s is %s", wait.volumeID, state)
+			return res, state, err
+		},
+		Timeout:      timeout,
+		PollInterval: c.pollInterval(wait.interval),
+	}
+	res, err := stateConf.WaitForState()
+	if err != nil {
+		return volumeResult{}, err
+	}
+	return res.(volumeResult), nil
+}
+
+// snapshotPolicyWaitTimeout bounds the wait for the snapshot policy of a new volume to be applied
+const snapshotPolicyWaitTimeout = 2 * time.Minute
+
+// waitForSnapshotPolicy polls the volume until the configured schedules of its snapshot policy are the ones it was
+// created with, for at most snapshotPolicyWaitTimeout and until the deadline
+func (c *Client) waitForSnapshotPolicy(region string, volumeID string, policy snapshotPolicy, schedules []string, deadline time.Time) error {
+	timeout := snapshotPolicyWaitTimeout
+	if untilDeadline := time.Until(deadline); untilDeadline < timeout {
+		timeout = untilDeadline
+	}
+	if timeout <= 0 {
+		return fmt.Errorf("timed out waiting for the snapshot policy of volume with id: %s to be applied", volumeID)
+	}
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"pending"},
+		Target:  []string{"applied"},
+		Refresh: func() (interface{}, string, error) {
+			res, err := c.getVolumeByID(volumeRequest{Region: region, VolumeID: volumeID})
+			if err != nil {
+				return nil, "", err
+			}
+			if !snapshotPolicyApplied(res.SnapshotPolicy, policy, schedules) {
+				log.Printf("[DEBUG] Snapshot policy of volume %s is not applied yet", volumeID)
+				return res, "pending", nil
+			}
+			return res, "applied", nil
+		},
+		Timeout:      timeout,
+		PollInterval: c.pollInterval(10 * time.Second),
+	}
+	if _, err := stateConf.WaitForState(); err != nil {
+		return fmt.Errorf("snapshot policy of volume with id: %s is not applied: %s", volumeID, err)
+	}
+	return nil
+}
+
+// configuredSchedules returns the schedules of a snapshot_policy block that are set in the configuration, e.g.
+// daily_schedule
+func configuredSchedules(data map[string]interface{}) []string {
+	schedules := []string{}
+	for _, schedule := range []string{"daily_schedule", "hourly_schedule", "monthly_schedule", "weekly_schedule"} {
+		if v, ok := data[schedule].([]interface{}); ok && len(v) > 0 {
+			schedules = append(schedules, schedule)
+		}
+	}
+	return schedules
+}
+
+// snapshotPolicyApplied reports whether the snapshot policy read from the API is enabled as configured and has the
+// configured schedules. The schedules missing from the configuration are left to the API defaults and aren't
+// compared. The days of the schedules are compared regardless of spacing and casing.
+func snapshotPolicyApplied(applied snapshotPolicy, configured snapshotPolicy, schedules []string) bool {
+	if applied.Enabled != configured.Enabled {
+		return false
+	}
+	normalize := func(days string) string {
+		return strings.ToLower(strings.Replace(days, " ", "", -1))
+	}
+	for _, schedule := range schedules {
+		switch schedule {
+		case "daily_schedule":
+			if applied.DailySchedule != configured.DailySchedule {
+				return false
+			}
+		case "hourly_schedule":
+			if applied.HourlySchedule != configured.HourlySchedule {
+				return false
+			}
+		case "monthly_schedule":
+			applied.MonthlySchedule.DaysOfMonth = normalize(applied.MonthlySchedule.DaysOfMonth)
+			configured.MonthlySchedule.DaysOfMonth = normalize(configured.MonthlySchedule.DaysOfMonth)
+			if applied.MonthlySchedule != configured.MonthlySchedule {
+				return false
+			}
+		case "weekly_schedule":
+			applied.WeeklySchedule.Day = normalize(applied.WeeklySchedule.Day)
+			configured.WeeklySchedule.Day = normalize(configured.WeeklySchedule.Day)
+			if applied.WeeklySchedule != configured.WeeklySchedule {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// volumeJobsWait bounds the wait for the running jobs of a volume before deleting it
+const volumeJobsWait = 10 * time.Minute
+
+// finishedJobStates are the terminal states of a job. A job in any other state, e.g. ongoing or queued, is running.
+var finishedJobStates = map[string]bool{"done": true, "error": true, "failed": true}
+
+// runningVolumeJobs returns the jobs of a volume that are still running, e.g. a backup
+func runningVolumeJobs(jobs []volumeJob) []string {
+	running := []string{}
+	for _, job := range jobs {
+		if !finishedJobStates[job.State] {
+			running = append(running, fmt.Sprintf("%s job %s", job.Action, job.JobID))
+		}
+	}
+	return running
+}
+
+// waitForVolumeJobs waits until no job of the volume is running before it is deleted, as the backend fails the
+// deletion of a volume with a running job. The wait ends at the deadline or after volumeJobsWait, and the volume is
+// then deleted anyway. Failing to list the jobs doesn't fail the deletion.
+func (c *Client) waitForVolumeJobs(region string, volumeID string, deadline time.Time) error {
+	end := time.Now().Add(volumeJobsWait)
+	if !deadline.IsZero() && deadline.Before(end) {
+		end = deadline
+	}
+	for {
+		jobs, err := c.listJobsForVolume(region, volumeID)
+		if err != nil {
+			log.Printf("[WARN] Unable to list the jobs of volume %s before deleting it: %s", volumeID, err)
+			return nil
+		}
+		running := runningVolumeJobs(jobs)
+		if len(running) == 0 {
+			return nil
+		}
+		if !time.Now().Before(end) {
+			log.Printf("[WARN] Deleting volume %s with %s still running", volumeID, strings.Join(running, ", "))
+			return nil
+		}
+		log.Printf("[INFO] Waiting for %s of volume %s to finish before deleting it", strings.Join(running, ", "), volumeID)
+		if err := c.sleep(c.pollInterval(20 * time.Second)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package gcp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestVolumeWaitState(t *testing.T) {
+	target := []string{"available"}
+	if state, err := volumeWaitState(volumeResult{LifeCycleState: "updating"}, target); state != "updating" || err != nil {
+		t.Errorf("expected a pending state, got %s, %v", state, err)
+	}
+	if state, err := volumeWaitState(volumeResult{LifeCycleState: "available"}, target); state != "available" || err != nil {
+		t.Errorf("expected the target state, got %s, %v", state, err)
+	}
+	if _, err := volumeWaitState(volumeResult{VolumeID: "v1", LifeCycleState: "error", LifeCycleStateDetails: "no capacity"}, target); err == nil || err.Error() != "volume with id: v1 is in error state: no capacity" {
+		t.Errorf("expected the error state to be terminal, got %v", err)
+	}
+	if state, err := volumeWaitState(volumeResult{LifeCycleState: "error"}, []string{"available", "error"}); state != "error" || err != nil {
+		t.Errorf("expected the error state as target, got %s, %v", state, err)
+	}
+}
+
+func TestWaitForVolumeAvailable(t *testing.T) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a revert goes through updating and restoring
+		state := "available"
+		switch atomic.AddInt32(&polls, 1) {
+		case 1:
+			state = "updating"
+		case 2, 3:
+			state = "restoring"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"volumeId": "v1", "lifeCycleState": %q}`, state)
+	}))
+	defer server.Close()
+	client := &Client{Host: server.URL + "/", Token: "opaque-access-token", PollInterval: time.Millisecond}
+
+	res, err := waitForVolumeAvailable(client, "us-east4", "v1", "", time.Now().Add(time.Minute))
+	if err != nil || res.LifeCycleState != "available" {
+		t.Errorf("expected the volume to become available, got %q, %v", res.LifeCycleState, err)
+	}
+}
+
+func TestSnapshotPolicyApplied(t *testing.T) {
+	configured := snapshotPolicy{
+		Enabled:         true,
+		DailySchedule:   dailySchedule{Hour: 1, Minute: 30, SnapshotsToKeep: 7},
+		WeeklySchedule:  weeklySchedule{Day: "Monday, Friday", Hour: 2, SnapshotsToKeep: 4},
+		MonthlySchedule: monthlySchedule{DaysOfMonth: "1,15", SnapshotsToKeep: 2},
+	}
+	schedules := []string{"daily_schedule", "monthly_schedule", "weekly_schedule"}
+	applied := configured
+	applied.WeeklySchedule.Day = "monday,friday"
+	applied.MonthlySchedule.DaysOfMonth = "1, 15"
+	// the schedules missing from the configuration have the defaults of the API
+	applied.HourlySchedule = hourlySchedule{SnapshotsToKeep: 48}
+	if !snapshotPolicyApplied(applied, configured, schedules) {
+		t.Error("expected the policy to be applied")
+	}
+	if snapshotPolicyApplied(snapshotPolicy{}, configured, schedules) {
+		t.Error("expected a disabled policy not to be applied")
+	}
+	applied.DailySchedule.Hour = 0
+	if snapshotPolicyApplied(applied, configured, schedules) {
+		t.Error("expected a different schedule not to be applied")
+	}
+	if !snapshotPolicyApplied(applied, configured, []string{"weekly_schedule"}) {
+		t.Error("expected a schedule missing from the configuration not to be compared")
+	}
+}
+
+func TestConfiguredSchedules(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceGCPVolume().Schema, map[string]interface{}{
+		"snapshot_policy": []interface{}{map[string]interface{}{
+			"enabled":         true,
+			"hourly_schedule": []interface{}{map[string]interface{}{"minute": 10, "snapshots_to_keep": 24}},
+			"weekly_schedule": []interface{}{map[string]interface{}{"day": "Monday", "snapshots_to_keep": 4}},
+		}},
+	})
+	schedules := configuredSchedules(d.Get("snapshot_policy").([]interface{})[0].(map[string]interface{}))
+	if !reflect.DeepEqual(schedules, []string{"hourly_schedule", "weekly_schedule"}) {
+		t.Errorf("expected the hourly and weekly schedules, got %v", schedules)
+	}
+}
+
+func TestWaitForVolumeJobs(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/us-east4/Jobs" || r.URL.Query().Get("project") != "123456" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		state := "ongoing"
+		switch atomic.AddInt32(&calls, 1) {
+		case 2:
+			// jobs not in a terminal state are running, whatever their state
+			state = "queued"
+		case 3, 4:
+			state = "done"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"jobId": "j1", "volumeId": "v1", "action": "backup", "state": %q}, {"jobId": "j2", "volumeId": "v2", "action": "create", "state": "ongoing"}]`, state)
+	}))
+	defer server.Close()
+	client := &Client{Host: server.URL + "/", Token: "opaque-access-token", Project: "123456", PollInterval: time.Millisecond}
+
+	// waits for the job of the volume, not the ones of other volumes
+	if err := client.waitForVolumeJobs("us-east4", "v1", time.Now().Add(time.Minute)); err != nil || calls != 3 {
+		t.Errorf("expected the wait to end when the job is done, got %v after %d calls", err, calls)
+	}
+
+	// the deletion goes ahead at the deadline
+	start := time.Now()
+	if err := client.waitForVolumeJobs("us-east4", "v2", time.Now().Add(20*time.Millisecond)); err != nil || time.Since(start) > time.Second {
+		t.Errorf("expected the wait to end at the deadline, got %v after %s", err, time.Since(start))
+	}
+
+	// a deadline already passed lists the jobs once and goes ahead
+	atomic.StoreInt32(&calls, 0)
+	if err := client.waitForVolumeJobs("us-east4", "v2", time.Now().Add(-time.Minute)); err != nil || calls != 1 {
+		t.Errorf("expected the wait to end at once, got %v after %d calls", err, calls)
+	}
+}
+
+func TestRunningVolumeJobs(t *testing.T) {
+	jobs := []volumeJob{
+		{JobID: "j1", Action: "backup", State: "ongoing"},
+		{JobID: "j2", Action: "snapshot", State: "queued"},
+		{JobID: "j3", Action: "update", State: "done"},
+		{JobID: "j4", Action: "create", State: "error"},
+		{JobID: "j5", Action: "revert", State: "failed"},
+	}
+	if running := fmt.Sprint(runningVolumeJobs(jobs)); running != "[backup job j1 snapshot job j2]" {
+		t.Errorf("expected the ongoing and queued jobs to be running, got %s", running)
+	}
+}
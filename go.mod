@@ -3,7 +3,7 @@ module github.com/netapp/terraform-provider-netapp-gcp
 go 1.14
 
 require (
-	github.com/fatih/structs v1.1.0
+	cloud.google.com/go v0.45.1
 	github.com/hashicorp/terraform v0.12.28
 	github.com/sirupsen/logrus v1.6.0
 	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d